@@ -9,27 +9,46 @@ import (
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/config"
 	"github.com/auto-dns/pihole-cluster-admin/internal/database"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/audithandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/authhandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/blockinghandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/devicehandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/domainrulehandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/eventshandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/frontendhandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/gravityhandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/grouphandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/healthcheckhandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/healthhandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/listhandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/metricshandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/notificationhandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/piholehandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/queryloghandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/setuphandler"
+	"github.com/auto-dns/pihole-cluster-admin/internal/handler/statshandler"
 	"github.com/auto-dns/pihole-cluster-admin/internal/handler/userhandler"
 	apimw "github.com/auto-dns/pihole-cluster-admin/internal/middleware"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
 	"github.com/auto-dns/pihole-cluster-admin/internal/realtime"
 	"github.com/auto-dns/pihole-cluster-admin/internal/server"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/authservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/blockingservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/deviceservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/domainruleservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/eventsservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/gravityservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/groupservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/healthservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/listservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/metricsservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/notificationservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/piholeservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/querylogservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/ruleexpiryservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/setupservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/statsservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/userservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/sessions"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
@@ -39,10 +58,12 @@ import (
 )
 
 type App struct {
-	Logger        zerolog.Logger
-	Server        HttpServer
-	Sessions      SessionPurger
-	HealthService HealthService
+	Logger            zerolog.Logger
+	Server            HttpServer
+	Sessions          SessionPurger
+	HealthService     HealthService
+	QueryLogCollector QueryLogCollector
+	RuleExpiryService RuleExpiryService
 }
 
 func newSessionStorage(cfg config.SessionConfig, sessionSqliteStore SessionSqliteStore, logger zerolog.Logger) SessionStorage {
@@ -71,44 +92,75 @@ func New(cfg *config.Config, logger zerolog.Logger) (*App, error) {
 	piholeStore := store.NewPiholeStore(db, cfg.EncryptionKey, logger)
 	sessionStore := store.NewSessionStore(db, logger)
 	userStore := store.NewUserStore(db, logger)
+	auditStore := store.NewAuditStore(db, logger)
+	domainRuleHistoryStore := store.NewDomainRuleHistoryStore(db, logger)
+	pendingDomainRuleOpStore := store.NewPendingDomainRuleOpStore(db, logger)
+	domainRuleExpirationStore := store.NewDomainRuleExpirationStore(db, logger)
+	queryLogStore := store.NewQueryLogStore(db, logger)
+	notificationDeliveryStore := store.NewNotificationDeliveryStore(db, logger)
+	nodeHealthHistoryStore := store.NewNodeHealthHistoryStore(db, logger)
 
 	clients, err := GetClients(piholeStore, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("error loading clients from database")
 	}
-	cursorManager := pihole.NewCursorManager[pihole.FetchQueryLogFilters](cfg.Server.Session.TTLHours)
-	cluster := pihole.NewCluster(clients, cursorManager, logger)
-
 	// Broker
 	broker := realtime.NewBroker()
 
+	// Metrics
+	metricsService := metricsservice.NewService(broker, logger)
+
+	cursorManager := pihole.NewCursorManager[pihole.FetchQueryLogFilters](cfg.Server.Session.TTLHours)
+	cluster := pihole.NewCluster(clients, cursorManager, metricsService, logger)
+
 	// Handler
 	sessionStorage := newSessionStorage(cfg.Server.Session, sessionStore, logger)
 	sessionManager := sessions.NewSessionManager(sessionStorage, cfg.Server.Session, logger)
 
 	// Router
-	authService := authservice.NewService(userStore, sessionManager, logger)
+	auditService := auditservice.NewService(auditStore, logger)
+	auditHandler := audithandler.NewHandler(auditService, logger)
+	authService := authservice.NewService(userStore, sessionManager, auditService, logger)
 	authHandler := authhandler.NewHandler(authService, sessionManager, logger)
-	domainService := domainruleservice.NewService(cluster)
+	blockingService := blockingservice.NewService(cluster, broker, auditService, logger)
+	blockingHandler := blockinghandler.NewHandler(blockingService, logger)
+	deviceService := deviceservice.NewService(cluster, auditService, logger)
+	deviceHandler := devicehandler.NewHandler(deviceService, logger)
+	domainService := domainruleservice.NewService(cluster, domainRuleHistoryStore, pendingDomainRuleOpStore, domainRuleExpirationStore, auditService, logger)
 	domainRuleHandler := domainrulehandler.NewHandler(domainService, logger)
 	eventsService := eventsservice.NewService(broker, logger)
 	eventsHandler := eventshandler.NewHandler(cfg.Server.ServerSideEvents, eventsService, logger)
 	frontendHandler := frontendhandler.NewHandler(logger)
+	gravityService := gravityservice.NewService(cluster, broker, auditService, logger)
+	gravityHandler := gravityhandler.NewHandler(gravityService, logger)
+	groupService := groupservice.NewService(cluster, auditService, logger)
+	groupHandler := grouphandler.NewHandler(groupService, logger)
 	healthcheckHandler := healthcheckhandler.NewHandler(logger)
-	healthService := healthservice.NewService(broker, cluster, cfg.HealthService, logger)
+	notificationService := notificationservice.NewService(cfg.Notifications, notificationDeliveryStore, logger)
+	notificationHandler := notificationhandler.NewHandler(notificationService, logger)
+	healthService := healthservice.NewService(broker, cluster, domainService, metricsService, notificationService, nodeHealthHistoryStore, cfg.HealthService, logger)
 	healthHandler := healthhandler.NewHandler(healthService, logger)
-	piholeService := piholeservice.NewService(cluster, piholeStore, logger)
+	listService := listservice.NewService(cluster, auditService, logger)
+	listHandler := listhandler.NewHandler(listService, logger)
+	metricsHandler := metricshandler.NewHandler(cfg.Metrics, metricsService, logger)
+	piholeService := piholeservice.NewService(cluster, piholeStore, auditService, logger)
 	piholeHandler := piholehandler.NewHandler(piholeService, logger)
-	queryLogService := querylogservice.NewService(cluster, logger)
+	ruleExpiryService := ruleexpiryservice.NewService(domainRuleExpirationStore, domainService, cfg.RuleExpiryService, logger)
+	queryLogService := querylogservice.NewService(cluster, broker, queryLogStore, domainService, userStore, cfg.QueryLogService, logger)
 	queryLogHandler := queryloghandler.NewHandler(queryLogService, logger)
 	setupService := setupservice.NewService(initializationStatusStore, userStore, sessionManager, logger)
 	setupHandler := setuphandler.NewHandler(setupService, sessionManager, logger)
-	userService := userservice.NewService(userStore, logger)
+	statsService := statsservice.NewService(cluster, logger)
+	statsHandler := statshandler.NewHandler(statsService, logger)
+	userService := userservice.NewService(userStore, auditService, logger)
 	userHandler := userhandler.NewHandler(userService, logger)
 
 	// Root router
 	rootRouter := chi.NewRouter()
 	rootRouter.Use(apimw.RequestLogger(logger))
+	if cfg.Metrics.Enabled {
+		rootRouter.Use(apimw.Metrics(metricsService))
+	}
 	rootRouter.Use(chimw.RequestID, chimw.RealIP, chimw.Recoverer, chimw.CleanPath, chimw.RedirectSlashes)
 	// API router
 	apiRouter := chi.NewRouter()
@@ -127,11 +179,19 @@ func New(cfg *config.Config, logger zerolog.Logger) (*App, error) {
 		r.Use(sessionManager.AuthMiddleware)
 		// Routes
 		authHandler.RegisterPrivate(r)
+		r.Route("/audit", func(r chi.Router) { auditHandler.Register(r) })
+		r.Route("/blocking", func(r chi.Router) { blockingHandler.Register(r) })
 		r.Route("/cluster/health", func(r chi.Router) { healthHandler.Register(r) })
+		r.Route("/devices", func(r chi.Router) { deviceHandler.Register(r) })
 		r.Route("/domain", func(r chi.Router) { domainRuleHandler.Register(r) })
 		r.Route("/events", func(r chi.Router) { eventsHandler.Register(r) })
+		r.Route("/gravity", func(r chi.Router) { gravityHandler.Register(r) })
+		r.Route("/groups", func(r chi.Router) { groupHandler.Register(r) })
+		r.Route("/lists", func(r chi.Router) { listHandler.Register(r) })
+		r.Route("/notifications", func(r chi.Router) { notificationHandler.Register(r) })
 		r.Route("/pihole", func(r chi.Router) { piholeHandler.Register(r) })
 		r.Route("/querylog", func(r chi.Router) { queryLogHandler.Register(r) })
+		r.Route("/stats", func(r chi.Router) { statsHandler.Register(r) })
 		r.Route("/user", func(r chi.Router) { userHandler.Register(r) })
 	})
 
@@ -147,6 +207,11 @@ func New(cfg *config.Config, logger zerolog.Logger) (*App, error) {
 		})
 	})
 
+	// Metrics
+	if cfg.Metrics.Enabled {
+		rootRouter.Route("/metrics", func(r chi.Router) { metricsHandler.Register(r) })
+	}
+
 	// Front end
 	frontendHandler.Register(rootRouter)
 
@@ -162,10 +227,12 @@ func New(cfg *config.Config, logger zerolog.Logger) (*App, error) {
 	logger.Info().Msg("application dependencies wired")
 
 	return &App{
-		Logger:        logger,
-		Server:        srv,
-		Sessions:      purgeAdapter{sessionManager},
-		HealthService: healthService,
+		Logger:            logger,
+		Server:            srv,
+		Sessions:          purgeAdapter{sessionManager},
+		HealthService:     healthService,
+		QueryLogCollector: queryLogService,
+		RuleExpiryService: ruleExpiryService,
 	}, nil
 }
 
@@ -177,6 +244,12 @@ func (a *App) Run(ctx context.Context) error {
 	// Start health service
 	go a.HealthService.Start(ctx)
 
+	// Start query log collector
+	go a.QueryLogCollector.Start(ctx)
+
+	// Start rule expiry service
+	go a.RuleExpiryService.Start(ctx)
+
 	// Start session purge loop
 	go a.Sessions.Start(ctx)
 
@@ -17,6 +17,15 @@ type Broker interface {
 type HealthService interface {
 	Start(ctx context.Context)
 }
+
+type QueryLogCollector interface {
+	Start(ctx context.Context)
+}
+
+type RuleExpiryService interface {
+	Start(ctx context.Context)
+}
+
 type HttpServer interface {
 	StartAndServe(ctx context.Context) error
 }
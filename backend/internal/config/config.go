@@ -10,11 +10,15 @@ import (
 )
 
 type Config struct {
-	Database      DatabaseConfig      `mapstructure:"database"`
-	EncryptionKey string              `mapstructure:"encryption_key"`
-	HealthService HealthServiceConfig `mapstructure:"health_service"`
-	Log           LoggingConfig       `mapstructure:"log"`
-	Server        ServerConfig        `mapstructure:"server"`
+	Database          DatabaseConfig          `mapstructure:"database"`
+	EncryptionKey     string                  `mapstructure:"encryption_key"`
+	HealthService     HealthServiceConfig     `mapstructure:"health_service"`
+	Log               LoggingConfig           `mapstructure:"log"`
+	Metrics           MetricsConfig           `mapstructure:"metrics"`
+	Notifications     NotificationsConfig     `mapstructure:"notifications"`
+	QueryLogService   QueryLogServiceConfig   `mapstructure:"query_log_service"`
+	RuleExpiryService RuleExpiryServiceConfig `mapstructure:"rule_expiry_service"`
+	Server            ServerConfig            `mapstructure:"server"`
 }
 
 type DatabaseConfig struct {
@@ -23,7 +27,100 @@ type DatabaseConfig struct {
 }
 
 type HealthServiceConfig struct {
+	GracePeriodSeconds     int                 `mapstructure:"grace_period_seconds"`
+	PollingIntervalSeconds int                 `mapstructure:"polling_interval_seconds"`
+	Monitor                HealthMonitorConfig `mapstructure:"monitor"`
+	History                HealthHistoryConfig `mapstructure:"history"`
+	Checks                 HealthChecksConfig  `mapstructure:"checks"`
+}
+
+// HealthMonitorConfig keeps nodes polled while nobody is watching the UI, so status changes can be
+// notified. Thresholds are consecutive sweeps a new status must be seen for before it is reported.
+type HealthMonitorConfig struct {
+	Enabled                bool `mapstructure:"enabled"`
+	PollingIntervalSeconds int  `mapstructure:"polling_interval_seconds"`
+	FailureThreshold       int  `mapstructure:"failure_threshold"`
+	RecoveryThreshold      int  `mapstructure:"recovery_threshold"`
+}
+
+type MetricsConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	BearerToken string `mapstructure:"bearer_token"` // scrapes must send "Authorization: Bearer <token>"
+}
+
+// HealthHistoryConfig controls how sweep results are kept. Samples are stored at full resolution for
+// SampleRetentionHours and as hourly rollups for RetentionDays.
+type HealthHistoryConfig struct {
+	Enabled              bool `mapstructure:"enabled"`
+	SampleRetentionHours int  `mapstructure:"sample_retention_hours"`
+	RetentionDays        int  `mapstructure:"retention_days"`
+}
+
+// HealthChecksConfig controls the checks run on top of the API status check. Any check that does not
+// pass marks the node degraded.
+type HealthChecksConfig struct {
+	Blocking           bool                 `mapstructure:"blocking"`
+	GravityMaxAgeHours int                  `mapstructure:"gravity_max_age_hours"` // 0 disables the gravity check
+	FTLVersion         bool                 `mapstructure:"ftl_version"`
+	DNSProbe           HealthDNSProbeConfig `mapstructure:"dns_probe"`
+}
+
+// HealthDNSProbeConfig resolves Domain directly against each node's DNS server.
+type HealthDNSProbeConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Domain  string `mapstructure:"domain"`
+	Port    int    `mapstructure:"port"`
+}
+
+type NotificationsConfig struct {
+	TimeoutSeconds    int                   `mapstructure:"timeout_seconds"`
+	MaxAttempts       int                   `mapstructure:"max_attempts"`
+	RetryDelaySeconds int                   `mapstructure:"retry_delay_seconds"`
+	RetentionDays     int                   `mapstructure:"retention_days"`
+	Webhook           WebhookNotifierConfig `mapstructure:"webhook"`
+	SMTP              SMTPNotifierConfig    `mapstructure:"smtp"`
+	Ntfy              NtfyNotifierConfig    `mapstructure:"ntfy"`
+}
+
+type WebhookNotifierConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+type SMTPNotifierConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	TLSMode  string   `mapstructure:"tls_mode"` // "starttls" | "tls" | "none"
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+type NtfyNotifierConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	URL      string `mapstructure:"url"` // full topic URL, e.g. https://ntfy.sh/my-topic
+	Token    string `mapstructure:"token"`
+	Priority string `mapstructure:"priority"`
+}
+
+type QueryLogServiceConfig struct {
+	CollectorEnabled       bool                 `mapstructure:"collector_enabled"`
+	PollingIntervalSeconds int                  `mapstructure:"polling_interval_seconds"`
+	RetentionHours         int                  `mapstructure:"retention_hours"`
+	BatchSize              int                  `mapstructure:"batch_size"`
+	Stream                 QueryLogStreamConfig `mapstructure:"stream"`
+}
+
+// QueryLogStreamConfig sets how nodes are polled while anyone is subscribed to the query log topic.
+type QueryLogStreamConfig struct {
+	PollingIntervalSeconds int `mapstructure:"polling_interval_seconds"`
 	GracePeriodSeconds     int `mapstructure:"grace_period_seconds"`
+}
+
+type RuleExpiryServiceConfig struct {
 	PollingIntervalSeconds int `mapstructure:"polling_interval_seconds"`
 }
 
@@ -101,7 +198,39 @@ func initConfig() error {
 	viper.SetDefault("encryption_key", "")
 	viper.SetDefault("health_service.grace_period_seconds", 10)
 	viper.SetDefault("health_service.polling_interval_seconds", 5)
+	viper.SetDefault("health_service.monitor.enabled", false)
+	viper.SetDefault("health_service.monitor.polling_interval_seconds", 30)
+	viper.SetDefault("health_service.monitor.failure_threshold", 3)
+	viper.SetDefault("health_service.monitor.recovery_threshold", 2)
+	viper.SetDefault("health_service.history.enabled", true)
+	viper.SetDefault("health_service.history.sample_retention_hours", 24)
+	viper.SetDefault("health_service.history.retention_days", 30)
+	viper.SetDefault("health_service.checks.blocking", true)
+	viper.SetDefault("health_service.checks.gravity_max_age_hours", 192)
+	viper.SetDefault("health_service.checks.ftl_version", true)
+	viper.SetDefault("health_service.checks.dns_probe.enabled", false)
+	viper.SetDefault("health_service.checks.dns_probe.domain", "pi.hole")
+	viper.SetDefault("health_service.checks.dns_probe.port", 53)
 	viper.SetDefault("log.level", "INFO")
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.bearer_token", "")
+	viper.SetDefault("notifications.timeout_seconds", 10)
+	viper.SetDefault("notifications.max_attempts", 3)
+	viper.SetDefault("notifications.retry_delay_seconds", 30)
+	viper.SetDefault("notifications.retention_days", 30)
+	viper.SetDefault("notifications.webhook.enabled", false)
+	viper.SetDefault("notifications.smtp.enabled", false)
+	viper.SetDefault("notifications.smtp.port", 587)
+	viper.SetDefault("notifications.smtp.tls_mode", "starttls")
+	viper.SetDefault("notifications.ntfy.enabled", false)
+	viper.SetDefault("notifications.ntfy.priority", "high")
+	viper.SetDefault("query_log_service.collector_enabled", true)
+	viper.SetDefault("query_log_service.polling_interval_seconds", 10)
+	viper.SetDefault("query_log_service.retention_hours", 24)
+	viper.SetDefault("query_log_service.batch_size", 1000)
+	viper.SetDefault("query_log_service.stream.polling_interval_seconds", 2)
+	viper.SetDefault("query_log_service.stream.grace_period_seconds", 10)
+	viper.SetDefault("rule_expiry_service.polling_interval_seconds", 15)
 	viper.SetDefault("server.port", 8081)
 	viper.SetDefault("server.tls_enabled", false)
 	viper.SetDefault("server.tls_cert_file", "")
@@ -160,6 +289,40 @@ func (c *Config) validate() error {
 		return fmt.Errorf("health_service.polling_interval_seconds must be greater than 1")
 	}
 
+	if c.HealthService.Monitor.Enabled && c.HealthService.Monitor.PollingIntervalSeconds < 1 {
+		return fmt.Errorf("health_service.monitor.polling_interval_seconds must be at least 1")
+	}
+	if c.HealthService.Monitor.FailureThreshold < 1 {
+		return fmt.Errorf("health_service.monitor.failure_threshold must be at least 1")
+	}
+	if c.HealthService.Monitor.RecoveryThreshold < 1 {
+		return fmt.Errorf("health_service.monitor.recovery_threshold must be at least 1")
+	}
+	if c.HealthService.History.Enabled {
+		if c.HealthService.History.SampleRetentionHours < 24 {
+			return fmt.Errorf("health_service.history.sample_retention_hours must be at least 24")
+		}
+		if c.HealthService.History.RetentionDays < 1 {
+			return fmt.Errorf("health_service.history.retention_days must be at least 1")
+		}
+	}
+	if c.HealthService.Checks.GravityMaxAgeHours < 0 {
+		return fmt.Errorf("health_service.checks.gravity_max_age_hours must not be negative")
+	}
+	if c.HealthService.Checks.DNSProbe.Enabled {
+		if strings.TrimSpace(c.HealthService.Checks.DNSProbe.Domain) == "" {
+			return fmt.Errorf("health_service.checks.dns_probe.domain is required when the DNS probe is enabled")
+		}
+		if c.HealthService.Checks.DNSProbe.Port < 1 || c.HealthService.Checks.DNSProbe.Port > 65535 {
+			return fmt.Errorf("health_service.checks.dns_probe.port must be between 1 and 65535")
+		}
+	}
+
+	// Metrics
+	if c.Metrics.Enabled && strings.TrimSpace(c.Metrics.BearerToken) == "" {
+		return fmt.Errorf("metrics.bearer_token is required when metrics are enabled")
+	}
+
 	// Logs
 	if _, ok := validLevels[strings.ToUpper(c.Log.Level)]; !ok {
 		return fmt.Errorf("log.level must be a valid log level, got: %s", c.Log.Level)
@@ -208,6 +371,66 @@ func (c *Config) validate() error {
 		return fmt.Errorf("server.session.secure=true requires TLS or allow_insecure_cookie=true")
 	}
 
+	// Query log service
+	if c.QueryLogService.CollectorEnabled && c.QueryLogService.PollingIntervalSeconds <= 0 {
+		return fmt.Errorf("query_log_service.polling_interval_seconds must be greater than 0")
+	}
+	// Streaming polls nodes whether or not the collector is enabled
+	if c.QueryLogService.RetentionHours <= 0 {
+		return fmt.Errorf("query_log_service.retention_hours must be greater than 0")
+	}
+	if c.QueryLogService.BatchSize <= 0 {
+		return fmt.Errorf("query_log_service.batch_size must be greater than 0")
+	}
+	if c.QueryLogService.Stream.PollingIntervalSeconds <= 0 {
+		return fmt.Errorf("query_log_service.stream.polling_interval_seconds must be greater than 0")
+	}
+	if c.QueryLogService.Stream.GracePeriodSeconds < 0 {
+		return fmt.Errorf("query_log_service.stream.grace_period_seconds must not be negative")
+	}
+
+	// Notifications
+	n := c.Notifications
+	if n.TimeoutSeconds <= 0 {
+		return fmt.Errorf("notifications.timeout_seconds must be greater than 0")
+	}
+	if n.MaxAttempts < 1 {
+		return fmt.Errorf("notifications.max_attempts must be at least 1")
+	}
+	if n.RetryDelaySeconds < 0 {
+		return fmt.Errorf("notifications.retry_delay_seconds may not be negative")
+	}
+	if n.RetentionDays < 1 {
+		return fmt.Errorf("notifications.retention_days must be at least 1")
+	}
+	if n.Webhook.Enabled && strings.TrimSpace(n.Webhook.URL) == "" {
+		return fmt.Errorf("notifications.webhook.url is required when the webhook notifier is enabled")
+	}
+	if n.SMTP.Enabled {
+		if strings.TrimSpace(n.SMTP.Host) == "" {
+			return fmt.Errorf("notifications.smtp.host is required when the smtp notifier is enabled")
+		}
+		if n.SMTP.Port <= 0 || n.SMTP.Port > 65535 {
+			return fmt.Errorf("notifications.smtp.port must be a valid TCP port")
+		}
+		switch strings.ToLower(n.SMTP.TLSMode) {
+		case "starttls", "tls", "none":
+		default:
+			return fmt.Errorf("notifications.smtp.tls_mode must be one of starttls, tls, none (got %s)", n.SMTP.TLSMode)
+		}
+		if strings.TrimSpace(n.SMTP.From) == "" || len(n.SMTP.To) == 0 {
+			return fmt.Errorf("notifications.smtp.from and notifications.smtp.to are required when the smtp notifier is enabled")
+		}
+	}
+	if n.Ntfy.Enabled && strings.TrimSpace(n.Ntfy.URL) == "" {
+		return fmt.Errorf("notifications.ntfy.url is required when the ntfy notifier is enabled")
+	}
+
+	// Rule expiry service
+	if c.RuleExpiryService.PollingIntervalSeconds <= 0 {
+		return fmt.Errorf("rule_expiry_service.polling_interval_seconds must be greater than 0")
+	}
+
 	// Server - Server Side Events
 	if c.Server.ServerSideEvents.HeartbeatSeconds < 0 {
 		return fmt.Errorf("server.server_side_events.heartbeat_seconds must be greater than 0")
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	Id           int64              `json:"id"`
+	UserId       *int64             `json:"userId,omitempty"`
+	Action       string             `json:"action"`
+	Route        string             `json:"route"`
+	Target       string             `json:"target"`
+	Details      json.RawMessage    `json:"details,omitempty"`
+	NodeOutcomes []AuditNodeOutcome `json:"nodeOutcomes"`
+	Success      bool               `json:"success"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+type AuditNodeOutcome struct {
+	PiholeNode PiholeNodeRef `json:"piholeNode"`
+	Success    bool          `json:"success"`
+	Error      string        `json:"error,omitempty"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// DomainRuleExpiration schedules a temporary rule for removal from the nodes where it was added.
+type DomainRuleExpiration struct {
+	Id            int64     `json:"id"`
+	Type          string    `json:"type"`
+	Kind          string    `json:"kind"`
+	Domain        string    `json:"domain"`
+	PiholeNodeIds []int64   `json:"piholeNodeIds"` // nil means every node
+	UserId        *int64    `json:"userId,omitempty"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// DomainRuleChangeSet is one domain rule write made through the app, recorded so it can be reverted.
+type DomainRuleChangeSet struct {
+	Id           int64              `json:"id"`
+	UserId       *int64             `json:"userId,omitempty"`
+	Action       string             `json:"action"` // add, remove, reconcile, revert
+	RevertsId    *int64             `json:"revertsId,omitempty"`
+	Changes      []DomainRuleChange `json:"changes"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	RevertedAt   *time.Time         `json:"revertedAt,omitempty"`
+	RevertedById *int64             `json:"revertedById,omitempty"`
+}
+
+// DomainRuleChange is the state transition of a single rule on a single node. A nil state means the
+// rule did not exist on the node.
+type DomainRuleChange struct {
+	PiholeNode PiholeNodeRef    `json:"piholeNode"`
+	Type       string           `json:"type"`
+	Kind       string           `json:"kind"`
+	Domain     string           `json:"domain"`
+	Previous   *DomainRuleState `json:"previous"`
+	Current    *DomainRuleState `json:"current"`
+}
+
+type DomainRuleState struct {
+	Comment *string `json:"comment,omitempty"`
+	Groups  []int   `json:"groups"`
+	Enabled bool    `json:"enabled"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// NodeHealthSample is the outcome of one health sweep for one node.
+type NodeHealthSample struct {
+	PiholeId  int64     `json:"piholeId"`
+	Time      time.Time `json:"time"`
+	Status    string    `json:"status"`
+	LatencyMS int       `json:"latencyMs"`
+	// IntervalSeconds is how often sweeps ran when this one was taken, i.e. the time it stands for
+	IntervalSeconds int `json:"intervalSeconds"`
+}
+
+// NodeHealthRollup counts the sweeps in one hour that had the given status and latency bucket, and
+// the time they stand for.
+type NodeHealthRollup struct {
+	PiholeId      int64     `json:"piholeId"`
+	BucketStart   time.Time `json:"bucketStart"`
+	Status        string    `json:"status"`
+	LatencyBucket int       `json:"latencyBucket"`
+	Count         int64     `json:"count"`
+	Seconds       int64     `json:"seconds"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// NodeStatusTransition is a debounced change in a node's health status.
+type NodeStatusTransition struct {
+	PiholeNode PiholeNodeRef `json:"piholeNode"`
+	From       string        `json:"from"` // empty when the node's status was not known yet
+	To         string        `json:"to"`
+	Error      string        `json:"error,omitempty"`
+	At         time.Time     `json:"at"`
+}
+
+// NotificationDelivery records a single attempt to deliver a notification on one channel.
+type NotificationDelivery struct {
+	Id         int64     `json:"id"`
+	Channel    string    `json:"channel"`
+	Event      string    `json:"event"`
+	PiholeId   *int64    `json:"piholeId,omitempty"`
+	PiholeName *string   `json:"piholeName,omitempty"`
+	Subject    string    `json:"subject"`
+	Attempt    int       `json:"attempt"`
+	Success    bool      `json:"success"`
+	Error      *string   `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
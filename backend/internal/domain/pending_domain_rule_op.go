@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// PendingDomainRuleOp is a domain rule write that could not reach a node and is waiting to be
+// replayed once the node is back online.
+type PendingDomainRuleOp struct {
+	Id            int64      `json:"id"`
+	PiholeId      int64      `json:"piholeId"`
+	Action        string     `json:"action"` // add, update or remove
+	Type          string     `json:"type"`
+	Kind          string     `json:"kind"`
+	Domain        string     `json:"domain"`
+	Comment       *string    `json:"comment,omitempty"`
+	Groups        []int      `json:"groups,omitempty"`
+	GroupNames    []string   `json:"groupNames,omitempty"`
+	Enabled       *bool      `json:"enabled,omitempty"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+}
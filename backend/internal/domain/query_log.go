@@ -0,0 +1,32 @@
+package domain
+
+// QueryLogEntry is a DNS query collected from a Pi-hole node into the local query log.
+type QueryLogEntry struct {
+	Id         int64   `json:"id"`
+	PiholeId   int64   `json:"piholeId"`
+	QueryId    int64   `json:"queryId"`
+	Time       float64 `json:"time"`
+	Type       string  `json:"type"`
+	Status     string  `json:"status"`
+	DNSSEC     string  `json:"dnssec"`
+	Domain     string  `json:"domain"`
+	Upstream   *string `json:"upstream,omitempty"`
+	ReplyType  string  `json:"replyType"`
+	ReplyTime  float64 `json:"replyTime"`
+	ClientIP   string  `json:"clientIp"`
+	ClientName *string `json:"clientName,omitempty"`
+	ListId     *int64  `json:"listId,omitempty"`
+	EDECode    int64   `json:"edeCode"`
+	EDEText    *string `json:"edeText,omitempty"`
+	CNAME      *string `json:"cname,omitempty"`
+}
+
+// QueryLogCollectPosition is where the collector stopped reading a node's backlog. The time range and
+// the node's cursor are pinned, so the read continues at Start.
+type QueryLogCollectPosition struct {
+	PiholeId int64
+	From     int64
+	Until    int64
+	Cursor   int
+	Start    int
+}
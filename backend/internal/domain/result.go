@@ -7,3 +7,10 @@ type NodeResult[T any] struct {
 	ErrorString string        `json:"error,omitempty"`
 	Response    *T            `json:"response,omitempty"`
 }
+
+// UnreachableNode is a node that failed to answer a cluster-wide read. Reports list these apart from
+// the nodes that answered, so they are never mistaken for nodes that lack something.
+type UnreachableNode struct {
+	PiholeNode PiholeNodeRef `json:"piholeNode"`
+	Error      string        `json:"error,omitempty"`
+}
@@ -0,0 +1,96 @@
+package audithandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+func (h *Handler) Register(r chi.Router) {
+	r.Get("/", h.list)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	var params auditservice.ListParams
+	query := r.URL.Query()
+
+	if v := query.Get("limit"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		params.Limit = i
+	}
+	if v := query.Get("offset"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		params.Offset = i
+	}
+	if v := query.Get("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid user_id", http.StatusBadRequest)
+			return
+		}
+		params.UserId = &id
+	}
+	if v := query.Get("action"); v != "" {
+		params.Action = &v
+	}
+	if v := query.Get("success"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid success", http.StatusBadRequest)
+			return
+		}
+		params.Success = &b
+	}
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid 'from' time", http.StatusBadRequest)
+			return
+		}
+		params.From = &t
+	}
+	if v := query.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid 'until' time", http.StatusBadRequest)
+			return
+		}
+		params.Until = &t
+	}
+
+	result, err := h.service.List(params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error getting audit log entries from database")
+		httpx.WriteJSONError(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Debug().Int("count", len(result.Entries)).Int64("total", result.Total).Msg("fetched audit log entries")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
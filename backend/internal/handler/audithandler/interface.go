@@ -0,0 +1,7 @@
+package audithandler
+
+import "github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+
+type service interface {
+	List(params auditservice.ListParams) (*auditservice.ListResult, error)
+}
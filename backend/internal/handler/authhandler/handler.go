@@ -42,7 +42,7 @@ func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, sessionId, err := h.service.Login(body)
+	user, sessionId, err := h.service.Login(r.Context(), body)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("logging in")
 		httpx.WriteJSONErrorFromErr(w, err)
@@ -62,7 +62,7 @@ func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = h.service.Logout(cookie.Value)
+	_ = h.service.Logout(r.Context(), cookie.Value)
 
 	expired := h.httpCookieFactory.Cookie("")
 	expired.Expires = time.Now().Add(-1 * time.Hour)
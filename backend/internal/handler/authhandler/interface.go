@@ -1,6 +1,7 @@
 package authhandler
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
@@ -8,8 +9,8 @@ import (
 )
 
 type service interface {
-	Login(params authservice.LoginParams) (*domain.User, string, error)
-	Logout(sessionId string) error
+	Login(ctx context.Context, params authservice.LoginParams) (*domain.User, string, error)
+	Logout(ctx context.Context, sessionId string) error
 	GetUser(id int64) (*domain.User, error)
 }
 
@@ -0,0 +1,87 @@
+package blockinghandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/blockingservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+// Register mounts the blocking routes. Enable and disable take an optional "timer" query parameter,
+// the number of seconds after which each node flips back.
+func (h *Handler) Register(r chi.Router) {
+	r.Get("/", h.getBlocking)
+	r.Post("/enable", h.enableBlocking)
+	r.Post("/disable", h.disableBlocking)
+}
+
+func (h *Handler) getBlocking(w http.ResponseWriter, r *http.Request) {
+	results := h.service.Get(r.Context())
+
+	for _, nr := range results {
+		if nr.Error != nil {
+			h.logger.Warn().Err(nr.Error).Msg("partial failure getting blocking status")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) enableBlocking(w http.ResponseWriter, r *http.Request) {
+	h.setBlocking(w, r, true)
+}
+
+func (h *Handler) disableBlocking(w http.ResponseWriter, r *http.Request) {
+	h.setBlocking(w, r, false)
+}
+
+func (h *Handler) setBlocking(w http.ResponseWriter, r *http.Request, blocking bool) {
+	var timer *int
+	if v := r.URL.Query().Get("timer"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			h.logger.Error().Str("timer", v).Msg("bad \"timer\" parameter")
+			httpx.WriteJSONError(w, "\"timer\" must be a positive number of seconds", http.StatusBadRequest)
+			return
+		}
+		timer = &parsed
+	}
+
+	logger := h.logger.With().Bool("blocking", blocking).Logger()
+	if timer != nil {
+		logger = logger.With().Int("timer", *timer).Logger()
+	}
+	logger.Debug().Msg("setting blocking status")
+
+	results := h.service.Set(r.Context(), blockingservice.SetParams{Blocking: blocking, Timer: timer})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure setting blocking status")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
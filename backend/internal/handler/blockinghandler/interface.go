@@ -0,0 +1,14 @@
+package blockinghandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/blockingservice"
+)
+
+type service interface {
+	Get(ctx context.Context) map[int64]*domain.NodeResult[pihole.BlockingResponse]
+	Set(ctx context.Context, params blockingservice.SetParams) map[int64]*domain.NodeResult[pihole.BlockingResponse]
+}
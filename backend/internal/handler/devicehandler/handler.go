@@ -0,0 +1,194 @@
+package devicehandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+// Register mounts the device routes. A device may be a subnet such as 192.168.1.0/24, so an
+// existing device is identified by the "client" query parameter rather than a path segment.
+func (h *Handler) Register(r chi.Router) {
+	// Read
+	r.Get("/", h.getAll)
+	r.Get("/merged", h.getMerged)
+	// Write
+	r.Post("/", h.addDevice)
+	r.Patch("/", h.updateDevice)
+	r.Delete("/", h.removeDevice)
+}
+
+func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
+	results := h.service.GetAll(r.Context())
+
+	for _, nr := range results {
+		if nr.Error != nil {
+			h.logger.Warn().Err(nr.Error).Msg("partial failure getting devices")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getMerged(w http.ResponseWriter, r *http.Request) {
+	merged := h.service.GetMerged(r.Context())
+
+	for _, node := range merged.UnreachableNodes {
+		h.logger.Warn().Int64("id", node.PiholeNode.Id).Str("error", node.Error).Msg("partial failure getting devices")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(merged); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) addDevice(w http.ResponseWriter, r *http.Request) {
+	var body pihole.AddDevicePayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// --- Normalize Client to []string
+	var clients []string
+	switch v := body.Client.(type) {
+	case string:
+		clients = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				h.logger.Error().Interface("client", item).Msg("client list must contain only strings")
+				httpx.WriteJSONError(w, "client list must contain only strings", http.StatusBadRequest)
+				return
+			}
+			clients = append(clients, s)
+		}
+	default:
+		h.logger.Error().Interface("client", v).Msg("client must be string or array of strings")
+		httpx.WriteJSONError(w, "client must be string or array of strings", http.StatusBadRequest)
+		return
+	}
+	body.Client = clients
+
+	logger := h.logger.With().Strs("clients", clients).Logger()
+	logger.Debug().Msg("adding device")
+
+	results, err := h.service.Add(r.Context(), pihole.AddDeviceOptions{Payload: body})
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid device")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure adding device")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.parseClient(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("client", client).Logger()
+
+	var body pihole.UpdateDevicePayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Comment == nil && body.Groups == nil && body.GroupNames == nil {
+		logger.Error().Msg("empty update")
+		httpx.WriteJSONError(w, "at least one of comment, groups or groupNames is required", http.StatusBadRequest)
+		return
+	}
+	if body.Groups != nil && body.GroupNames != nil {
+		logger.Error().Msg("both groups and groupNames set")
+		httpx.WriteJSONError(w, "only one of groups or groupNames may be set", http.StatusBadRequest)
+		return
+	}
+
+	logger.Debug().Msg("updating device")
+
+	results := h.service.Update(r.Context(), pihole.UpdateDeviceOptions{Client: client, Payload: body})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure updating device")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) removeDevice(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.parseClient(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("client", client).Logger()
+	logger.Debug().Msg("removing device")
+
+	results := h.service.Remove(r.Context(), pihole.RemoveDeviceOptions{Client: client})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure removing device")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) parseClient(w http.ResponseWriter, r *http.Request) (string, bool) {
+	client := strings.TrimSpace(r.URL.Query().Get("client"))
+	if client == "" {
+		h.logger.Error().Msg("empty \"client\" parameter")
+		httpx.WriteJSONError(w, "empty \"client\" parameter", http.StatusBadRequest)
+		return "", false
+	}
+	return client, true
+}
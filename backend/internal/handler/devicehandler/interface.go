@@ -0,0 +1,17 @@
+package devicehandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/deviceservice"
+)
+
+type service interface {
+	GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetDevicesResponse]
+	GetMerged(ctx context.Context) *deviceservice.MergedDevices
+	Add(ctx context.Context, opts pihole.AddDeviceOptions) (map[int64]*domain.NodeResult[pihole.AddDeviceResponse], error)
+	Update(ctx context.Context, opts pihole.UpdateDeviceOptions) map[int64]*domain.NodeResult[pihole.UpdateDeviceResponse]
+	Remove(ctx context.Context, opts pihole.RemoveDeviceOptions) map[int64]*domain.NodeResult[pihole.RemoveDeviceResponse]
+}
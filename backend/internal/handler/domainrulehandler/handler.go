@@ -2,9 +2,13 @@ package domainrulehandler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/domainruleservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog"
@@ -27,9 +31,29 @@ func (h *Handler) Register(r chi.Router) {
 	r.Get("/domain/{domain}", h.getByDomain)
 	r.Get("/type/{type}/kind/{kind}", h.getByTypeKind)
 	r.Get("/type/{type}/kind/{kind}/domain/{domain}", h.getByTypeKindDomain)
+	r.Get("/drift", h.getDrift)
 	// Write
 	r.Post("/type/{type}/kind/{kind}", h.addDomainRule)
+	r.Patch("/type/{type}/kind/{kind}/domain/{domain}", h.updateDomainRule)
 	r.Delete("/type/{type}/kind/{kind}/domain/{domain}", h.removeDomainRule)
+	r.Post("/reconcile", h.reconcile)
+	// Import and export
+	r.Get("/export", h.export)
+	r.Post("/import/type/{type}/kind/{kind}", h.importDomainRules)
+	// Regex tools
+	r.Post("/regex/test", h.testRegex)
+	// History
+	r.Get("/history", h.getHistory)
+	r.Get("/history/{id}", h.getHistoryById)
+	r.Post("/history/revert", h.revertSince)
+	r.Post("/history/{id}/revert", h.revert)
+	// Temporary rules
+	r.Get("/expirations", h.getExpirations)
+	r.Delete("/expirations/{id}", h.cancelExpiration)
+	// Pending operations for unreachable nodes
+	r.Get("/pending", h.getPending)
+	r.Delete("/pending/{id}", h.discardPending)
+	r.Post("/pending/node/{id}/replay", h.replayPending)
 }
 
 func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
@@ -217,6 +241,28 @@ func (h *Handler) getByTypeKindDomain(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *Handler) getDrift(w http.ResponseWriter, r *http.Request) {
+	driftOnly, err := parseBoolQuery(r, "drift_only")
+	if err != nil {
+		h.logger.Error().Err(err).Msg("bad \"drift_only\" parameter")
+		httpx.WriteJSONError(w, "bad \"drift_only\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	report := h.service.GetDrift(r.Context(), driftOnly)
+
+	for _, node := range report.UnreachableNodes {
+		h.logger.Warn().Int64("id", node.PiholeNode.Id).Str("error", node.Error).Msg("node excluded from drift report")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 func (h *Handler) addDomainRule(w http.ResponseWriter, r *http.Request) {
 	typeString := chi.URLParam(r, "type")
 	kindString := chi.URLParam(r, "kind")
@@ -235,7 +281,25 @@ func (h *Handler) addDomainRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Logger()
+	atomic, err := parseBoolQuery(r, "atomic")
+	if err != nil {
+		h.logger.Error().Err(err).Msg("bad \"atomic\" parameter")
+		httpx.WriteJSONError(w, "bad \"atomic\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	var expiresIn time.Duration
+	if v := r.URL.Query().Get("expires_in_minutes"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes <= 0 {
+			h.logger.Error().Err(err).Msg("bad \"expires_in_minutes\" parameter")
+			httpx.WriteJSONError(w, "bad \"expires_in_minutes\" parameter", http.StatusBadRequest)
+			return
+		}
+		expiresIn = time.Duration(minutes) * time.Minute
+	}
+
+	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Bool("atomic", atomic).Dur("expires_in", expiresIn).Logger()
 
 	// --- Parse JSON body
 	var body pihole.AddDomainPayload
@@ -275,17 +339,116 @@ func (h *Handler) addDomainRule(w http.ResponseWriter, r *http.Request) {
 		Kind:    ruleKind,
 		Payload: body,
 	}
-	results := h.service.Add(r.Context(), opts)
 
+	var response any
+	if expiresIn > 0 {
+		result, err := h.service.AddTemporary(r.Context(), opts, atomic, expiresIn)
+		if err != nil && result == nil {
+			logger.Error().Err(err).Msg("invalid domain rule")
+			httpx.WriteValidationError(w, err)
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Msg("error scheduling domain rule expiry")
+			httpx.WriteJSONError(w, "domain rule added, but scheduling its expiry failed", http.StatusInternalServerError)
+			return
+		}
+		response = result
+	} else if atomic {
+		result, err := h.service.AddAtomic(r.Context(), opts)
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid domain rule")
+			httpx.WriteValidationError(w, err)
+			return
+		}
+		if result.RolledBack {
+			logger.Warn().Msg("domain rule add failed on at least one node and was rolled back")
+		}
+		response = result
+	} else {
+		results, err := h.service.Add(r.Context(), opts)
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid domain rule")
+			httpx.WriteValidationError(w, err)
+			return
+		}
+		for _, nr := range results {
+			if nr.Error != nil {
+				logger.Warn().Err(nr.Error).Msg("partial failure adding domain rule")
+			}
+		}
+		response = results
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) updateDomainRule(w http.ResponseWriter, r *http.Request) {
+	typeString := chi.URLParam(r, "type")
+	kindString := chi.URLParam(r, "kind")
+	domainString := chi.URLParam(r, "domain")
+
+	ruleType, ok := pihole.ParseRuleType(typeString)
+	if !ok {
+		h.logger.Error().Msg("bad \"type\" parameter")
+		httpx.WriteJSONError(w, "bad \"type\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	ruleKind, ok := pihole.ParseRuleKind(kindString)
+	if !ok {
+		h.logger.Error().Msg("bad \"kind\" parameter")
+		httpx.WriteJSONError(w, "bad \"kind\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	if len(domainString) == 0 {
+		h.logger.Error().Msg("empty \"domain\" parmeter")
+		httpx.WriteJSONError(w, "empty \"domain\" parmeter", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Str("domain", domainString).Logger()
+
+	var body pihole.UpdateDomainPayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Comment == nil && body.Groups == nil && body.GroupNames == nil && body.Enabled == nil {
+		logger.Error().Msg("empty update")
+		httpx.WriteJSONError(w, "at least one of comment, groups, groupNames or enabled is required", http.StatusBadRequest)
+		return
+	}
+	if body.Groups != nil && body.GroupNames != nil {
+		logger.Error().Msg("both groups and groupNames set")
+		httpx.WriteJSONError(w, "only one of groups or groupNames may be set", http.StatusBadRequest)
+		return
+	}
+
+	logger.Debug().Msg("updating domain rule")
+
+	results := h.service.Update(r.Context(), pihole.UpdateDomainRuleOptions{
+		Type:    ruleType,
+		Kind:    ruleKind,
+		Domain:  domainString,
+		Payload: body,
+	})
 	for _, nr := range results {
 		if nr.Error != nil {
-			logger.Warn().Err(nr.Error).Msg("partial failure adding domain rule")
+			logger.Warn().Err(nr.Error).Msg("partial failure updating domain rule")
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-
 	if err := json.NewEncoder(w).Encode(results); err != nil {
 		logger.Error().Err(err).Msg("failed to encode response")
 		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
@@ -317,7 +480,14 @@ func (h *Handler) removeDomainRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Str("domain", domainString).Logger()
+	atomic, err := parseBoolQuery(r, "atomic")
+	if err != nil {
+		h.logger.Error().Err(err).Msg("bad \"atomic\" parameter")
+		httpx.WriteJSONError(w, "bad \"atomic\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Str("domain", domainString).Bool("atomic", atomic).Logger()
 	logger.Debug().Msg("removing domain rule")
 
 	opts := pihole.RemoveDomainRuleOptions{
@@ -325,22 +495,411 @@ func (h *Handler) removeDomainRule(w http.ResponseWriter, r *http.Request) {
 		Kind:   ruleKind,
 		Domain: domainString,
 	}
-	results := h.service.Remove(r.Context(), opts)
 
-	for _, nr := range results {
+	var response any
+	if atomic {
+		result := h.service.RemoveAtomic(r.Context(), opts)
+		if result.RolledBack {
+			logger.Warn().Msg("domain rule removal failed on at least one node and was rolled back")
+		}
+		response = result
+	} else {
+		results := h.service.Remove(r.Context(), opts)
+		for _, nr := range results {
+			if nr.Error != nil {
+				logger.Warn().Err(nr.Error).Msg("partial failure removing domain rule")
+			}
+		}
+		response = results
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) reconcile(w http.ResponseWriter, r *http.Request) {
+	var body domainruleservice.ReconcileParams
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("source", string(body.Source)).Bool("dry_run", body.DryRun).Logger()
+	if body.NodeId != nil {
+		logger = logger.With().Int64("node_id", *body.NodeId).Logger()
+	}
+	logger.Debug().Msg("reconciling domain rules")
+
+	result, err := h.service.Reconcile(r.Context(), body)
+	if err != nil {
+		logger.Error().Err(err).Msg("error reconciling domain rules")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	for _, nr := range result.Results {
 		if nr.Error != nil {
-			logger.Warn().Err(nr.Error).Msg("partial failure removing domain rule")
+			logger.Warn().Err(nr.Error).Int64("id", nr.PiholeNode.Id).Msg("partial failure reconciling domain rules")
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(results); err != nil {
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := domainruleservice.ExportParams{Format: domainruleservice.ExportFormat(query.Get("format"))}
+	if v := query.Get("type"); v != "" {
+		ruleType, ok := pihole.ParseRuleType(v)
+		if !ok {
+			h.logger.Error().Msg("bad \"type\" parameter")
+			httpx.WriteJSONError(w, "bad \"type\" parameter", http.StatusBadRequest)
+			return
+		}
+		params.Type = &ruleType
+	}
+	if v := query.Get("kind"); v != "" {
+		ruleKind, ok := pihole.ParseRuleKind(v)
+		if !ok {
+			h.logger.Error().Msg("bad \"kind\" parameter")
+			httpx.WriteJSONError(w, "bad \"kind\" parameter", http.StatusBadRequest)
+			return
+		}
+		params.Kind = &ruleKind
+	}
+
+	logger := h.logger.With().Str("format", string(params.Format)).Logger()
+	logger.Debug().Msg("exporting domain rules")
+
+	file, err := h.service.Export(r.Context(), params)
+	if err != nil {
+		logger.Error().Err(err).Msg("error exporting domain rules")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(file.Body); err != nil {
+		logger.Error().Err(err).Msg("failed to write response")
+	}
+}
+
+func (h *Handler) importDomainRules(w http.ResponseWriter, r *http.Request) {
+	ruleType, ok := pihole.ParseRuleType(chi.URLParam(r, "type"))
+	if !ok {
+		h.logger.Error().Msg("bad \"type\" parameter")
+		httpx.WriteJSONError(w, "bad \"type\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	ruleKind, ok := pihole.ParseRuleKind(chi.URLParam(r, "kind"))
+	if !ok {
+		h.logger.Error().Msg("bad \"kind\" parameter")
+		httpx.WriteJSONError(w, "bad \"kind\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	var body domainruleservice.ImportParams
+	if err := httpx.DecodeJSONBody(w, r, &body, 16<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(ruleType)).Str("kind", string(ruleKind)).Str("format", string(body.Format)).Logger()
+	logger.Debug().Msg("importing domain rules")
+
+	result, err := h.service.Import(r.Context(), ruleType, ruleKind, body)
+	if err != nil {
+		logger.Error().Err(err).Msg("error importing domain rules")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	for _, nr := range result.Results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Int64("id", nr.PiholeNode.Id).Msg("partial failure importing domain rules")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) testRegex(w http.ResponseWriter, r *http.Request) {
+	var body domainruleservice.RegexTestParams
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("regex", body.Regex).Bool("query_log", body.QueryLog).Logger()
+	logger.Debug().Msg("testing regex")
+
+	result, err := h.service.TestRegex(r.Context(), body)
+	if err != nil {
+		logger.Error().Err(err).Msg("error testing regex")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
+	var params domainruleservice.HistoryListParams
+	query := r.URL.Query()
+	if v := query.Get("limit"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		params.Limit = i
+	}
+	if v := query.Get("offset"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		params.Offset = i
+	}
+
+	result, err := h.service.ListHistory(params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error getting domain rule history from database")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getHistoryById(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	changeSet, err := h.service.GetHistory(id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("error getting domain rule change set")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(changeSet); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) revert(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Int64("change_set_id", id).Logger()
+	logger.Debug().Msg("reverting domain rule change set")
+
+	result, err := h.service.Revert(r.Context(), id)
+	if err != nil {
+		logger.Error().Err(err).Msg("error reverting domain rule change set")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+	if !result.Complete {
+		logger.Warn().Msg("domain rule change set was only partially reverted")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
 		logger.Error().Err(err).Msg("failed to encode response")
 		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
 	}
 }
 
+func (h *Handler) revertSince(w http.ResponseWriter, r *http.Request) {
+	var body domainruleservice.RevertSinceParams
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Since == nil {
+		httpx.WriteJSONError(w, "\"since\" is required", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Time("since", *body.Since).Logger()
+	logger.Debug().Msg("reverting domain rule changes")
+
+	result, err := h.service.RevertSince(r.Context(), *body.Since)
+	if err != nil {
+		logger.Error().Err(err).Msg("error reverting domain rule changes")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+	if !result.Complete {
+		logger.Warn().Int("reverted", len(result.Reverts)).Msg("stopped reverting domain rule changes after a partial revert")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getExpirations(w http.ResponseWriter, r *http.Request) {
+	expirations, err := h.service.GetExpirations()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error getting domain rule expirations from database")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(expirations); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) cancelExpiration(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelExpiration(id); err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("error cancelling domain rule expiration")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getPending(w http.ResponseWriter, r *http.Request) {
+	var piholeId *int64
+	if v := r.URL.Query().Get("node_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid node_id", http.StatusBadRequest)
+			return
+		}
+		piholeId = &id
+	}
+
+	ops, err := h.service.GetPending(piholeId)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error getting pending domain rule operations from database")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ops); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) discardPending(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DiscardPending(id); err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("error discarding pending domain rule operation")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) replayPending(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ReplayPending(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("error replaying pending domain rule operations")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) parseIdParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idString := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Error().Err(err).Msg("error converting path parameter id to int64")
+		httpx.WriteJSONError(w, "error processing id path parameter", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
 func parseDomainPath(parts []string) (typeParam *pihole.RuleType, kindParam *pihole.RuleKind, domainParam *string) {
 	switch len(parts) {
 	case 0:
@@ -2,9 +2,11 @@ package domainrulehandler
 
 import (
 	"context"
+	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/domainruleservice"
 )
 
 type service interface {
@@ -14,6 +16,24 @@ type service interface {
 	GetByDomain(ctx context.Context, opts pihole.GetDomainRulesByDomainOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetByTypeKind(ctx context.Context, opts pihole.GetDomainRulesByTypeKindOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetByTypeKindDomain(ctx context.Context, opts pihole.GetDomainRulesByTypeKindDomainOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
-	Add(ctx context.Context, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	Add(ctx context.Context, opts pihole.AddDomainRuleOptions) (map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], error)
+	Update(ctx context.Context, opts pihole.UpdateDomainRuleOptions) map[int64]*domain.NodeResult[pihole.UpdateDomainRuleResponse]
 	Remove(ctx context.Context, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]
+	AddAtomic(ctx context.Context, opts pihole.AddDomainRuleOptions) (domainruleservice.AtomicResult[pihole.AddDomainRuleResponse], error)
+	RemoveAtomic(ctx context.Context, opts pihole.RemoveDomainRuleOptions) domainruleservice.AtomicResult[pihole.RemoveDomainRuleResponse]
+	GetDrift(ctx context.Context, driftOnly bool) domainruleservice.DriftReport
+	Reconcile(ctx context.Context, params domainruleservice.ReconcileParams) (*domainruleservice.ReconcileResult, error)
+	TestRegex(ctx context.Context, params domainruleservice.RegexTestParams) (*domainruleservice.RegexTestResult, error)
+	Export(ctx context.Context, params domainruleservice.ExportParams) (*domainruleservice.ExportFile, error)
+	Import(ctx context.Context, ruleType pihole.RuleType, ruleKind pihole.RuleKind, params domainruleservice.ImportParams) (*domainruleservice.ImportResult, error)
+	ListHistory(params domainruleservice.HistoryListParams) (*domainruleservice.HistoryList, error)
+	GetHistory(id int64) (*domain.DomainRuleChangeSet, error)
+	Revert(ctx context.Context, id int64) (*domainruleservice.RevertResult, error)
+	RevertSince(ctx context.Context, since time.Time) (*domainruleservice.RevertSinceResult, error)
+	AddTemporary(ctx context.Context, opts pihole.AddDomainRuleOptions, atomic bool, ttl time.Duration) (*domainruleservice.TemporaryAddResult, error)
+	GetExpirations() ([]*domain.DomainRuleExpiration, error)
+	CancelExpiration(id int64) error
+	GetPending(piholeId *int64) ([]*domain.PendingDomainRuleOp, error)
+	DiscardPending(id int64) error
+	ReplayPending(ctx context.Context, id int64) (*domain.NodeResult[domainruleservice.ReconcileNodeResponse], error)
 }
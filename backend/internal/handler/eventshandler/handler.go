@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/eventsservice"
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog"
 )
@@ -31,7 +32,14 @@ func (h *Handler) Register(r chi.Router) {
 }
 
 func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
-	topics := parseTopics(r.URL.Query().Get("topics"))
+	query := r.URL.Query()
+	topics := parseTopics(query.Get("topics"))
+	// Filters only apply to the query_log topic
+	filter := eventsservice.QueryLogFilter{
+		Domain:   strings.TrimSpace(query.Get("domain")),
+		Client:   strings.TrimSpace(query.Get("client")),
+		Statuses: parseList(query.Get("status")),
+	}
 
 	// Server Side Events headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -51,7 +59,7 @@ func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
 	_, _ = io.WriteString(w, "retry: 3000\n\n")
 	flusher.Flush()
 
-	events, cancel := h.service.Subscribe(r.Context(), topics)
+	events, cancel := h.service.Subscribe(r.Context(), topics, filter)
 	defer cancel()
 
 	heartbeat := time.NewTicker(time.Duration(h.cfg.HeartbeatSeconds) * time.Second)
@@ -100,6 +108,10 @@ func parseTopics(val string) []string {
 	if strings.TrimSpace(val) == "" {
 		return []string{"health_summary", "node_health"}
 	}
+	return parseList(val)
+}
+
+func parseList(val string) []string {
 	parts := strings.Split(val, ",")
 	out := make([]string, 0, len(parts))
 	for _, p := range parts {
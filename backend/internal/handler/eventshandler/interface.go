@@ -4,8 +4,9 @@ import (
 	"context"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/realtime"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/eventsservice"
 )
 
 type service interface {
-	Subscribe(ctx context.Context, topics []string) (<-chan realtime.Event, func())
+	Subscribe(ctx context.Context, topics []string, filter eventsservice.QueryLogFilter) (<-chan realtime.Event, func())
 }
@@ -0,0 +1,76 @@
+package gravityhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/gravityservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+func (h *Handler) Register(r chi.Router) {
+	r.Get("/", h.getLatest)
+	r.Post("/", h.start)
+}
+
+func (h *Handler) getLatest(w http.ResponseWriter, r *http.Request) {
+	run := h.service.Latest()
+	if run == nil {
+		httpx.WriteJSONError(w, "no gravity update has been run", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(run); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// start kicks off a gravity update and answers 202 straight away. Output follows on the gravity
+// events topic.
+func (h *Handler) start(w http.ResponseWriter, r *http.Request) {
+	var sequential bool
+	if v := r.URL.Query().Get("sequential"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("bad \"sequential\" parameter")
+			httpx.WriteJSONError(w, "bad \"sequential\" parameter", http.StatusBadRequest)
+			return
+		}
+		sequential = parsed
+	}
+
+	run, err := h.service.Start(r.Context(), gravityservice.RunParams{Sequential: sequential})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error starting gravity update")
+		var httpErr *httpx.HttpError
+		if errors.As(err, &httpErr) && errors.Is(httpErr.Kind, httpx.ErrConflict) {
+			httpx.WriteJSONError(w, httpErr.Message, http.StatusConflict)
+			return
+		}
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(run); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
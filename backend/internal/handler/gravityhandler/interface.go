@@ -0,0 +1,12 @@
+package gravityhandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/gravityservice"
+)
+
+type service interface {
+	Start(ctx context.Context, params gravityservice.RunParams) (*gravityservice.Run, error)
+	Latest() *gravityservice.Run
+}
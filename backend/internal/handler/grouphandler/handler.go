@@ -0,0 +1,194 @@
+package grouphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+// Register mounts the group routes. Groups are addressed by name, which is the only thing that
+// identifies a group across nodes.
+func (h *Handler) Register(r chi.Router) {
+	// Read
+	r.Get("/", h.getAll)
+	r.Get("/merged", h.getMerged)
+	// Write
+	r.Post("/", h.addGroup)
+	r.Patch("/{name}", h.updateGroup)
+	r.Delete("/{name}", h.removeGroup)
+}
+
+func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
+	results := h.service.GetAll(r.Context())
+
+	for _, nr := range results {
+		if nr.Error != nil {
+			h.logger.Warn().Err(nr.Error).Msg("partial failure getting groups")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getMerged(w http.ResponseWriter, r *http.Request) {
+	merged := h.service.GetMerged(r.Context())
+
+	for _, node := range merged.UnreachableNodes {
+		h.logger.Warn().Int64("id", node.PiholeNode.Id).Str("error", node.Error).Msg("partial failure getting groups")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(merged); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) addGroup(w http.ResponseWriter, r *http.Request) {
+	var body pihole.AddGroupPayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// --- Normalize Name to []string
+	var names []string
+	switch v := body.Name.(type) {
+	case string:
+		names = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				h.logger.Error().Interface("name", item).Msg("name list must contain only strings")
+				httpx.WriteJSONError(w, "name list must contain only strings", http.StatusBadRequest)
+				return
+			}
+			names = append(names, s)
+		}
+	default:
+		h.logger.Error().Interface("name", v).Msg("name must be string or array of strings")
+		httpx.WriteJSONError(w, "name must be string or array of strings", http.StatusBadRequest)
+		return
+	}
+	body.Name = names
+
+	logger := h.logger.With().Strs("names", names).Logger()
+	logger.Debug().Msg("adding group")
+
+	results, err := h.service.Add(r.Context(), pihole.AddGroupOptions{Payload: body})
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid group")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure adding group")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) updateGroup(w http.ResponseWriter, r *http.Request) {
+	name, ok := h.parseName(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("name", name).Logger()
+
+	var body pihole.UpdateGroupPayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Name == nil && body.Comment == nil && body.Enabled == nil {
+		logger.Error().Msg("empty update")
+		httpx.WriteJSONError(w, "at least one of name, comment or enabled is required", http.StatusBadRequest)
+		return
+	}
+	if body.Name != nil && strings.TrimSpace(*body.Name) == "" {
+		logger.Error().Msg("empty new name")
+		httpx.WriteJSONError(w, "group names cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	logger.Debug().Msg("updating group")
+
+	results := h.service.Update(r.Context(), pihole.UpdateGroupOptions{Name: name, Payload: body})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure updating group")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) removeGroup(w http.ResponseWriter, r *http.Request) {
+	name, ok := h.parseName(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("name", name).Logger()
+	logger.Debug().Msg("removing group")
+
+	results := h.service.Remove(r.Context(), pihole.RemoveGroupOptions{Name: name})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure removing group")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) parseName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := chi.URLParam(r, "name")
+	if strings.TrimSpace(name) == "" {
+		h.logger.Error().Msg("empty \"name\" parameter")
+		httpx.WriteJSONError(w, "empty \"name\" parameter", http.StatusBadRequest)
+		return "", false
+	}
+	return name, true
+}
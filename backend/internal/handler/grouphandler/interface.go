@@ -0,0 +1,17 @@
+package grouphandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/groupservice"
+)
+
+type service interface {
+	GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+	GetMerged(ctx context.Context) *groupservice.MergedGroups
+	Add(ctx context.Context, opts pihole.AddGroupOptions) (map[int64]*domain.NodeResult[pihole.AddGroupResponse], error)
+	Update(ctx context.Context, opts pihole.UpdateGroupOptions) map[int64]*domain.NodeResult[pihole.UpdateGroupResponse]
+	Remove(ctx context.Context, opts pihole.RemoveGroupOptions) map[int64]*domain.NodeResult[pihole.RemoveGroupResponse]
+}
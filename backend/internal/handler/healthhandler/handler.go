@@ -3,8 +3,10 @@ package healthhandler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/service/healthservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog"
 )
@@ -21,6 +23,7 @@ func NewHandler(service service, logger zerolog.Logger) *Handler {
 func (h *Handler) Register(r chi.Router) {
 	r.Get("/summary", h.getSummary)
 	r.Get("/node", h.getNodeHealth)
+	r.Get("/node/{id}/history", h.getNodeHistory)
 }
 
 func (h *Handler) getSummary(w http.ResponseWriter, r *http.Request) {
@@ -42,3 +45,28 @@ func (h *Handler) getNodeHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(nodeHealthSlice)
 }
+
+func (h *Handler) getNodeHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		httpx.WriteJSONError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Int64("id", id).Logger()
+	logger.Debug().Msg("getting node health history")
+
+	history, err := h.service.GetNodeHistory(id)
+	if err != nil {
+		logger.Error().Err(err).Msg("error getting node health history")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -5,4 +5,5 @@ import "github.com/auto-dns/pihole-cluster-admin/internal/service/healthservice"
 type service interface {
 	GetSummary() healthservice.Summary
 	GetNodeHealth() map[int64]healthservice.NodeHealth
+	GetNodeHistory(id int64) (*healthservice.NodeHistory, error)
 }
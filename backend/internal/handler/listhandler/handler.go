@@ -0,0 +1,245 @@
+package listhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+// Register mounts the list routes. List addresses are URLs, so the address of an existing list is
+// passed as the "address" query parameter rather than a path segment.
+func (h *Handler) Register(r chi.Router) {
+	// Read
+	r.Get("/", h.getAll)
+	r.Get("/merged", h.getMerged)
+	// Write
+	r.Post("/type/{type}", h.addList)
+	r.Patch("/type/{type}", h.updateList)
+	r.Post("/type/{type}/enable", h.enableList)
+	r.Post("/type/{type}/disable", h.disableList)
+	r.Delete("/type/{type}", h.removeList)
+}
+
+func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
+	results := h.service.GetAll(r.Context())
+
+	for _, nr := range results {
+		if nr.Error != nil {
+			h.logger.Warn().Err(nr.Error).Msg("partial failure getting lists")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getMerged(w http.ResponseWriter, r *http.Request) {
+	merged := h.service.GetMerged(r.Context())
+
+	for _, node := range merged.UnreachableNodes {
+		h.logger.Warn().Int64("id", node.PiholeNode.Id).Str("error", node.Error).Msg("partial failure getting lists")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(merged); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) addList(w http.ResponseWriter, r *http.Request) {
+	listType, ok := pihole.ParseListType(chi.URLParam(r, "type"))
+	if !ok {
+		h.logger.Error().Msg("bad \"type\" parameter")
+		httpx.WriteJSONError(w, "bad \"type\" parameter", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(listType)).Logger()
+
+	var body pihole.AddListPayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// --- Normalize Address to []string
+	var addresses []string
+	switch v := body.Address.(type) {
+	case string:
+		addresses = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				logger.Error().Interface("address", item).Msg("address list must contain only strings")
+				httpx.WriteJSONError(w, "address list must contain only strings", http.StatusBadRequest)
+				return
+			}
+			addresses = append(addresses, s)
+		}
+	default:
+		logger.Error().Interface("address", v).Msg("address must be string or array of strings")
+		httpx.WriteJSONError(w, "address must be string or array of strings", http.StatusBadRequest)
+		return
+	}
+	body.Address = addresses
+
+	logger.Debug().Strs("addresses", addresses).Msg("adding list")
+
+	results, err := h.service.Add(r.Context(), pihole.AddListOptions{Type: listType, Payload: body})
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid list")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure adding list")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) updateList(w http.ResponseWriter, r *http.Request) {
+	listType, address, ok := h.parseListRef(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(listType)).Str("address", address).Logger()
+
+	var body pihole.UpdateListPayload
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Comment == nil && body.Groups == nil && body.Enabled == nil {
+		logger.Error().Msg("empty update")
+		httpx.WriteJSONError(w, "at least one of comment, groups or enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	logger.Debug().Msg("updating list")
+
+	results := h.service.Update(r.Context(), pihole.UpdateListOptions{
+		Type:    listType,
+		Address: address,
+		Payload: body,
+	})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure updating list")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) enableList(w http.ResponseWriter, r *http.Request) {
+	h.setEnabled(w, r, true)
+}
+
+func (h *Handler) disableList(w http.ResponseWriter, r *http.Request) {
+	h.setEnabled(w, r, false)
+}
+
+func (h *Handler) setEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	listType, address, ok := h.parseListRef(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(listType)).Str("address", address).Bool("enabled", enabled).Logger()
+	logger.Debug().Msg("setting list enabled state")
+
+	results := h.service.SetEnabled(r.Context(), listType, address, enabled)
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure setting list enabled state")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) removeList(w http.ResponseWriter, r *http.Request) {
+	listType, address, ok := h.parseListRef(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.With().Str("type", string(listType)).Str("address", address).Logger()
+	logger.Debug().Msg("removing list")
+
+	results := h.service.Remove(r.Context(), pihole.RemoveListOptions{Type: listType, Address: address})
+	for _, nr := range results {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Msg("partial failure removing list")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// parseListRef reads the type path parameter and address query parameter that identify an
+// existing list, writing a 400 when either is missing or invalid.
+func (h *Handler) parseListRef(w http.ResponseWriter, r *http.Request) (pihole.ListType, string, bool) {
+	listType, ok := pihole.ParseListType(chi.URLParam(r, "type"))
+	if !ok {
+		h.logger.Error().Msg("bad \"type\" parameter")
+		httpx.WriteJSONError(w, "bad \"type\" parameter", http.StatusBadRequest)
+		return "", "", false
+	}
+
+	address := strings.TrimSpace(r.URL.Query().Get("address"))
+	if address == "" {
+		h.logger.Error().Msg("empty \"address\" parameter")
+		httpx.WriteJSONError(w, "empty \"address\" parameter", http.StatusBadRequest)
+		return "", "", false
+	}
+
+	return listType, address, true
+}
@@ -0,0 +1,18 @@
+package listhandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/listservice"
+)
+
+type service interface {
+	GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetListsResponse]
+	GetMerged(ctx context.Context) *listservice.MergedLists
+	Add(ctx context.Context, opts pihole.AddListOptions) (map[int64]*domain.NodeResult[pihole.AddListResponse], error)
+	Update(ctx context.Context, opts pihole.UpdateListOptions) map[int64]*domain.NodeResult[pihole.UpdateListResponse]
+	SetEnabled(ctx context.Context, listType pihole.ListType, address string, enabled bool) map[int64]*domain.NodeResult[pihole.UpdateListResponse]
+	Remove(ctx context.Context, opts pihole.RemoveListOptions) map[int64]*domain.NodeResult[pihole.RemoveListResponse]
+}
@@ -0,0 +1,50 @@
+package metricshandler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	cfg     config.MetricsConfig
+	logger  zerolog.Logger
+}
+
+func NewHandler(cfg config.MetricsConfig, service service, logger zerolog.Logger) *Handler {
+	return &Handler{
+		service: service,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) Register(r chi.Router) {
+	r.Get("/", h.getMetrics)
+}
+
+func (h *Handler) getMetrics(w http.ResponseWriter, r *http.Request) {
+	// The endpoint sits outside session auth, so it is never served without a token
+	expected := []byte("Bearer " + h.cfg.BearerToken)
+	if h.cfg.BearerToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Render into a buffer first so a failure can still produce a proper status code
+	var buf bytes.Buffer
+	if err := h.service.WriteMetrics(&buf); err != nil {
+		h.logger.Error().Err(err).Msg("failed to render metrics")
+		http.Error(w, "failed to render metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,7 @@
+package metricshandler
+
+import "io"
+
+type service interface {
+	WriteMetrics(w io.Writer) error
+}
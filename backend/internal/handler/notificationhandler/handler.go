@@ -0,0 +1,95 @@
+package notificationhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/notificationservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+func (h *Handler) Register(r chi.Router) {
+	// Read
+	r.Get("/deliveries", h.listDeliveries)
+	// Write
+	r.Post("/test", h.sendTest)
+}
+
+func (h *Handler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	var params notificationservice.ListParams
+	query := r.URL.Query()
+
+	if v := query.Get("limit"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		params.Limit = i
+	}
+	if v := query.Get("offset"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			httpx.WriteJSONError(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		params.Offset = i
+	}
+	if v := query.Get("channel"); v != "" {
+		params.Channel = &v
+	}
+	if v := query.Get("event"); v != "" {
+		params.Event = &v
+	}
+	if v := query.Get("success"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid success", http.StatusBadRequest)
+			return
+		}
+		params.Success = &b
+	}
+
+	result, err := h.service.List(params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error getting notification deliveries from database")
+		httpx.WriteJSONError(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Debug().Int("count", len(result.Deliveries)).Int64("total", result.Total).Msg("fetched notification deliveries")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) sendTest(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug().Msg("sending test notification")
+
+	result, err := h.service.SendTest(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error sending test notification")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
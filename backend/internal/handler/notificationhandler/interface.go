@@ -0,0 +1,12 @@
+package notificationhandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/notificationservice"
+)
+
+type service interface {
+	List(params notificationservice.ListParams) (*notificationservice.ListResult, error)
+	SendTest(ctx context.Context) (*notificationservice.TestResult, error)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/querylogservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog"
@@ -24,6 +25,9 @@ func NewHandler(service service, logger zerolog.Logger) *Handler {
 func (h *Handler) Register(r chi.Router) {
 	// Read
 	r.Get("/", h.getQueryLogs)
+	r.Get("/explain", h.explain)
+	// Write
+	r.Post("/unblock", h.unblock)
 }
 
 func (h *Handler) getQueryLogs(w http.ResponseWriter, r *http.Request) {
@@ -142,9 +146,29 @@ func (h *Handler) getQueryLogs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	merged := false
+	if v := r.URL.Query().Get("merged"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid merged", http.StatusBadRequest)
+			return
+		}
+		merged = b
+		ctxLogger.Bool("merged", b)
+	}
+	if merged && body.Start != nil {
+		httpx.WriteJSONError(w, "start is not supported for merged query logs, page with cursor instead", http.StatusBadRequest)
+		return
+	}
+
 	logger := ctxLogger.Logger()
 	logger.Debug().Msg("fetching query logs")
 
+	if merged {
+		h.getMergedQueryLogs(w, r, body, logger)
+		return
+	}
+
 	res, err := h.service.Fetch(r.Context(), body)
 	if err != nil {
 		httpx.WriteJSONError(w, err.Error(), http.StatusBadRequest)
@@ -161,3 +185,96 @@ func (h *Handler) getQueryLogs(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(res)
 }
+
+func (h *Handler) getMergedQueryLogs(w http.ResponseWriter, r *http.Request, body pihole.FetchQueryLogClusterRequest, logger zerolog.Logger) {
+	res, err := h.service.FetchMerged(r.Context(), body)
+	if err != nil {
+		httpx.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, nr := range res.Nodes {
+		if nr.Error != nil {
+			logger.Warn().Err(nr.Error).Int64("id", nr.PiholeNode.Id).Msg("partial failure fetching merged logs")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (h *Handler) explain(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var params querylogservice.ExplainParams
+
+	nodeId, err := strconv.ParseInt(query.Get("node_id"), 10, 64)
+	if err != nil || nodeId <= 0 {
+		httpx.WriteJSONError(w, "invalid node_id", http.StatusBadRequest)
+		return
+	}
+	params.NodeId = nodeId
+
+	params.Status = query.Get("status")
+	if params.Status == "" {
+		httpx.WriteJSONError(w, "status is required", http.StatusBadRequest)
+		return
+	}
+
+	if v := query.Get("list_id"); v != "" {
+		listId, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid list_id", http.StatusBadRequest)
+			return
+		}
+		params.ListId = &listId
+	}
+	params.Domain = query.Get("domain")
+
+	logger := h.logger.With().Int64("node_id", params.NodeId).Str("status", params.Status).Logger()
+	logger.Debug().Msg("explaining query")
+
+	explanation, err := h.service.Explain(r.Context(), params)
+	if err != nil {
+		logger.Error().Err(err).Msg("error explaining query")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(explanation); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) unblock(w http.ResponseWriter, r *http.Request) {
+	var body querylogservice.UnblockParams
+	if err := httpx.DecodeJSONBody(w, r, &body, 1<<20); err != nil {
+		h.logger.Error().Err(err).Msg("invalid JSON body")
+		httpx.WriteJSONError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.NodeId <= 0 {
+		httpx.WriteJSONError(w, "invalid nodeId", http.StatusBadRequest)
+		return
+	}
+
+	logger := h.logger.With().Int64("node_id", body.NodeId).Str("domain", body.Domain).Str("status", body.Status).Logger()
+	logger.Debug().Msg("unblocking query")
+
+	result, err := h.service.Unblock(r.Context(), body)
+	if err != nil {
+		logger.Error().Err(err).Msg("error unblocking query")
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
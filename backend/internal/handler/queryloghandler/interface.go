@@ -4,8 +4,12 @@ import (
 	"context"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/querylogservice"
 )
 
 type service interface {
 	Fetch(ctx context.Context, req pihole.FetchQueryLogClusterRequest) (*pihole.FetchQueryLogsClusterResponse, error)
+	Explain(ctx context.Context, params querylogservice.ExplainParams) (*querylogservice.Explanation, error)
+	Unblock(ctx context.Context, params querylogservice.UnblockParams) (*querylogservice.UnblockResult, error)
+	FetchMerged(ctx context.Context, req pihole.FetchQueryLogClusterRequest) (*pihole.FetchMergedQueryLogsClusterResponse, error)
 }
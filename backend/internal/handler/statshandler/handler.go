@@ -0,0 +1,88 @@
+package statshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/statsservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultCount = 10
+	maxCount     = 100
+)
+
+type Handler struct {
+	service service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service service, logger zerolog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+func (h *Handler) Register(r chi.Router) {
+	r.Get("/", h.getStats)
+}
+
+func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := statsservice.Params{Count: defaultCount}
+	ctxLogger := h.logger.With()
+
+	// --- Parse optional timestamps (RFC3339)
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid 'from' time", http.StatusBadRequest)
+			return
+		}
+		params.From = &t
+		ctxLogger = ctxLogger.Time("from", t)
+	}
+	if v := query.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpx.WriteJSONError(w, "invalid 'until' time", http.StatusBadRequest)
+			return
+		}
+		params.Until = &t
+		ctxLogger = ctxLogger.Time("until", t)
+	}
+	if params.From != nil && params.Until != nil && !params.From.Before(*params.Until) {
+		httpx.WriteJSONError(w, "'from' must be before 'until'", http.StatusBadRequest)
+		return
+	}
+
+	if v := query.Get("count"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i <= 0 || i > maxCount {
+			httpx.WriteJSONError(w, "count must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		params.Count = i
+	}
+	ctxLogger = ctxLogger.Int("count", params.Count)
+
+	logger := ctxLogger.Logger()
+	logger.Debug().Msg("getting cluster stats")
+
+	stats, err := h.service.GetStats(r.Context(), params)
+	if err != nil {
+		logger.Error().Err(err).Msg("error getting cluster stats")
+		httpx.WriteJSONErrorFromErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		logger.Error().Err(err).Msg("failed to encode response")
+		httpx.WriteJSONError(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
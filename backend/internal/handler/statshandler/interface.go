@@ -0,0 +1,11 @@
+package statshandler
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/statsservice"
+)
+
+type service interface {
+	GetStats(ctx context.Context, params statsservice.Params) (*statsservice.Stats, error)
+}
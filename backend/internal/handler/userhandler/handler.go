@@ -78,7 +78,7 @@ func (h *Handler) patch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedUser, err := h.service.Patch(id, body)
+	updatedUser, err := h.service.Patch(r.Context(), id, body)
 
 	safe := func(p *string) string {
 		if p == nil {
@@ -147,7 +147,7 @@ func (h *Handler) updatePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedUser, err := h.service.UpdatePassword(id, body)
+	updatedUser, err := h.service.UpdatePassword(r.Context(), id, body)
 	if err != nil {
 		h.logger.Error().Err(err).Int64("id", updatedUser.Id).Msg("updating password")
 		httpx.WriteJSONErrorFromErr(w, err)
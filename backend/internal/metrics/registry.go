@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, matching the Prometheus client defaults.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families and renders them in the Prometheus text exposition format.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.families {
+		if existing.name() == f.name() {
+			panic(fmt.Sprintf("metric %q registered twice", f.name()))
+		}
+	}
+	r.families = append(r.families, f)
+}
+
+// Write renders every family, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]family, len(r.families))
+	copy(families, r.families)
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Counter vectors
+
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{desc: desc{n: name, help: help, labelNames: labelNames}, values: make(map[string]*counterValue)}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: labelValues}
+		c.values[key] = value
+	}
+	value.value += v
+}
+
+// Delete drops the series for the given label values, e.g. when a node is removed.
+func (c *CounterVec) Delete(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, c.key(labelValues))
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		c.sample(w, "", value.labelValues, nil, value.value)
+	}
+}
+
+// Histogram vectors
+
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{desc: desc{n: name, help: help, labelNames: labelNames}, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, ok := h.values[key]
+	if !ok {
+		value = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = value
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		value.counts[i]++
+	}
+	value.count++
+	value.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		value := h.values[key]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += value.counts[i]
+			h.sample(w, "_bucket", value.labelValues, []string{"le", formatFloat(upper)}, float64(cumulative))
+		}
+		h.sample(w, "_bucket", value.labelValues, []string{"le", "+Inf"}, float64(value.count))
+		h.sample(w, "_sum", value.labelValues, nil, value.sum)
+		h.sample(w, "_count", value.labelValues, nil, float64(value.count))
+	}
+}
+
+// Gauges
+
+// GaugeFunc is evaluated on every scrape. It reports one sample per call to emit.
+type GaugeFunc struct {
+	desc
+	collect func(emit func(value float64, labelValues ...string))
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, labelNames []string, collect func(emit func(value float64, labelValues ...string))) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{n: name, help: help, labelNames: labelNames}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.header(w, "gauge")
+	g.collect(func(value float64, labelValues ...string) {
+		g.sample(w, "", labelValues, nil, value)
+	})
+}
+
+// Shared rendering
+
+type desc struct {
+	n          string
+	help       string
+	labelNames []string
+}
+
+func (d *desc) name() string {
+	return d.n
+}
+
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metric %q expects %d label values, got %d", d.n, len(d.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (d *desc) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.n, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.n, kind)
+}
+
+// sample writes a single line. extra is an optional trailing name/value label pair such as le.
+func (d *desc) sample(w *bufio.Writer, suffix string, labelValues []string, extra []string, value float64) {
+	w.WriteString(d.n)
+	w.WriteString(suffix)
+	if len(labelValues) > 0 || len(extra) > 0 {
+		w.WriteByte('{')
+		for i, v := range labelValues {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, d.labelNames[i], v)
+		}
+		if len(extra) == 2 {
+			if len(labelValues) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extra[0], extra[1])
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(labelEscaper.Replace(value))
+	w.WriteByte('"')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
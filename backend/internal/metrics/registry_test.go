@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return b.String()
+}
+
+func TestRegistryWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+		want  string
+	}{
+		{
+			name: "counter with escaped labels and help",
+			setup: func(r *Registry) {
+				c := r.NewCounterVec("requests_total", "Requests sent\nper \\ node.", "node", "path")
+				c.Inc("b", "/x")
+				c.Add(2.5, "a", "say \"hi\"\nback\\slash")
+			},
+			want: `# HELP requests_total Requests sent\nper \\ node.
+# TYPE requests_total counter
+requests_total{node="a",path="say \"hi\"\nback\\slash"} 2.5
+requests_total{node="b",path="/x"} 1
+`,
+		},
+		{
+			name: "deleted series are left out",
+			setup: func(r *Registry) {
+				c := r.NewCounterVec("errors_total", "Errors.", "node_id")
+				c.Inc("1")
+				c.Inc("2")
+				c.Delete("1")
+			},
+			want: `# HELP errors_total Errors.
+# TYPE errors_total counter
+errors_total{node_id="2"} 1
+`,
+		},
+		{
+			name: "histogram buckets are cumulative and end with +Inf",
+			setup: func(r *Registry) {
+				h := r.NewHistogramVec("duration_seconds", "Durations.", []float64{0.1, 1}, "op")
+				h.Observe(0.05, "read")
+				h.Observe(0.1, "read") // a value on a bound falls in that bucket
+				h.Observe(0.5, "read")
+				h.Observe(3, "read")
+			},
+			want: `# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{op="read",le="0.1"} 2
+duration_seconds_bucket{op="read",le="1"} 3
+duration_seconds_bucket{op="read",le="+Inf"} 4
+duration_seconds_sum{op="read"} 3.65
+duration_seconds_count{op="read"} 4
+`,
+		},
+		{
+			name: "histogram without labels",
+			setup: func(r *Registry) {
+				r.NewHistogramVec("wait_seconds", "Waits.", []float64{1}).Observe(2)
+			},
+			want: `# HELP wait_seconds Waits.
+# TYPE wait_seconds histogram
+wait_seconds_bucket{le="1"} 0
+wait_seconds_bucket{le="+Inf"} 1
+wait_seconds_sum 2
+wait_seconds_count 1
+`,
+		},
+		{
+			name: "gauge funcs and special values",
+			setup: func(r *Registry) {
+				r.NewGaugeFunc("values", "Values.", []string{"kind"}, func(emit func(float64, ...string)) {
+					emit(math.Inf(1), "inf")
+					emit(math.NaN(), "nan")
+					emit(1e-7, "small")
+				})
+			},
+			want: `# HELP values Values.
+# TYPE values gauge
+values{kind="inf"} +Inf
+values{kind="nan"} NaN
+values{kind="small"} 1e-07
+`,
+		},
+		{
+			name: "families are sorted by name",
+			setup: func(r *Registry) {
+				r.NewGaugeFunc("zeta", "Z.", nil, func(emit func(float64, ...string)) { emit(1) })
+				r.NewGaugeFunc("alpha", "A.", nil, func(emit func(float64, ...string)) { emit(0) })
+			},
+			want: `# HELP alpha A.
+# TYPE alpha gauge
+alpha 0
+# HELP zeta Z.
+# TYPE zeta gauge
+zeta 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+			if got := render(t, r); got != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(r *Registry)
+	}{
+		{
+			name: "label count mismatch",
+			f:    func(r *Registry) { r.NewCounterVec("c_total", "C.", "a", "b").Inc("only one") },
+		},
+		{
+			name: "duplicate name",
+			f: func(r *Registry) {
+				r.NewCounterVec("c_total", "C.")
+				r.NewHistogramVec("c_total", "C.", DefaultBuckets)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.f(NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,17 @@
+/* Audit Log */
+
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    action TEXT NOT NULL,
+    route TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',
+    details TEXT,
+    node_outcomes TEXT,
+    success BOOLEAN NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX idx_audit_log_action ON audit_log (action);
+CREATE INDEX idx_audit_log_user_id ON audit_log (user_id);
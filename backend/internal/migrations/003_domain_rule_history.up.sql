@@ -0,0 +1,14 @@
+/* Domain Rule History */
+
+CREATE TABLE domain_rule_change_set (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    action TEXT NOT NULL,
+    reverts_id INTEGER REFERENCES domain_rule_change_set (id) ON DELETE SET NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    reverted_at DATETIME,
+    reverted_by_id INTEGER REFERENCES domain_rule_change_set (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_domain_rule_change_set_created_at ON domain_rule_change_set (created_at);
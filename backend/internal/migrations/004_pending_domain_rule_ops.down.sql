@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS delete_pending_domain_rule_ops_on_pihole_delete;
+DROP TABLE IF EXISTS pending_domain_rule_ops;
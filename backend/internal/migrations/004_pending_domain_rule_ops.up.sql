@@ -0,0 +1,26 @@
+/* Pending Domain Rule Operations */
+
+CREATE TABLE pending_domain_rule_ops (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pihole_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('add', 'remove')),
+    type TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    comment TEXT,
+    groups TEXT,
+    enabled BOOLEAN,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at DATETIME,
+    UNIQUE (pihole_id, type, kind, domain)
+);
+
+CREATE TRIGGER delete_pending_domain_rule_ops_on_pihole_delete
+AFTER DELETE ON piholes
+FOR EACH ROW
+BEGIN
+    DELETE FROM pending_domain_rule_ops
+    WHERE pihole_id = OLD.id;
+END;
@@ -0,0 +1,15 @@
+/* Domain Rule Expirations */
+
+CREATE TABLE domain_rule_expirations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    pihole_node_ids TEXT, -- JSON array of the nodes where the add created the rule, NULL for every node
+    user_id INTEGER,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (type, kind, domain)
+);
+
+CREATE INDEX idx_domain_rule_expirations_expires_at ON domain_rule_expirations (expires_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS delete_query_log_on_pihole_delete;
+DROP TABLE IF EXISTS query_log_collect_positions;
+DROP TABLE IF EXISTS query_log;
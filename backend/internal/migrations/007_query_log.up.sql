@@ -0,0 +1,45 @@
+/* Query Log */
+
+CREATE TABLE query_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pihole_id INTEGER NOT NULL,
+    query_id INTEGER NOT NULL, -- id of the query on the Pi-hole node
+    time REAL NOT NULL,        -- unix timestamp with fractional seconds, as reported by Pi-hole
+    type TEXT,
+    status TEXT,
+    dnssec TEXT,
+    domain TEXT NOT NULL,
+    upstream TEXT,
+    reply_type TEXT,
+    reply_time REAL,
+    client_ip TEXT,
+    client_name TEXT,
+    list_id INTEGER,
+    ede_code INTEGER,
+    ede_text TEXT,
+    cname TEXT,
+    UNIQUE (pihole_id, query_id, time)
+);
+
+CREATE INDEX idx_query_log_time ON query_log (time);
+CREATE INDEX idx_query_log_pihole_time ON query_log (pihole_id, time);
+CREATE INDEX idx_query_log_domain ON query_log (domain);
+
+-- Where the collector stopped reading a node's backlog, so the read survives a restart
+CREATE TABLE query_log_collect_positions (
+    pihole_id INTEGER PRIMARY KEY,
+    from_time INTEGER NOT NULL,  -- unix seconds, the pinned time range
+    until_time INTEGER NOT NULL,
+    cursor INTEGER NOT NULL,     -- Pi-hole cursor pinning the node's view
+    start INTEGER NOT NULL       -- offset of the next page
+);
+
+CREATE TRIGGER delete_query_log_on_pihole_delete
+AFTER DELETE ON piholes
+FOR EACH ROW
+BEGIN
+    DELETE FROM query_log
+    WHERE pihole_id = OLD.id;
+    DELETE FROM query_log_collect_positions
+    WHERE pihole_id = OLD.id;
+END;
@@ -0,0 +1,17 @@
+/* Notification Deliveries */
+
+-- One row per delivery attempt, so retries show up individually
+CREATE TABLE notification_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel TEXT NOT NULL,
+    event TEXT NOT NULL,
+    pihole_id INTEGER,
+    pihole_name TEXT,
+    subject TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_deliveries_created_at ON notification_deliveries (created_at);
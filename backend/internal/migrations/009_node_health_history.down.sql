@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS delete_node_health_history_on_pihole_delete;
+DROP TABLE IF EXISTS node_health_rollups;
+DROP TABLE IF EXISTS node_health_samples;
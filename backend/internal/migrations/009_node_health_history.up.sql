@@ -0,0 +1,37 @@
+/* Node Health History */
+
+-- Every sweep at full resolution, kept for a short time
+CREATE TABLE node_health_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pihole_id INTEGER NOT NULL,
+    time INTEGER NOT NULL, -- unix seconds
+    status TEXT NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    interval_seconds INTEGER NOT NULL -- polling interval the sweep ran at, which uptime is weighted by
+);
+
+CREATE INDEX idx_node_health_samples_pihole_time ON node_health_samples (pihole_id, time);
+CREATE INDEX idx_node_health_samples_time ON node_health_samples (time);
+
+-- Sweeps downsampled to hourly counts per status and latency bucket, kept for longer
+CREATE TABLE node_health_rollups (
+    pihole_id INTEGER NOT NULL,
+    bucket_start INTEGER NOT NULL, -- unix seconds, start of the hour
+    status TEXT NOT NULL,
+    latency_bucket INTEGER NOT NULL, -- index into the latency bucket bounds defined by the health service
+    count INTEGER NOT NULL,
+    seconds INTEGER NOT NULL, -- total polling interval of the counted sweeps
+    PRIMARY KEY (pihole_id, bucket_start, status, latency_bucket)
+);
+
+CREATE INDEX idx_node_health_rollups_bucket_start ON node_health_rollups (bucket_start);
+
+CREATE TRIGGER delete_node_health_history_on_pihole_delete
+AFTER DELETE ON piholes
+FOR EACH ROW
+BEGIN
+    DELETE FROM node_health_samples
+    WHERE pihole_id = OLD.id;
+    DELETE FROM node_health_rollups
+    WHERE pihole_id = OLD.id;
+END;
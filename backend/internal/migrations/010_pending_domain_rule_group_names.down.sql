@@ -0,0 +1 @@
+ALTER TABLE pending_domain_rule_ops DROP COLUMN group_names;
@@ -0,0 +1,3 @@
+/* Group names are resolved to each node's own group ids when a queued operation is replayed */
+
+ALTER TABLE pending_domain_rule_ops ADD COLUMN group_names TEXT;
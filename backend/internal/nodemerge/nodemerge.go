@@ -0,0 +1,124 @@
+// Package nodemerge lines up what each node reports about the same items, for the cluster-wide views
+// that show where nodes differ.
+package nodemerge
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// Split separates the nodes that answered from the ones that did not, both sorted by id. Unreachable
+// nodes are reported on their own so they never count as missing an item.
+func Split[T any](results map[int64]*domain.NodeResult[T]) ([]domain.PiholeNodeRef, []domain.UnreachableNode, map[int64]*T) {
+	nodes := []domain.PiholeNodeRef{}
+	unreachable := []domain.UnreachableNode{}
+	responses := make(map[int64]*T, len(results))
+
+	for id, nr := range results {
+		if nr == nil {
+			continue
+		}
+		if !nr.Success || nr.Response == nil {
+			unreachable = append(unreachable, domain.UnreachableNode{PiholeNode: nr.PiholeNode, Error: nr.ErrorString})
+			continue
+		}
+		nodes = append(nodes, nr.PiholeNode)
+		responses[id] = nr.Response
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	sort.Slice(unreachable, func(i, j int) bool { return unreachable[i].PiholeNode.Id < unreachable[j].PiholeNode.Id })
+
+	return nodes, unreachable, responses
+}
+
+// Fields are the attributes of one node's copy of an item that are compared with the other nodes.
+// Items without an enabled flag or groups leave those zero, so they never conflict.
+type Fields struct {
+	Enabled bool
+	Comment *string
+	Groups  []string // names, since group ids differ between nodes
+}
+
+// Conflicts names the fields that differ between the nodes' copies of an item, out of "enabled",
+// "comment" and "groups".
+func Conflicts[S any](states []S, fields func(S) Fields) []string {
+	conflicts := []string{}
+	if len(states) < 2 {
+		return conflicts
+	}
+
+	first := fields(states[0])
+	var enabled, comment, groups bool
+	for _, state := range states[1:] {
+		f := fields(state)
+		if f.Enabled != first.Enabled {
+			enabled = true
+		}
+		if CommentValue(f.Comment) != CommentValue(first.Comment) {
+			comment = true
+		}
+		if !slices.Equal(f.Groups, first.Groups) {
+			groups = true
+		}
+	}
+
+	if enabled {
+		conflicts = append(conflicts, "enabled")
+	}
+	if comment {
+		conflicts = append(conflicts, "comment")
+	}
+	if groups {
+		conflicts = append(conflicts, "groups")
+	}
+	return conflicts
+}
+
+// GroupNames maps each node's group ids to names. Nodes whose groups could not be fetched are left
+// out.
+func GroupNames(results map[int64]*domain.NodeResult[pihole.GetGroupsResponse]) map[int64]map[int]string {
+	names := make(map[int64]map[int]string, len(results))
+	for id, nr := range results {
+		if nr == nil || nr.Response == nil {
+			continue
+		}
+		nodeNames := make(map[int]string, len(nr.Response.Groups))
+		for _, group := range nr.Response.Groups {
+			nodeNames[group.Id] = group.Name
+		}
+		names[id] = nodeNames
+	}
+	return names
+}
+
+// NamesFor translates a node's group ids to sorted names. An id the node's groups did not include,
+// e.g. because they could not be fetched, is shown as "#<id>" so it still counts when comparing nodes.
+func NamesFor(ids []int, names map[int]string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			name = fmt.Sprintf("#%d", id)
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func CommentValue(c *string) string {
+	if c == nil {
+		return ""
+	}
+	return *c
+}
+
+func SortedGroups(groups []int) []int {
+	out := append([]int{}, groups...)
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,85 @@
+package nodemerge
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+)
+
+type testResponse struct{ Value string }
+
+func TestSplit(t *testing.T) {
+	results := map[int64]*domain.NodeResult[testResponse]{
+		3: {PiholeNode: domain.PiholeNodeRef{Id: 3}, Success: true, Response: &testResponse{Value: "c"}},
+		1: {PiholeNode: domain.PiholeNodeRef{Id: 1}, Success: true, Response: &testResponse{Value: "a"}},
+		4: {PiholeNode: domain.PiholeNodeRef{Id: 4}, Error: errors.New("timeout"), ErrorString: "timeout"},
+		2: {PiholeNode: domain.PiholeNodeRef{Id: 2}, Success: true},
+		5: nil,
+	}
+
+	nodes, unreachable, responses := Split(results)
+
+	var nodeIds []int64
+	for _, node := range nodes {
+		nodeIds = append(nodeIds, node.Id)
+	}
+	if !slices.Equal(nodeIds, []int64{1, 3}) {
+		t.Errorf("nodes = %v, want [1 3]", nodeIds)
+	}
+
+	var unreachableIds []int64
+	for _, node := range unreachable {
+		unreachableIds = append(unreachableIds, node.PiholeNode.Id)
+	}
+	if !slices.Equal(unreachableIds, []int64{2, 4}) {
+		t.Errorf("unreachable = %v, want [2 4]", unreachableIds)
+	}
+	if unreachable[1].Error != "timeout" {
+		t.Errorf("unreachable error = %q, want %q", unreachable[1].Error, "timeout")
+	}
+
+	if len(responses) != 2 || responses[1].Value != "a" || responses[3].Value != "c" {
+		t.Errorf("responses = %v, want nodes 1 and 3", responses)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	comment := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		states []Fields
+		want   []string
+	}{
+		{name: "single node", states: []Fields{{Enabled: true}}, want: []string{}},
+		{name: "in sync", states: []Fields{{Enabled: true, Groups: []string{"a"}}, {Enabled: true, Groups: []string{"a"}}}, want: []string{}},
+		{name: "nil and empty comment match", states: []Fields{{Comment: nil}, {Comment: comment("")}}, want: []string{}},
+		{name: "enabled differs", states: []Fields{{Enabled: true}, {Enabled: false}}, want: []string{"enabled"}},
+		{name: "comment differs on a later node", states: []Fields{{}, {}, {Comment: comment("x")}}, want: []string{"comment"}},
+		{name: "groups differ", states: []Fields{{Groups: []string{"a"}}, {Groups: []string{"a", "b"}}}, want: []string{"groups"}},
+		{
+			name:   "everything differs",
+			states: []Fields{{Enabled: true, Comment: comment("x"), Groups: []string{"a"}}, {Comment: comment("y")}},
+			want:   []string{"enabled", "comment", "groups"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Conflicts(tt.states, func(f Fields) Fields { return f })
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Conflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamesFor(t *testing.T) {
+	got := NamesFor([]int{7, 0, 9}, map[int]string{0: "Default", 7: "kids"})
+	want := []string{"#9", "Default", "kids"}
+	if !slices.Equal(got, want) {
+		t.Errorf("NamesFor() = %v, want %v", got, want)
+	}
+}
@@ -94,6 +94,7 @@ type Client struct {
 	mu      sync.Mutex
 	logger  zerolog.Logger
 	cfgMu   sync.RWMutex
+	metrics metricsPort // set by the cluster the client joins; nil for standalone clients
 }
 
 type ClientConfig struct {
@@ -191,7 +192,40 @@ func (c *Client) ensureSession(ctx context.Context) (string, error) {
 	return sid, nil
 }
 
+func (c *Client) setMetrics(m metricsPort) {
+	c.cfgMu.Lock()
+	defer c.cfgMu.Unlock()
+	c.metrics = m
+}
+
+// doRequest sends the request and records its outcome. Transport errors and non-2xx responses both
+// count as failures.
 func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	return c.doRequestWith(c.HTTP, req)
+}
+
+// doStreamingRequest is doRequest without the HTTP client's overall timeout, for responses that take
+// longer than that to read. The request's context bounds it instead.
+func (c *Client) doStreamingRequest(req *http.Request) (*http.Response, error) {
+	hc := *c.HTTP
+	hc.Timeout = 0
+	return c.doRequestWith(&hc, req)
+}
+
+func (c *Client) doRequestWith(hc *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := c.sendRequest(hc, req)
+
+	c.cfgMu.RLock()
+	m, id := c.metrics, c.cfg.Id
+	c.cfgMu.RUnlock()
+	if m != nil {
+		m.ObserveNodeRequest(id, err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300)
+	}
+
+	return resp, err
+}
+
+func (c *Client) sendRequest(hc *http.Client, req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 	if ctx == nil {
 		ctx = context.TODO()
@@ -218,7 +252,7 @@ func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
 	req.Header.Set("X-Request-ID", childId)
 	req.Header.Set("User-Agent", "pihole-cluster-admin/6")
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := hc.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +274,7 @@ func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
 		req.Header.Set("X-FTL-SID", sid)
 		req.Header.Set("X-Request-ID", childId)
 		req.Header.Set("User-Agent", "pihole-cluster-admin/6")
-		resp, err = c.HTTP.Do(req)
+		resp, err = hc.Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -317,8 +351,8 @@ func (c *Client) GetAllDomainRules(ctx context.Context) (*GetDomainRulesResponse
 	return &result, nil
 }
 
-func (c *Client) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesByTypeOptions) (*GetDomainRulesResponse, error) {
-	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Type)))
+func (c *Client) GetLists(ctx context.Context) (*GetListsResponse, error) {
+	url := c.getBaseURL() + "/lists"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -326,7 +360,7 @@ func (c *Client) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesBy
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+		return nil, fmt.Errorf("requesting Pi-hole lists: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -334,7 +368,7 @@ func (c *Client) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesBy
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result GetDomainRulesResponse
+	var result GetListsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
@@ -343,8 +377,8 @@ func (c *Client) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesBy
 	return &result, nil
 }
 
-func (c *Client) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesByKindOptions) (*GetDomainRulesResponse, error) {
-	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Kind)))
+func (c *Client) GetList(ctx context.Context, opts GetListOptions) (*GetListsResponse, error) {
+	url := fmt.Sprintf("%s/lists/%s?type=%s", c.getBaseURL(), url.PathEscape(opts.Address), opts.Type)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -352,7 +386,7 @@ func (c *Client) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesBy
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+		return nil, fmt.Errorf("requesting Pi-hole list: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -360,7 +394,7 @@ func (c *Client) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesBy
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result GetDomainRulesResponse
+	var result GetListsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
@@ -369,8 +403,135 @@ func (c *Client) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesBy
 	return &result, nil
 }
 
-func (c *Client) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRulesByDomainOptions) (*GetDomainRulesResponse, error) {
-	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Domain)))
+func (c *Client) AddList(ctx context.Context, opts AddListOptions) (*AddListResponse, error) {
+	c.logger.Debug().Str("type", string(opts.Type)).Msg("adding list")
+
+	url := fmt.Sprintf("%s/lists?type=%s", c.getBaseURL(), opts.Type)
+
+	bodyBytes, err := json.Marshal(opts.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("adding list to pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result AddListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateList edits a list in place, keeping its id and gravity data. Pi-hole replaces every field
+// on PUT, so any field left nil in the payload is filled in from the list's current state.
+func (c *Client) UpdateList(ctx context.Context, opts UpdateListOptions) (*UpdateListResponse, error) {
+	c.logger.Debug().Str("type", string(opts.Type)).Str("address", opts.Address).Msg("updating list")
+
+	current, err := c.GetList(ctx, GetListOptions{Type: opts.Type, Address: opts.Address})
+	if err != nil {
+		return nil, fmt.Errorf("getting current list: %w", err)
+	}
+	if len(current.Lists) == 0 {
+		return nil, fmt.Errorf("%s list %s not found", opts.Type, opts.Address)
+	}
+
+	list := current.Lists[0]
+	payload := updateListRequest{
+		Type:    opts.Type,
+		Comment: list.Comment,
+		Groups:  list.Groups,
+		Enabled: list.Enabled,
+	}
+	if opts.Payload.Comment != nil {
+		payload.Comment = opts.Payload.Comment
+	}
+	if opts.Payload.Groups != nil {
+		payload.Groups = opts.Payload.Groups
+	}
+	if opts.Payload.Enabled != nil {
+		payload.Enabled = *opts.Payload.Enabled
+	}
+
+	url := fmt.Sprintf("%s/lists/%s?type=%s", c.getBaseURL(), url.PathEscape(opts.Address), opts.Type)
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("updating list on pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result UpdateListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) RemoveList(ctx context.Context, opts RemoveListOptions) error {
+	c.logger.Debug().Str("type", string(opts.Type)).Str("address", opts.Address).Msg("removing list")
+
+	url := fmt.Sprintf("%s/lists/%s?type=%s", c.getBaseURL(), url.PathEscape(opts.Address), opts.Type)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("removing list from pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *Client) GetGroups(ctx context.Context) (*GetGroupsResponse, error) {
+	url := c.getBaseURL() + "/groups"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -378,7 +539,7 @@ func (c *Client) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRules
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+		return nil, fmt.Errorf("requesting Pi-hole groups: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -386,7 +547,7 @@ func (c *Client) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRules
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result GetDomainRulesResponse
+	var result GetGroupsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
@@ -395,8 +556,8 @@ func (c *Client) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRules
 	return &result, nil
 }
 
-func (c *Client) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRulesByTypeKindOptions) (*GetDomainRulesResponse, error) {
-	url := c.getBaseURL() + fmt.Sprintf("/domains/%s/%s", url.PathEscape(string(opts.Type)), url.PathEscape(string(opts.Kind)))
+func (c *Client) GetGroup(ctx context.Context, opts GetGroupOptions) (*GetGroupsResponse, error) {
+	url := fmt.Sprintf("%s/groups/%s", c.getBaseURL(), url.PathEscape(opts.Name))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -404,7 +565,7 @@ func (c *Client) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRul
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+		return nil, fmt.Errorf("requesting Pi-hole group: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -412,7 +573,7 @@ func (c *Client) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRul
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result GetDomainRulesResponse
+	var result GetGroupsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
@@ -421,8 +582,135 @@ func (c *Client) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRul
 	return &result, nil
 }
 
-func (c *Client) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDomainRulesByTypeKindDomainOptions) (*GetDomainRulesResponse, error) {
-	url := c.getBaseURL() + fmt.Sprintf("/domains/%s/%s/%s", url.PathEscape(string(opts.Type)), url.PathEscape(string(opts.Kind)), url.PathEscape(opts.Domain))
+func (c *Client) AddGroup(ctx context.Context, opts AddGroupOptions) (*AddGroupResponse, error) {
+	c.logger.Debug().Msg("adding group")
+
+	url := c.getBaseURL() + "/groups"
+
+	bodyBytes, err := json.Marshal(opts.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("adding group to pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result AddGroupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateGroup edits or renames a group in place, keeping its id so existing assignments survive.
+// Pi-hole replaces every field on PUT, so any field left nil in the payload is filled in from the
+// group's current state.
+func (c *Client) UpdateGroup(ctx context.Context, opts UpdateGroupOptions) (*UpdateGroupResponse, error) {
+	c.logger.Debug().Str("name", opts.Name).Msg("updating group")
+
+	current, err := c.GetGroup(ctx, GetGroupOptions{Name: opts.Name})
+	if err != nil {
+		return nil, fmt.Errorf("getting current group: %w", err)
+	}
+	if len(current.Groups) == 0 {
+		return nil, fmt.Errorf("group %s not found", opts.Name)
+	}
+
+	group := current.Groups[0]
+	payload := updateGroupRequest{
+		Name:    group.Name,
+		Comment: group.Comment,
+		Enabled: group.Enabled,
+	}
+	if opts.Payload.Name != nil {
+		payload.Name = *opts.Payload.Name
+	}
+	if opts.Payload.Comment != nil {
+		payload.Comment = opts.Payload.Comment
+	}
+	if opts.Payload.Enabled != nil {
+		payload.Enabled = *opts.Payload.Enabled
+	}
+
+	url := fmt.Sprintf("%s/groups/%s", c.getBaseURL(), url.PathEscape(opts.Name))
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("updating group on pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result UpdateGroupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) RemoveGroup(ctx context.Context, opts RemoveGroupOptions) error {
+	c.logger.Debug().Str("name", opts.Name).Msg("removing group")
+
+	url := fmt.Sprintf("%s/groups/%s", c.getBaseURL(), url.PathEscape(opts.Name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("removing group from pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *Client) GetDevices(ctx context.Context) (*GetDevicesResponse, error) {
+	url := c.getBaseURL() + "/clients"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -430,7 +718,7 @@ func (c *Client) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDom
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+		return nil, fmt.Errorf("requesting Pi-hole clients: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -438,7 +726,7 @@ func (c *Client) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDom
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result GetDomainRulesResponse
+	var result GetDevicesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
@@ -447,12 +735,47 @@ func (c *Client) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDom
 	return &result, nil
 }
 
-func (c *Client) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (*AddDomainRuleResponse, error) {
-	c.logger.Debug().Str("type", string(opts.Type)).Str("kind", string(opts.Kind)).Msg("adding domain rule")
+func (c *Client) GetDevice(ctx context.Context, opts GetDeviceOptions) (*GetDevicesResponse, error) {
+	url := fmt.Sprintf("%s/clients/%s", c.getBaseURL(), url.PathEscape(opts.Client))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
 
-	url := fmt.Sprintf("%s/domains/%s/%s", c.getBaseURL(), opts.Type, opts.Kind)
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole client: %w", err)
+	}
+	defer resp.Body.Close()
 
-	bodyBytes, err := json.Marshal(opts.Payload)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDevicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) AddDevice(ctx context.Context, opts AddDeviceOptions) (*AddDeviceResponse, error) {
+	c.logger.Debug().Msg("adding device")
+
+	payload := opts.Payload
+	if payload.GroupNames != nil {
+		groups, err := c.resolveGroupNames(ctx, payload.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		payload.Groups, payload.GroupNames = groups, nil
+	}
+
+	url := c.getBaseURL() + "/clients"
+
+	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request body: %w", err)
 	}
@@ -469,7 +792,7 @@ func (c *Client) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("adding domain to pihole: %w", err)
+		return nil, fmt.Errorf("adding device to pihole: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -477,7 +800,608 @@ func (c *Client) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var result AddDomainRuleResponse
+	var result AddDeviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateDevice edits a client in place, keeping its id. Pi-hole replaces every field on PUT, so any
+// field left nil in the payload is filled in from the client's current state.
+func (c *Client) UpdateDevice(ctx context.Context, opts UpdateDeviceOptions) (*UpdateDeviceResponse, error) {
+	c.logger.Debug().Str("client", opts.Client).Msg("updating device")
+
+	current, err := c.GetDevice(ctx, GetDeviceOptions{Client: opts.Client})
+	if err != nil {
+		return nil, fmt.Errorf("getting current device: %w", err)
+	}
+	if len(current.Devices) == 0 {
+		return nil, fmt.Errorf("device %s not found", opts.Client)
+	}
+
+	device := current.Devices[0]
+	payload := updateDeviceRequest{
+		Comment: device.Comment,
+		Groups:  device.Groups,
+	}
+	if opts.Payload.Comment != nil {
+		payload.Comment = opts.Payload.Comment
+	}
+	if opts.Payload.Groups != nil {
+		payload.Groups = opts.Payload.Groups
+	}
+	if opts.Payload.GroupNames != nil {
+		groups, err := c.resolveGroupNames(ctx, opts.Payload.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		payload.Groups = groups
+	}
+
+	url := fmt.Sprintf("%s/clients/%s", c.getBaseURL(), url.PathEscape(opts.Client))
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("updating device on pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result UpdateDeviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) RemoveDevice(ctx context.Context, opts RemoveDeviceOptions) error {
+	c.logger.Debug().Str("client", opts.Client).Msg("removing device")
+
+	url := fmt.Sprintf("%s/clients/%s", c.getBaseURL(), url.PathEscape(opts.Client))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("removing device from pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// resolveGroupNames maps group names to this node's group ids, which differ from node to node.
+func (c *Client) resolveGroupNames(ctx context.Context, names []string) ([]int, error) {
+	groups, err := c.GetGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving group names: %w", err)
+	}
+
+	byName := make(map[string]int, len(groups.Groups))
+	for _, group := range groups.Groups {
+		byName[group.Name] = group.Id
+	}
+
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("group %q does not exist on this node", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// statsURL picks between the in-memory /stats endpoints and their /stats/database counterparts,
+// which are the only ones that accept a time range.
+func (c *Client) statsURL(name string, window StatsWindow, params url.Values) string {
+	path := "/stats/" + name
+	if window.isSet() {
+		path = "/stats/database/" + name
+		from, until := int64(0), time.Now().Unix()
+		if window.From != nil {
+			from = *window.From
+		}
+		if window.Until != nil {
+			until = *window.Until
+		}
+		params.Set("from", strconv.FormatInt(from, 10))
+		params.Set("until", strconv.FormatInt(until, 10))
+	}
+	if len(params) == 0 {
+		return c.getBaseURL() + path
+	}
+	return c.getBaseURL() + path + "?" + params.Encode()
+}
+
+func (c *Client) getStats(ctx context.Context, url string, what string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("requesting Pi-hole %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) GetStatsSummary(ctx context.Context, opts GetStatsSummaryOptions) (*StatsSummaryResponse, error) {
+	url := c.statsURL("summary", opts.Window, url.Values{})
+	if !opts.Window.isSet() {
+		var result StatsSummaryResponse
+		if err := c.getStats(ctx, url, "stats summary", &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	var result databaseSummaryResponse
+	if err := c.getStats(ctx, url, "stats summary", &result); err != nil {
+		return nil, err
+	}
+	return &StatsSummaryResponse{
+		Queries: StatsQueries{
+			Total:          result.SumQueries,
+			Blocked:        result.SumBlocked,
+			PercentBlocked: result.PercentBlocked,
+		},
+		Clients: StatsClients{Total: result.TotalClients},
+		Took:    result.Took,
+	}, nil
+}
+
+func (c *Client) GetTopDomains(ctx context.Context, opts GetTopDomainsOptions) (*TopDomainsResponse, error) {
+	params := url.Values{}
+	params.Set("blocked", strconv.FormatBool(opts.Blocked))
+	if opts.Count > 0 {
+		params.Set("count", strconv.Itoa(opts.Count))
+	}
+
+	var result TopDomainsResponse
+	if err := c.getStats(ctx, c.statsURL("top_domains", opts.Window, params), "top domains", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetTopClients(ctx context.Context, opts GetTopClientsOptions) (*TopClientsResponse, error) {
+	params := url.Values{}
+	params.Set("blocked", strconv.FormatBool(opts.Blocked))
+	if opts.Count > 0 {
+		params.Set("count", strconv.Itoa(opts.Count))
+	}
+
+	var result TopClientsResponse
+	if err := c.getStats(ctx, c.statsURL("top_clients", opts.Window, params), "top clients", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetUpstreams(ctx context.Context, opts GetUpstreamsOptions) (*UpstreamsResponse, error) {
+	var result UpstreamsResponse
+	if err := c.getStats(ctx, c.statsURL("upstreams", opts.Window, url.Values{}), "upstreams", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetBlocking(ctx context.Context) (*BlockingResponse, error) {
+	url := c.getBaseURL() + "/dns/blocking"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole blocking status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result BlockingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) SetBlocking(ctx context.Context, opts SetBlockingOptions) (*BlockingResponse, error) {
+	c.logger.Debug().Bool("blocking", opts.Blocking).Msg("setting blocking status")
+
+	url := c.getBaseURL() + "/dns/blocking"
+
+	bodyBytes, err := json.Marshal(setBlockingRequest{Blocking: opts.Blocking, Timer: opts.Timer})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("setting Pi-hole blocking status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result BlockingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetVersion(ctx context.Context) (*VersionResponse, error) {
+	url := c.getBaseURL() + "/info/version"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result VersionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateGravity runs gravity on the node, calling onLine with each line of output as it arrives.
+func (c *Client) UpdateGravity(ctx context.Context, onLine func(line string)) (*UpdateGravityResponse, error) {
+	c.logger.Debug().Msg("updating gravity")
+
+	start := time.Now()
+	url := c.getBaseURL() + "/action/gravity"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doStreamingRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole gravity update: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result UpdateGravityResponse
+	err = readGravityOutput(resp.Body, func(line string) {
+		result.Lines++
+		onLine(line)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading gravity output: %w", err)
+	}
+	result.Took = time.Since(start).Seconds()
+
+	return &result, nil
+}
+
+func (c *Client) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesByTypeOptions) (*GetDomainRulesResponse, error) {
+	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Type)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDomainRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesByKindOptions) (*GetDomainRulesResponse, error) {
+	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Kind)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDomainRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRulesByDomainOptions) (*GetDomainRulesResponse, error) {
+	url := c.getBaseURL() + fmt.Sprintf("/domains/%s", url.PathEscape(string(opts.Domain)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDomainRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRulesByTypeKindOptions) (*GetDomainRulesResponse, error) {
+	url := c.getBaseURL() + fmt.Sprintf("/domains/%s/%s", url.PathEscape(string(opts.Type)), url.PathEscape(string(opts.Kind)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDomainRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDomainRulesByTypeKindDomainOptions) (*GetDomainRulesResponse, error) {
+	url := c.getBaseURL() + fmt.Sprintf("/domains/%s/%s/%s", url.PathEscape(string(opts.Type)), url.PathEscape(string(opts.Kind)), url.PathEscape(opts.Domain))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting Pi-hole domain rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result GetDomainRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (*AddDomainRuleResponse, error) {
+	c.logger.Debug().Str("type", string(opts.Type)).Str("kind", string(opts.Kind)).Msg("adding domain rule")
+
+	payload := opts.Payload
+	if payload.GroupNames != nil {
+		groups, err := c.resolveGroupNames(ctx, payload.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		payload.Groups, payload.GroupNames = groups, nil
+	}
+
+	url := fmt.Sprintf("%s/domains/%s/%s", c.getBaseURL(), opts.Type, opts.Kind)
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("adding domain to pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result AddDomainRuleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateDomainRule edits a rule in place, keeping its id and date_added. Pi-hole replaces every
+// field on PUT, so any field left nil in the payload is filled in from the rule's current state.
+func (c *Client) UpdateDomainRule(ctx context.Context, opts UpdateDomainRuleOptions) (*UpdateDomainRuleResponse, error) {
+	c.logger.Debug().Str("type", string(opts.Type)).Str("kind", string(opts.Kind)).Msg("updating domain rule")
+
+	current, err := c.GetDomainRulesByTypeKindDomain(ctx, GetDomainRulesByTypeKindDomainOptions{
+		Type:   opts.Type,
+		Kind:   opts.Kind,
+		Domain: opts.Domain,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting current domain rule: %w", err)
+	}
+	if len(current.Domains) == 0 {
+		return nil, fmt.Errorf("domain rule %s/%s/%s not found", opts.Type, opts.Kind, opts.Domain)
+	}
+
+	rule := current.Domains[0]
+	payload := updateDomainRequest{
+		Type:    opts.Type,
+		Kind:    opts.Kind,
+		Comment: rule.Comment,
+		Groups:  rule.Groups,
+		Enabled: rule.Enabled,
+	}
+	if opts.Payload.Comment != nil {
+		payload.Comment = opts.Payload.Comment
+	}
+	if opts.Payload.Groups != nil {
+		payload.Groups = opts.Payload.Groups
+	}
+	if opts.Payload.GroupNames != nil {
+		groups, err := c.resolveGroupNames(ctx, opts.Payload.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		payload.Groups = groups
+	}
+	if opts.Payload.Enabled != nil {
+		payload.Enabled = *opts.Payload.Enabled
+	}
+
+	url := fmt.Sprintf("%s/domains/%s/%s/%s", c.getBaseURL(), opts.Type, opts.Kind, url.PathEscape(opts.Domain))
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("updating domain on pihole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result UpdateDomainRuleResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode Pi-hole response")
 		return nil, fmt.Errorf("decoding response: %w", err)
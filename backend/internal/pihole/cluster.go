@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,21 +14,26 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+var errClientNotFound = errors.New("client id not found")
+
 type Cluster struct {
 	clients       map[int64]clientPort
 	cursorManager cursorManagerPort[FetchQueryLogFilters]
+	metrics       metricsPort
 	logger        zerolog.Logger
 	rw            sync.RWMutex
 }
 
-func NewCluster(clientMap map[int64]*Client, cursorManager cursorManagerPort[FetchQueryLogFilters], logger zerolog.Logger) *Cluster {
+func NewCluster(clientMap map[int64]*Client, cursorManager cursorManagerPort[FetchQueryLogFilters], metrics metricsPort, logger zerolog.Logger) *Cluster {
 	clients := make(map[int64]clientPort, len(clientMap))
 	for id, c := range clientMap {
+		c.setMetrics(metrics)
 		clients[id] = c
 	}
 	return &Cluster{
 		clients:       clients,
 		cursorManager: cursorManager,
+		metrics:       metrics,
 		logger:        logger,
 	}
 }
@@ -45,6 +51,7 @@ func (c *Cluster) AddClient(ctx context.Context, client *Client) error {
 		return err
 	}
 
+	client.setMetrics(c.metrics)
 	c.clients[id] = client
 	logger.Debug().Msg("client added to cluster")
 
@@ -65,6 +72,7 @@ func (c *Cluster) RemoveClient(ctx context.Context, id int64) error {
 	}
 
 	delete(c.clients, id)
+	c.metrics.RemoveNode(id)
 	c.logger.Debug().Int64("id", client.GetId(ctx)).Str("name", client.GetName(ctx)).Str("scheme", client.GetScheme(ctx)).Str("host", client.GetHost(ctx)).Int("port", client.GetPort(ctx)).Msg("client removed from cluster")
 
 	return nil
@@ -100,14 +108,60 @@ func (c *Cluster) HasClient(ctx context.Context, id int64) bool {
 	return has
 }
 
-func (c *Cluster) forEachClient(ctx context.Context, limit int, f func(ctx context.Context, id int64, client clientPort) error) error {
+// GetNodes returns every node in the cluster, sorted by id.
+func (c *Cluster) GetNodes(ctx context.Context) []domain.PiholeNodeRef {
+	c.rw.RLock()
+	nodes := make([]domain.PiholeNodeRef, 0, len(c.clients))
+	for _, client := range c.clients {
+		nodes = append(nodes, client.GetNodeInfo(ctx))
+	}
+	c.rw.RUnlock()
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	return nodes
+}
+
+const (
+	defaultNodeTimeout = 3 * time.Second
+	// gravityNodeTimeout bounds a single node's gravity run, which downloads every list and rebuilds
+	// the gravity database
+	gravityNodeTimeout = 30 * time.Minute
+	// importNodeTimeout bounds a single node's share of an import batch, which inserts many domains
+	// in one request
+	importNodeTimeout = 2 * time.Minute
+)
+
+// forEachClient runs f against every node concurrently, at most limit at a time when limit is positive.
+// op names the operation in the fan-out duration metric.
+func (c *Cluster) forEachClient(ctx context.Context, op string, limit int, f func(ctx context.Context, id int64, client clientPort) error) error {
+	return c.forEachClientIn(ctx, op, nil, limit, f)
+}
+
+// forEachClientIn behaves like forEachClient, but only visits the given node ids. A nil slice visits every node.
+func (c *Cluster) forEachClientIn(ctx context.Context, op string, ids []int64, limit int, f func(ctx context.Context, id int64, client clientPort) error) error {
+	return c.forEachClientInWithTimeout(ctx, op, ids, limit, defaultNodeTimeout, f)
+}
+
+// forEachClientInWithTimeout behaves like forEachClientIn, with a different cap on each node's time.
+func (c *Cluster) forEachClientInWithTimeout(ctx context.Context, op string, ids []int64, limit int, maxNodeTimeout time.Duration, f func(ctx context.Context, id int64, client clientPort) error) error {
 	c.rw.RLock()
 	clients := make(map[int64]clientPort, len(c.clients))
-	for id, client := range c.clients {
-		clients[id] = client
+	if ids == nil {
+		for id, client := range c.clients {
+			clients[id] = client
+		}
+	} else {
+		for _, id := range ids {
+			if client, ok := c.clients[id]; ok {
+				clients[id] = client
+			}
+		}
 	}
 	c.rw.RUnlock()
 
+	start := time.Now()
+	defer func() { c.metrics.ObserveFanOut(op, time.Since(start)) }()
+
 	var semaphore chan struct{}
 	if limit > 0 {
 		semaphore = make(chan struct{}, limit)
@@ -132,11 +186,11 @@ func (c *Cluster) forEachClient(ctx context.Context, limit int, f func(ctx conte
 				defer func() { <-semaphore }()
 			}
 
-			nodeTimeout := 3 * time.Second
+			nodeTimeout := maxNodeTimeout
 			if deadline, ok := ctx.Deadline(); ok {
 				nodeTimeout = time.Until(deadline) / 2
-				if nodeTimeout > 3*time.Second {
-					nodeTimeout = 3 * time.Second
+				if nodeTimeout > maxNodeTimeout {
+					nodeTimeout = maxNodeTimeout
 				}
 			}
 			var cancel context.CancelFunc
@@ -160,13 +214,16 @@ func (c *Cluster) FetchQueryLogs(ctx context.Context, req FetchQueryLogClusterRe
 		if !ok {
 			return nil, fmt.Errorf("cursor expired or not found")
 		}
+		if searchState.IsMerged() {
+			return nil, fmt.Errorf("cursor belongs to a merged query log")
+		}
 	}
 
 	results := make(map[int64]*domain.NodeResult[FetchQueryLogResponse], len(c.clients))
 	nextPiholeCursors := make(map[int64]int)
 	var mu sync.Mutex
 
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "fetch_query_logs", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		nodeReq := fetchQueryLogClientRequest{
 			Filters: req.Filters, // use either user-provided filters (no cursor) or cursor snapshot
 			Length:  req.Length,
@@ -254,12 +311,46 @@ func (c *Cluster) FetchQueryLogs(ctx context.Context, req FetchQueryLogClusterRe
 	}, nil
 }
 
+func (c *Cluster) FetchQueryLogsFromNode(ctx context.Context, id int64, req FetchQueryLogNodeRequest) *domain.NodeResult[FetchQueryLogResponse] {
+	c.logger.Debug().Int64("id", id).Msg("fetching query logs from pihole node")
+
+	result := &domain.NodeResult[FetchQueryLogResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "fetch_query_logs_from_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.FetchQueryLogs(nodeCtx, fetchQueryLogClientRequest{
+			Filters: req.Filters,
+			Cursor:  req.Cursor,
+			Length:  req.Length,
+			Start:   req.Start,
+		})
+		result = &domain.NodeResult[FetchQueryLogResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
 func (c *Cluster) GetAllDomainRules(ctx context.Context) map[int64]*domain.NodeResult[GetDomainRulesResponse] {
 	c.logger.Debug().Msg("getting domain rules from all pihole nodes")
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_all_domain_rules", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetAllDomainRules(nodeCtx)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -289,7 +380,7 @@ func (c *Cluster) GetDomainRulesByType(ctx context.Context, opts GetDomainRulesB
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_domain_rules_by_type", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetDomainRulesByType(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -319,7 +410,7 @@ func (c *Cluster) GetDomainRulesByKind(ctx context.Context, opts GetDomainRulesB
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_domain_rules_by_kind", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetDomainRulesByKind(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -349,7 +440,7 @@ func (c *Cluster) GetDomainRulesByDomain(ctx context.Context, opts GetDomainRule
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_domain_rules_by_domain", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetDomainRulesByDomain(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -379,7 +470,7 @@ func (c *Cluster) GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRu
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_domain_rules_by_type_kind", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetDomainRulesByTypeKind(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -409,7 +500,7 @@ func (c *Cluster) GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDo
 
 	results := make(map[int64]*domain.NodeResult[GetDomainRulesResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "get_domain_rules_by_type_kind_domain", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		res, err := client.GetDomainRulesByTypeKindDomain(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -439,7 +530,7 @@ func (c *Cluster) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions)
 
 	results := make(map[int64]*domain.NodeResult[AddDomainRuleResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "add_domain_rule", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		r, err := client.AddDomainRule(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -463,12 +554,77 @@ func (c *Cluster) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions)
 	return results
 }
 
+func (c *Cluster) UpdateDomainRule(ctx context.Context, opts UpdateDomainRuleOptions) map[int64]*domain.NodeResult[UpdateDomainRuleResponse] {
+	c.logger.Debug().Msg("updating domain rule on all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[UpdateDomainRuleResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "update_domain_rule", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.UpdateDomainRule(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[UpdateDomainRuleResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
 func (c *Cluster) RemoveDomainRule(ctx context.Context, opts RemoveDomainRuleOptions) map[int64]*domain.NodeResult[RemoveDomainRuleResponse] {
 	c.logger.Debug().Msg("removing domain rule from all pihole nodes")
+	return c.RemoveDomainRuleFromNodes(ctx, nil, opts)
+}
 
+// ImportDomainRules adds a batch of imported rules to the given nodes. A nil slice adds them to every
+// node. Each node gets importNodeTimeout rather than the default, since a batch can be large.
+func (c *Cluster) ImportDomainRules(ctx context.Context, ids []int64, opts AddDomainRuleOptions) map[int64]*domain.NodeResult[AddDomainRuleResponse] {
+	c.logger.Debug().Msg("importing domain rules to pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[AddDomainRuleResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClientInWithTimeout(ctx, "import_domain_rules", ids, 0, importNodeTimeout, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.AddDomainRule(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[AddDomainRuleResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+// RemoveDomainRuleFromNodes removes a rule from the given nodes only. A nil slice removes it from every
+// node. Ids of nodes that are not in the cluster are left out of the results.
+func (c *Cluster) RemoveDomainRuleFromNodes(ctx context.Context, ids []int64, opts RemoveDomainRuleOptions) map[int64]*domain.NodeResult[RemoveDomainRuleResponse] {
 	results := make(map[int64]*domain.NodeResult[RemoveDomainRuleResponse], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClientIn(ctx, "remove_domain_rule_from_nodes", ids, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		err := client.RemoveDomainRule(nodeCtx, opts)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -492,12 +648,753 @@ func (c *Cluster) RemoveDomainRule(ctx context.Context, opts RemoveDomainRuleOpt
 	return results
 }
 
+func (c *Cluster) GetLists(ctx context.Context) map[int64]*domain.NodeResult[GetListsResponse] {
+	c.logger.Debug().Msg("getting lists from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[GetListsResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_lists", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.GetLists(nodeCtx)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[GetListsResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) AddList(ctx context.Context, opts AddListOptions) map[int64]*domain.NodeResult[AddListResponse] {
+	c.logger.Debug().Msg("adding list to all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[AddListResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "add_list", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.AddList(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[AddListResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) UpdateList(ctx context.Context, opts UpdateListOptions) map[int64]*domain.NodeResult[UpdateListResponse] {
+	c.logger.Debug().Msg("updating list on all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[UpdateListResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "update_list", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.UpdateList(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[UpdateListResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) RemoveList(ctx context.Context, opts RemoveListOptions) map[int64]*domain.NodeResult[RemoveListResponse] {
+	c.logger.Debug().Msg("removing list from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[RemoveListResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "remove_list", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		err := client.RemoveList(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[RemoveListResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetGroups(ctx context.Context) map[int64]*domain.NodeResult[GetGroupsResponse] {
+	c.logger.Debug().Msg("getting groups from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[GetGroupsResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_groups", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.GetGroups(nodeCtx)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[GetGroupsResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) AddGroup(ctx context.Context, opts AddGroupOptions) map[int64]*domain.NodeResult[AddGroupResponse] {
+	c.logger.Debug().Msg("adding group to all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[AddGroupResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "add_group", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.AddGroup(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[AddGroupResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) UpdateGroup(ctx context.Context, opts UpdateGroupOptions) map[int64]*domain.NodeResult[UpdateGroupResponse] {
+	c.logger.Debug().Msg("updating group on all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[UpdateGroupResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "update_group", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.UpdateGroup(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[UpdateGroupResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) RemoveGroup(ctx context.Context, opts RemoveGroupOptions) map[int64]*domain.NodeResult[RemoveGroupResponse] {
+	c.logger.Debug().Msg("removing group from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[RemoveGroupResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "remove_group", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		err := client.RemoveGroup(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[RemoveGroupResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetDevices(ctx context.Context) map[int64]*domain.NodeResult[GetDevicesResponse] {
+	c.logger.Debug().Msg("getting devices from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[GetDevicesResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_devices", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.GetDevices(nodeCtx)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[GetDevicesResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) AddDevice(ctx context.Context, opts AddDeviceOptions) map[int64]*domain.NodeResult[AddDeviceResponse] {
+	c.logger.Debug().Msg("adding device to all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[AddDeviceResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "add_device", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.AddDevice(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[AddDeviceResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) UpdateDevice(ctx context.Context, opts UpdateDeviceOptions) map[int64]*domain.NodeResult[UpdateDeviceResponse] {
+	c.logger.Debug().Msg("updating device on all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[UpdateDeviceResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "update_device", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.UpdateDevice(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[UpdateDeviceResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) RemoveDevice(ctx context.Context, opts RemoveDeviceOptions) map[int64]*domain.NodeResult[RemoveDeviceResponse] {
+	c.logger.Debug().Msg("removing device from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[RemoveDeviceResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "remove_device", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		err := client.RemoveDevice(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[RemoveDeviceResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+		}
+		mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetDomainRulesFromNode(ctx context.Context, id int64) *domain.NodeResult[GetDomainRulesResponse] {
+	c.logger.Debug().Int64("id", id).Msg("getting domain rules from pihole node")
+
+	result := &domain.NodeResult[GetDomainRulesResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "get_domain_rules_from_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.GetAllDomainRules(nodeCtx)
+		result = &domain.NodeResult[GetDomainRulesResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
+func (c *Cluster) GetListsFromNode(ctx context.Context, id int64) *domain.NodeResult[GetListsResponse] {
+	c.logger.Debug().Int64("id", id).Msg("getting lists from pihole node")
+
+	result := &domain.NodeResult[GetListsResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "get_lists_from_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.GetLists(nodeCtx)
+		result = &domain.NodeResult[GetListsResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
+func (c *Cluster) AddDomainRuleToNode(ctx context.Context, id int64, opts AddDomainRuleOptions) *domain.NodeResult[AddDomainRuleResponse] {
+	c.logger.Debug().Int64("id", id).Msg("adding domain rule to pihole node")
+
+	result := &domain.NodeResult[AddDomainRuleResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "add_domain_rule_to_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.AddDomainRule(nodeCtx, opts)
+		result = &domain.NodeResult[AddDomainRuleResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
+func (c *Cluster) UpdateDomainRuleOnNode(ctx context.Context, id int64, opts UpdateDomainRuleOptions) *domain.NodeResult[UpdateDomainRuleResponse] {
+	c.logger.Debug().Int64("id", id).Msg("updating domain rule on pihole node")
+
+	result := &domain.NodeResult[UpdateDomainRuleResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "update_domain_rule_on_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		r, err := client.UpdateDomainRule(nodeCtx, opts)
+		result = &domain.NodeResult[UpdateDomainRuleResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
+func (c *Cluster) RemoveDomainRuleFromNode(ctx context.Context, id int64, opts RemoveDomainRuleOptions) *domain.NodeResult[RemoveDomainRuleResponse] {
+	c.logger.Debug().Int64("id", id).Msg("removing domain rule from pihole node")
+
+	result := &domain.NodeResult[RemoveDomainRuleResponse]{
+		PiholeNode:  domain.PiholeNodeRef{Id: id},
+		Error:       errClientNotFound,
+		ErrorString: errClientNotFound.Error(),
+	}
+	err := c.forEachClientIn(ctx, "remove_domain_rule_from_node", []int64{id}, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		err := client.RemoveDomainRule(nodeCtx, opts)
+		result = &domain.NodeResult[RemoveDomainRuleResponse]{
+			PiholeNode:  client.GetNodeInfo(nodeCtx),
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+		}
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return result
+}
+
+func (c *Cluster) GetStatsSummary(ctx context.Context, opts GetStatsSummaryOptions) map[int64]*domain.NodeResult[StatsSummaryResponse] {
+	c.logger.Debug().Msg("getting stats summary from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[StatsSummaryResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_stats_summary", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetStatsSummary(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[StatsSummaryResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetTopDomains(ctx context.Context, opts GetTopDomainsOptions) map[int64]*domain.NodeResult[TopDomainsResponse] {
+	c.logger.Debug().Msg("getting top domains from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[TopDomainsResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_top_domains", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetTopDomains(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[TopDomainsResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetTopClients(ctx context.Context, opts GetTopClientsOptions) map[int64]*domain.NodeResult[TopClientsResponse] {
+	c.logger.Debug().Msg("getting top clients from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[TopClientsResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_top_clients", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetTopClients(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[TopClientsResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetUpstreams(ctx context.Context, opts GetUpstreamsOptions) map[int64]*domain.NodeResult[UpstreamsResponse] {
+	c.logger.Debug().Msg("getting upstreams from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[UpstreamsResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_upstreams", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetUpstreams(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[UpstreamsResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetBlocking(ctx context.Context) map[int64]*domain.NodeResult[BlockingResponse] {
+	c.logger.Debug().Msg("getting blocking status from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[BlockingResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_blocking", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetBlocking(nodeCtx)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[BlockingResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) SetBlocking(ctx context.Context, opts SetBlockingOptions) map[int64]*domain.NodeResult[BlockingResponse] {
+	c.logger.Debug().Bool("blocking", opts.Blocking).Msg("setting blocking status on all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[BlockingResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "set_blocking", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.SetBlocking(nodeCtx, opts)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[BlockingResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+func (c *Cluster) GetVersion(ctx context.Context) map[int64]*domain.NodeResult[VersionResponse] {
+	c.logger.Debug().Msg("getting version from all pihole nodes")
+
+	results := make(map[int64]*domain.NodeResult[VersionResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClient(ctx, "get_version", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		res, err := client.GetVersion(nodeCtx)
+		node := client.GetNodeInfo(nodeCtx)
+		mu.Lock()
+		results[id] = &domain.NodeResult[VersionResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    res,
+		}
+		mu.Unlock()
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
+// UpdateGravity runs gravity on every node, all at once or one node at a time, reporting each
+// node's output to progress as it arrives.
+func (c *Cluster) UpdateGravity(ctx context.Context, opts UpdateGravityOptions, progress GravityProgress) map[int64]*domain.NodeResult[UpdateGravityResponse] {
+	c.logger.Debug().Bool("sequential", opts.Sequential).Msg("updating gravity on all pihole nodes")
+
+	limit := 0
+	if opts.Sequential {
+		limit = 1
+	}
+
+	results := make(map[int64]*domain.NodeResult[UpdateGravityResponse], len(c.clients))
+	var mu sync.Mutex
+	err := c.forEachClientInWithTimeout(ctx, "update_gravity", nil, limit, gravityNodeTimeout, func(nodeCtx context.Context, id int64, client clientPort) error {
+		node := client.GetNodeInfo(nodeCtx)
+		progress.NodeStarted(node)
+		r, err := client.UpdateGravity(nodeCtx, func(line string) {
+			progress.NodeLine(node, line)
+		})
+		result := &domain.NodeResult[UpdateGravityResponse]{
+			PiholeNode:  node,
+			Success:     err == nil,
+			Error:       err,
+			ErrorString: util.ErrorString(err),
+			Response:    r,
+		}
+		mu.Lock()
+		results[id] = result
+		mu.Unlock()
+		progress.NodeFinished(result)
+
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	return results
+}
+
 func (c *Cluster) AuthStatus(ctx context.Context) map[int64]*domain.NodeResult[domain.AuthStatus] {
 	c.logger.Trace().Msg("getting auth status for cluster")
 
 	results := make(map[int64]*domain.NodeResult[domain.AuthStatus], len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "auth_status", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		authResponse, err := client.AuthStatus(nodeCtx)
 		node := client.GetNodeInfo(nodeCtx)
 		mu.Lock()
@@ -526,7 +1423,7 @@ func (c *Cluster) Logout(ctx context.Context) map[int64]error {
 
 	errs := make(map[int64]error, len(c.clients))
 	var mu sync.Mutex
-	err := c.forEachClient(ctx, 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+	err := c.forEachClient(ctx, "logout", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
 		err := client.Logout(nodeCtx)
 		mu.Lock()
 		errs[id] = err
@@ -0,0 +1,231 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
+)
+
+const defaultMergedQueryLogLength = 100
+
+// exhaustedOffset marks a node whose results have all been read by a merged search.
+const exhaustedOffset = -1
+
+// errNotInSearch is reported for a node that has no position in a merged search. Joining it later
+// would put its newest entries after older ones already served.
+var errNotInSearch = errors.New("node did not answer the first page of this search, start a new search to include it")
+
+type mergedNodePage struct {
+	node     domain.PiholeNodeRef
+	queries  []DNSLogEntry
+	cursor   int
+	offset   int
+	filtered int64
+}
+
+// FetchMergedQueryLogs interleaves the query logs of every node into a single, newest first view,
+// paged by one global page size. Each node's view is pinned to its Pi-hole cursor and read by offset,
+// and the cursor manager records how many entries of each node have been consumed, so consecutive
+// pages never duplicate or skip entries. Re-reading a cursor returns the same page again. A page that
+// is missing a node that failed is marked partial. A node that fails the first page is left out of
+// the rest of the search, while one that fails a later page is retried from where it stopped.
+func (c *Cluster) FetchMergedQueryLogs(ctx context.Context, req FetchQueryLogClusterRequest) (*FetchMergedQueryLogsClusterResponse, error) {
+	c.logger.Debug().Msg("fetching merged query logs from all pihole nodes")
+
+	length := defaultMergedQueryLogLength
+	if req.Length != nil && *req.Length > 0 {
+		length = *req.Length
+	}
+
+	filters := req.Filters
+	var searchState searchStatePort[FetchQueryLogFilters]
+	if req.Cursor != nil && *req.Cursor != "" {
+		var ok bool
+		searchState, ok = c.cursorManager.GetSearchState(*req.Cursor)
+		if !ok {
+			return nil, fmt.Errorf("cursor expired or not found")
+		}
+		if !searchState.IsMerged() {
+			return nil, fmt.Errorf("cursor does not belong to a merged query log")
+		}
+		filters = searchState.GetRequestParams()
+	}
+
+	pages := make(map[int64]*mergedNodePage, len(c.clients))
+	results := make(map[int64]*domain.NodeResult[MergedQueryLogNodeStatus], len(c.clients))
+	nextCursors := make(map[int64]int)
+	nextOffsets := make(map[int64]int)
+	notInSearch := make(map[int64]bool)
+	var mu sync.Mutex
+
+	err := c.forEachClient(ctx, "fetch_merged_query_logs", 0, func(nodeCtx context.Context, id int64, client clientPort) error {
+		node := client.GetNodeInfo(nodeCtx)
+		nodeReq := fetchQueryLogClientRequest{Filters: filters, Length: &length}
+
+		offset := 0
+		if searchState != nil {
+			o, ok := searchState.GetPiholeOffset(id)
+			if !ok {
+				mu.Lock()
+				notInSearch[id] = true
+				results[id] = &domain.NodeResult[MergedQueryLogNodeStatus]{
+					PiholeNode:  node,
+					Success:     false,
+					Error:       errNotInSearch,
+					ErrorString: errNotInSearch.Error(),
+				}
+				mu.Unlock()
+				return nil
+			}
+			offset = o
+			if cursor, ok := searchState.GetPiholeCursor(id); ok {
+				cur := cursor
+				nodeReq.Cursor = &cur
+			}
+		}
+
+		if offset == exhaustedOffset {
+			mu.Lock()
+			if nodeReq.Cursor != nil {
+				nextCursors[id] = *nodeReq.Cursor
+			}
+			nextOffsets[id] = exhaustedOffset
+			results[id] = &domain.NodeResult[MergedQueryLogNodeStatus]{
+				PiholeNode: node,
+				Success:    true,
+				Response:   &MergedQueryLogNodeStatus{Offset: exhaustedOffset, Exhausted: true},
+			}
+			mu.Unlock()
+			return nil
+		}
+
+		start := offset
+		nodeReq.Start = &start
+		response, err := client.FetchQueryLogs(nodeCtx, nodeReq)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			c.logger.Warn().Int64("id", id).Str("error", util.ErrorString(err)).Msg("node operation failed")
+			result := &domain.NodeResult[MergedQueryLogNodeStatus]{
+				PiholeNode:  node,
+				Success:     false,
+				Error:       err,
+				ErrorString: util.ErrorString(err),
+			}
+			results[id] = result
+			if searchState == nil {
+				// The node has no position to resume from, so it stays out of the cursor
+				notInSearch[id] = true
+				return nil
+			}
+			// Keep the node's position so the next page picks up where this one should have
+			if nodeReq.Cursor != nil {
+				nextCursors[id] = *nodeReq.Cursor
+			}
+			nextOffsets[id] = offset
+			result.Response = &MergedQueryLogNodeStatus{Offset: offset}
+			return nil
+		}
+
+		cursor := response.Cursor
+		if nodeReq.Cursor != nil {
+			cursor = *nodeReq.Cursor
+		}
+		pages[id] = &mergedNodePage{
+			node:     node,
+			queries:  response.Queries,
+			cursor:   cursor,
+			offset:   offset,
+			filtered: response.RecordsFiltered,
+		}
+		return nil
+	})
+	if err != nil && errors.Is(err, context.Canceled) {
+		c.logger.Warn().Err(err).Msg("fan-out aborted")
+	}
+
+	queries, taken := mergeQueryLogPages(pages, length)
+
+	endOfResults := true
+	for id, page := range pages {
+		offset := page.offset + taken[id]
+		exhausted := len(page.queries) < length && taken[id] == len(page.queries)
+		if exhausted {
+			offset = exhaustedOffset
+		} else {
+			endOfResults = false
+		}
+		nextCursors[id] = page.cursor
+		nextOffsets[id] = offset
+		results[id] = &domain.NodeResult[MergedQueryLogNodeStatus]{
+			PiholeNode: page.node,
+			Success:    true,
+			Response: &MergedQueryLogNodeStatus{
+				Offset:          page.offset + taken[id],
+				RecordsFiltered: page.filtered,
+				Exhausted:       exhausted,
+			},
+		}
+	}
+	partial := false
+	for id, nr := range results {
+		if nr.Success {
+			continue
+		}
+		partial = true
+		// A node left out of the search has nothing more to give it
+		if !notInSearch[id] {
+			endOfResults = false
+		}
+	}
+
+	return &FetchMergedQueryLogsClusterResponse{
+		Cursor:       c.cursorManager.CreateMergedCursor(filters, nextCursors, nextOffsets),
+		Queries:      queries,
+		Nodes:        results,
+		EndOfResults: endOfResults,
+		Partial:      partial,
+	}, nil
+}
+
+// mergeQueryLogPages does a k-way merge of each node's newest first page, taking at most length
+// entries. It returns the merged entries and how many were taken from each node; entries that were
+// fetched but not taken are fetched again with the next page.
+func mergeQueryLogPages(pages map[int64]*mergedNodePage, length int) ([]MergedDNSLogEntry, map[int64]int) {
+	ids := make([]int64, 0, len(pages))
+	for id := range pages {
+		ids = append(ids, id)
+	}
+	// Ties on time go to the lowest node id, so the order is deterministic
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	taken := make(map[int64]int, len(pages))
+	merged := make([]MergedDNSLogEntry, 0, length)
+	for len(merged) < length {
+		best := int64(-1)
+		for _, id := range ids {
+			i := taken[id]
+			if i >= len(pages[id].queries) {
+				continue
+			}
+			if best == -1 || pages[id].queries[i].Time > pages[best].queries[taken[best]].Time {
+				best = id
+			}
+		}
+		if best == -1 {
+			break
+		}
+		merged = append(merged, MergedDNSLogEntry{
+			DNSLogEntry: pages[best].queries[taken[best]],
+			PiholeNode:  pages[best].node,
+		})
+		taken[best]++
+	}
+	return merged, taken
+}
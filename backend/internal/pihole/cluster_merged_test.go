@@ -0,0 +1,195 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// fakeQueryLogClient serves a fixed, newest first query log. Only the methods a merged search uses are
+// implemented.
+type fakeQueryLogClient struct {
+	clientPort
+	id      int64
+	entries []DNSLogEntry
+	down    bool
+}
+
+func (f *fakeQueryLogClient) GetNodeInfo(ctx context.Context) domain.PiholeNodeRef {
+	return domain.PiholeNodeRef{Id: f.id}
+}
+
+func (f *fakeQueryLogClient) FetchQueryLogs(ctx context.Context, req fetchQueryLogClientRequest) (*FetchQueryLogResponse, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	start := min(*req.Start, len(f.entries))
+	end := min(start+*req.Length, len(f.entries))
+	return &FetchQueryLogResponse{
+		Queries:         f.entries[start:end],
+		Cursor:          1000,
+		RecordsFiltered: int64(len(f.entries)),
+	}, nil
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveFanOut(op string, d time.Duration)     {}
+func (nopMetrics) ObserveNodeRequest(nodeId int64, failed bool) {}
+func (nopMetrics) RemoveNode(nodeId int64)                      {}
+
+func entriesAt(node int64, times ...float64) []DNSLogEntry {
+	entries := make([]DNSLogEntry, 0, len(times))
+	for i, t := range times {
+		entries = append(entries, DNSLogEntry{Id: node*100 + int64(i), Time: t})
+	}
+	return entries
+}
+
+func TestMergeQueryLogPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     map[int64][]float64
+		length    int
+		wantTimes []float64
+		wantNodes []int64
+		wantTaken map[int64]int
+	}{
+		{
+			name:      "interleaves by time",
+			pages:     map[int64][]float64{1: {10, 6}, 2: {9, 8, 1}},
+			length:    4,
+			wantTimes: []float64{10, 9, 8, 6},
+			wantNodes: []int64{1, 2, 2, 1},
+			wantTaken: map[int64]int{1: 2, 2: 2},
+		},
+		{
+			name:      "ties go to the lowest node id",
+			pages:     map[int64][]float64{3: {5}, 1: {5}, 2: {5}},
+			length:    2,
+			wantTimes: []float64{5, 5},
+			wantNodes: []int64{1, 2},
+			wantTaken: map[int64]int{1: 1, 2: 1},
+		},
+		{
+			name:      "stops when every page is used up",
+			pages:     map[int64][]float64{1: {3}, 2: {}},
+			length:    5,
+			wantTimes: []float64{3},
+			wantNodes: []int64{1},
+			wantTaken: map[int64]int{1: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := make(map[int64]*mergedNodePage, len(tt.pages))
+			for id, times := range tt.pages {
+				pages[id] = &mergedNodePage{node: domain.PiholeNodeRef{Id: id}, queries: entriesAt(id, times...)}
+			}
+
+			merged, taken := mergeQueryLogPages(pages, tt.length)
+
+			var times []float64
+			var nodes []int64
+			for _, e := range merged {
+				times = append(times, e.Time)
+				nodes = append(nodes, e.PiholeNode.Id)
+			}
+			if !slices.Equal(times, tt.wantTimes) || !slices.Equal(nodes, tt.wantNodes) {
+				t.Errorf("merged times %v from nodes %v, want %v from %v", times, nodes, tt.wantTimes, tt.wantNodes)
+			}
+			for id, want := range tt.wantTaken {
+				if taken[id] != want {
+					t.Errorf("taken[%d] = %d, want %d", id, taken[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchMergedQueryLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		node1       []float64
+		node2       []float64
+		node2DownOn []int // pages, counted from 0, on which node 2 fails
+		wantTimes   []float64
+		wantPartial []bool
+	}{
+		{
+			name:        "pages until both nodes are exhausted",
+			node1:       []float64{10, 8, 6},
+			node2:       []float64{9, 7},
+			wantTimes:   []float64{10, 9, 8, 7, 6},
+			wantPartial: []bool{false, false, false},
+		},
+		{
+			name:        "node failing the first page stays out of the search",
+			node1:       []float64{10, 8, 6},
+			node2:       []float64{11, 9, 7},
+			node2DownOn: []int{0},
+			wantTimes:   []float64{10, 8, 6},
+			wantPartial: []bool{true, true},
+		},
+		{
+			name:        "node failing a later page resumes where it stopped",
+			node1:       []float64{10, 8, 6, 4},
+			node2:       []float64{9, 7, 5},
+			node2DownOn: []int{1},
+			wantTimes:   []float64{10, 9, 8, 6, 7, 5, 4},
+			wantPartial: []bool{false, true, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node2 := &fakeQueryLogClient{id: 2, entries: entriesAt(2, tt.node2...)}
+			c := &Cluster{
+				clients: map[int64]clientPort{
+					1: &fakeQueryLogClient{id: 1, entries: entriesAt(1, tt.node1...)},
+					2: node2,
+				},
+				cursorManager: NewCursorManager[FetchQueryLogFilters](1),
+				metrics:       nopMetrics{},
+				logger:        zerolog.Nop(),
+			}
+
+			length := 2
+			req := FetchQueryLogClusterRequest{Length: &length}
+			var times []float64
+			var partial []bool
+			for page := 0; ; page++ {
+				if page > 10 {
+					t.Fatal("never reached the end of the results")
+				}
+				node2.down = slices.Contains(tt.node2DownOn, page)
+
+				resp, err := c.FetchMergedQueryLogs(context.Background(), req)
+				if err != nil {
+					t.Fatalf("page %d: %v", page, err)
+				}
+				for _, e := range resp.Queries {
+					times = append(times, e.Time)
+				}
+				partial = append(partial, resp.Partial)
+				if resp.EndOfResults {
+					break
+				}
+				req.Cursor = &resp.Cursor
+			}
+
+			if !slices.Equal(times, tt.wantTimes) {
+				t.Errorf("times = %v, want %v", times, tt.wantTimes)
+			}
+			if !slices.Equal(partial, tt.wantPartial) {
+				t.Errorf("partial pages = %v, want %v", partial, tt.wantPartial)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// slowAddClient takes addTime to add rules. It fails at once when its deadline would cut the add short,
+// so tests do not have to wait.
+type slowAddClient struct {
+	clientPort
+	id      int64
+	addTime time.Duration
+}
+
+func (f *slowAddClient) GetNodeInfo(ctx context.Context) domain.PiholeNodeRef {
+	return domain.PiholeNodeRef{Id: f.id}
+}
+
+func (f *slowAddClient) AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (*AddDomainRuleResponse, error) {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < f.addTime {
+		return nil, context.DeadlineExceeded
+	}
+	return &AddDomainRuleResponse{}, nil
+}
+
+func TestImportDomainRulesTimeout(t *testing.T) {
+	c := &Cluster{
+		clients: map[int64]clientPort{
+			1: &slowAddClient{id: 1, addTime: 30 * time.Second},
+		},
+		metrics: nopMetrics{},
+		logger:  zerolog.Nop(),
+	}
+	opts := AddDomainRuleOptions{Type: RuleTypeDeny, Kind: RuleKindExact, Payload: AddDomainPayload{Domain: []string{"a.com"}}}
+
+	if nr := c.AddDomainRule(context.Background(), opts)[1]; !errors.Is(nr.Error, context.DeadlineExceeded) {
+		t.Errorf("AddDomainRule error = %v, want the default node timeout to cut it short", nr.Error)
+	}
+	if nr := c.ImportDomainRules(context.Background(), nil, opts)[1]; !nr.Success {
+		t.Errorf("ImportDomainRules error = %v, want the import timeout to leave time to finish", nr.Error)
+	}
+}
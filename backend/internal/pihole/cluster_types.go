@@ -14,3 +14,31 @@ type FetchQueryLogsClusterResponse struct {
 	Results      map[int64]*domain.NodeResult[FetchQueryLogResponse] `json:"results"`
 	EndOfResults bool                                                `json:"endOfResults"`
 }
+
+// MergedDNSLogEntry is a query log entry tagged with the node it came from.
+type MergedDNSLogEntry struct {
+	DNSLogEntry
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+}
+
+type MergedQueryLogNodeStatus struct {
+	Offset          int   `json:"offset"`          // entries of this node consumed by the merged view so far
+	RecordsFiltered int64 `json:"recordsFiltered"` // entries on the node that match the filters
+	Exhausted       bool  `json:"exhausted"`
+}
+
+type FetchMergedQueryLogsClusterResponse struct {
+	Cursor       string                                                 `json:"cursor"`
+	Queries      []MergedDNSLogEntry                                    `json:"queries"`
+	Nodes        map[int64]*domain.NodeResult[MergedQueryLogNodeStatus] `json:"nodes"`
+	EndOfResults bool                                                   `json:"endOfResults"`
+	Partial      bool                                                   `json:"partial"` // a node failed, so its entries are missing from this page
+}
+
+// FetchQueryLogNodeRequest reads one node's query log directly, without a cluster cursor.
+type FetchQueryLogNodeRequest struct {
+	Filters FetchQueryLogFilters
+	Cursor  *int // Pi-hole cursor, pins the node's view while paging
+	Length  *int // number of results
+	Start   *int // offset
+}
@@ -29,6 +29,19 @@ func (m *CursorManager[T]) CreateCursor(requestParams T, piholeCursors map[int64
 	return cursor
 }
 
+// CreateMergedCursor snapshots a merged search, which also tracks how far into each node's results
+// the merged view has read.
+func (m *CursorManager[T]) CreateMergedCursor(requestParams T, piholeCursors map[int64]int, piholeOffsets map[int64]int) string {
+	cursor := uuid.NewString()
+	state := NewSearchState(m.ttlHours, requestParams, piholeCursors)
+	state.merged = true
+	state.piholeOffsets = piholeOffsets
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.searchStatesByCursor[cursor] = state
+	return cursor
+}
+
 func (m *CursorManager[T]) GetSearchState(cursor string) (searchStatePort[T], bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -51,6 +64,8 @@ func (m *CursorManager[T]) Clear() {
 type SearchState[T any] struct {
 	expireAt      time.Time
 	piholeCursors map[int64]int // node Id → node cursor
+	piholeOffsets map[int64]int // node Id → entries already read, merged searches only
+	merged        bool
 	requestParams T
 }
 
@@ -74,3 +89,12 @@ func (s *SearchState[T]) GetPiholeCursor(id int64) (int, bool) {
 	cursor, ok := s.piholeCursors[id]
 	return cursor, ok
 }
+
+func (s *SearchState[T]) IsMerged() bool {
+	return s.merged
+}
+
+func (s *SearchState[T]) GetPiholeOffset(id int64) (int, bool) {
+	offset, ok := s.piholeOffsets[id]
+	return offset, ok
+}
@@ -0,0 +1,30 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// IsUnreachable reports whether err means the node could not be reached at all, as opposed to the
+// node answering with an error. Only dial, timeout and other network errors count. A cancelled
+// request says nothing about the node, so it does not.
+func IsUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	// *url.Error is a net.Error too, so only its timeouts are taken at face value
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,40 @@
+package pihole
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ansiEscape matches the terminal colour and cursor codes in pihole -g output.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// readGravityOutput splits gravity's output into lines. Pi-hole redraws progress lines with a
+// carriage return, so that counts as a line break too. Escape codes and blank lines are dropped.
+func readGravityOutput(r io.Reader, onLine func(line string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanGravityLines)
+	for scanner.Scan() {
+		line := strings.TrimRight(ansiEscape.ReplaceAllString(scanner.Text(), ""), " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		onLine(line)
+	}
+	return scanner.Err()
+}
+
+func scanGravityLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
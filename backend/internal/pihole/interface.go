@@ -23,13 +23,44 @@ type clientPort interface {
 	GetDomainRulesByTypeKind(ctx context.Context, opts GetDomainRulesByTypeKindOptions) (*GetDomainRulesResponse, error)
 	GetDomainRulesByTypeKindDomain(ctx context.Context, opts GetDomainRulesByTypeKindDomainOptions) (*GetDomainRulesResponse, error)
 	AddDomainRule(ctx context.Context, opts AddDomainRuleOptions) (*AddDomainRuleResponse, error)
+	UpdateDomainRule(ctx context.Context, opts UpdateDomainRuleOptions) (*UpdateDomainRuleResponse, error)
 	RemoveDomainRule(ctx context.Context, opts RemoveDomainRuleOptions) error
+	GetLists(ctx context.Context) (*GetListsResponse, error)
+	GetGroups(ctx context.Context) (*GetGroupsResponse, error)
+	GetGroup(ctx context.Context, opts GetGroupOptions) (*GetGroupsResponse, error)
+	AddGroup(ctx context.Context, opts AddGroupOptions) (*AddGroupResponse, error)
+	UpdateGroup(ctx context.Context, opts UpdateGroupOptions) (*UpdateGroupResponse, error)
+	RemoveGroup(ctx context.Context, opts RemoveGroupOptions) error
+	GetDevices(ctx context.Context) (*GetDevicesResponse, error)
+	GetDevice(ctx context.Context, opts GetDeviceOptions) (*GetDevicesResponse, error)
+	AddDevice(ctx context.Context, opts AddDeviceOptions) (*AddDeviceResponse, error)
+	UpdateDevice(ctx context.Context, opts UpdateDeviceOptions) (*UpdateDeviceResponse, error)
+	RemoveDevice(ctx context.Context, opts RemoveDeviceOptions) error
+	GetList(ctx context.Context, opts GetListOptions) (*GetListsResponse, error)
+	AddList(ctx context.Context, opts AddListOptions) (*AddListResponse, error)
+	UpdateList(ctx context.Context, opts UpdateListOptions) (*UpdateListResponse, error)
+	RemoveList(ctx context.Context, opts RemoveListOptions) error
+	GetStatsSummary(ctx context.Context, opts GetStatsSummaryOptions) (*StatsSummaryResponse, error)
+	GetTopDomains(ctx context.Context, opts GetTopDomainsOptions) (*TopDomainsResponse, error)
+	GetTopClients(ctx context.Context, opts GetTopClientsOptions) (*TopClientsResponse, error)
+	GetUpstreams(ctx context.Context, opts GetUpstreamsOptions) (*UpstreamsResponse, error)
+	GetBlocking(ctx context.Context) (*BlockingResponse, error)
+	SetBlocking(ctx context.Context, opts SetBlockingOptions) (*BlockingResponse, error)
+	GetVersion(ctx context.Context) (*VersionResponse, error)
+	UpdateGravity(ctx context.Context, onLine func(line string)) (*UpdateGravityResponse, error)
 	AuthStatus(ctx context.Context) (*domain.AuthStatus, error)
 	Logout(ctx context.Context) error
 }
 
+type metricsPort interface {
+	ObserveFanOut(op string, d time.Duration)
+	ObserveNodeRequest(nodeId int64, failed bool)
+	RemoveNode(nodeId int64)
+}
+
 type cursorManagerPort[T any] interface {
 	CreateCursor(requestParams T, piholeCursors map[int64]int) string
+	CreateMergedCursor(requestParams T, piholeCursors map[int64]int, piholeOffsets map[int64]int) string
 	GetSearchState(id string) (searchState searchStatePort[T], exists bool)
 	Clear()
 }
@@ -38,4 +69,13 @@ type searchStatePort[T any] interface {
 	Expiration() time.Time
 	GetRequestParams() T
 	GetPiholeCursor(id int64) (int, bool)
+	IsMerged() bool
+	GetPiholeOffset(id int64) (int, bool)
+}
+
+// GravityProgress follows a cluster gravity update. Calls for different nodes may overlap.
+type GravityProgress interface {
+	NodeStarted(node domain.PiholeNodeRef)
+	NodeLine(node domain.PiholeNodeRef, line string)
+	NodeFinished(result *domain.NodeResult[UpdateGravityResponse])
 }
@@ -0,0 +1,21 @@
+package pihole
+
+import "strings"
+
+type ListType string
+
+const (
+	ListTypeBlock ListType = "block"
+	ListTypeAllow ListType = "allow"
+)
+
+func ParseListType(s string) (ListType, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case string(ListTypeBlock):
+		return ListTypeBlock, true
+	case string(ListTypeAllow):
+		return ListTypeAllow, true
+	default:
+		return "", false
+	}
+}
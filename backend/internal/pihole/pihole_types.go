@@ -15,6 +15,23 @@ type DomainInfo struct {
 	DateModified int64   `json:"date_modified"`
 }
 
+// ListInfo is a Pi-hole adlist (type "block") or allowlist subscription (type "allow").
+type ListInfo struct {
+	Address        string  `json:"address"`
+	Comment        *string `json:"comment,omitempty"`
+	Groups         []int   `json:"groups"`
+	Enabled        bool    `json:"enabled"`
+	Id             int     `json:"id"`
+	Type           string  `json:"type"` // "block" or "allow"
+	DateAdded      int64   `json:"date_added"`
+	DateModified   int64   `json:"date_modified"`
+	DateUpdated    int64   `json:"date_updated"`
+	Number         int64   `json:"number"` // domains on the list at the last gravity run
+	InvalidDomains int64   `json:"invalid_domains"`
+	ABPEntries     int64   `json:"abp_entries"`
+	Status         int     `json:"status"`
+}
+
 type DNSLogEntry struct {
 	Id       int64      `json:"id"`
 	Time     float64    `json:"time"`
@@ -113,11 +130,17 @@ type GetDomainRulesResponse struct {
 	Took    float64      `json:"took"`
 }
 
+type GetListsResponse struct {
+	Lists []ListInfo `json:"lists"`
+	Took  float64    `json:"took"`
+}
+
 type AddDomainPayload struct {
-	Domain  interface{} `json:"domain"`            // string OR []string
-	Comment *string     `json:"comment,omitempty"` // optional
-	Groups  []int       `json:"groups,omitempty"`  // optional, default empty
-	Enabled *bool       `json:"enabled,omitempty"` // optional, default true
+	Domain     interface{} `json:"domain"`               // string OR []string
+	Comment    *string     `json:"comment,omitempty"`    // optional
+	Groups     []int       `json:"groups,omitempty"`     // optional, default empty
+	GroupNames []string    `json:"groupNames,omitempty"` // optional, resolved to each node's own group ids instead of Groups
+	Enabled    *bool       `json:"enabled,omitempty"`    // optional, default true
 }
 
 type AddDomainRuleOptions struct {
@@ -132,6 +155,36 @@ type AddDomainRuleResponse struct {
 	Took      float64          `json:"took"`
 }
 
+// UpdateDomainPayload is a partial update. Nil fields keep each node's current value.
+type UpdateDomainPayload struct {
+	Comment    *string  `json:"comment,omitempty"`
+	Groups     []int    `json:"groups,omitempty"`
+	GroupNames []string `json:"groupNames,omitempty"` // resolved to each node's own group ids instead of Groups
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+type UpdateDomainRuleOptions struct {
+	Type    RuleType
+	Kind    RuleKind
+	Domain  string
+	Payload UpdateDomainPayload
+}
+
+// updateDomainRequest is the full rule Pi-hole expects, since PUT replaces every field.
+type updateDomainRequest struct {
+	Type    RuleType `json:"type"`
+	Kind    RuleKind `json:"kind"`
+	Comment *string  `json:"comment"`
+	Groups  []int    `json:"groups"`
+	Enabled bool     `json:"enabled"`
+}
+
+type UpdateDomainRuleResponse struct {
+	Domains   []DomainInfo     `json:"domains"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
 type RemoveDomainRuleOptions struct {
 	Type   RuleType
 	Kind   RuleKind
@@ -141,3 +194,364 @@ type RemoveDomainRuleOptions struct {
 // RemoveDomainRuleResponse is intentionally empty because Pi-hole returns no body.
 // It exists only so we have a concrete T type for NodeResult.
 type RemoveDomainRuleResponse struct{}
+
+type GetListOptions struct {
+	Type    ListType
+	Address string
+}
+
+type AddListPayload struct {
+	Address interface{} `json:"address"`           // string OR []string
+	Comment *string     `json:"comment,omitempty"` // optional
+	Groups  []int       `json:"groups,omitempty"`  // optional, default empty
+	Enabled *bool       `json:"enabled,omitempty"` // optional, default true
+}
+
+type AddListOptions struct {
+	Type    ListType
+	Payload AddListPayload // request body
+}
+
+type AddListResponse struct {
+	Lists     []ListInfo       `json:"lists"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+// UpdateListPayload is a partial update. Nil fields keep each node's current value.
+type UpdateListPayload struct {
+	Comment *string `json:"comment,omitempty"`
+	Groups  []int   `json:"groups,omitempty"`
+	Enabled *bool   `json:"enabled,omitempty"`
+}
+
+type UpdateListOptions struct {
+	Type    ListType
+	Address string
+	Payload UpdateListPayload
+}
+
+// updateListRequest is the full list Pi-hole expects, since PUT replaces every field.
+type updateListRequest struct {
+	Type    ListType `json:"type"`
+	Comment *string  `json:"comment"`
+	Groups  []int    `json:"groups"`
+	Enabled bool     `json:"enabled"`
+}
+
+type UpdateListResponse struct {
+	Lists     []ListInfo       `json:"lists"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+type RemoveListOptions struct {
+	Type    ListType
+	Address string
+}
+
+type RemoveListResponse struct{}
+
+// GroupInfo is a Pi-hole group. Ids are assigned per node, so groups are matched by name across nodes.
+type GroupInfo struct {
+	Name         string  `json:"name"`
+	Comment      *string `json:"comment,omitempty"`
+	Enabled      bool    `json:"enabled"`
+	Id           int     `json:"id"`
+	DateAdded    int64   `json:"date_added"`
+	DateModified int64   `json:"date_modified"`
+}
+
+type GetGroupsResponse struct {
+	Groups []GroupInfo `json:"groups"`
+	Took   float64     `json:"took"`
+}
+
+type GetGroupOptions struct {
+	Name string
+}
+
+type AddGroupPayload struct {
+	Name    interface{} `json:"name"`              // string OR []string
+	Comment *string     `json:"comment,omitempty"` // optional
+	Enabled *bool       `json:"enabled,omitempty"` // optional, default true
+}
+
+type AddGroupOptions struct {
+	Payload AddGroupPayload // request body
+}
+
+type AddGroupResponse struct {
+	Groups    []GroupInfo      `json:"groups"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+// UpdateGroupPayload is a partial update. Nil fields keep each node's current value; Name renames the group.
+type UpdateGroupPayload struct {
+	Name    *string `json:"name,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+	Enabled *bool   `json:"enabled,omitempty"`
+}
+
+type UpdateGroupOptions struct {
+	Name    string
+	Payload UpdateGroupPayload
+}
+
+// updateGroupRequest is the full group Pi-hole expects, since PUT replaces every field.
+type updateGroupRequest struct {
+	Name    string  `json:"name"`
+	Comment *string `json:"comment"`
+	Enabled bool    `json:"enabled"`
+}
+
+type UpdateGroupResponse struct {
+	Groups    []GroupInfo      `json:"groups"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+type RemoveGroupOptions struct {
+	Name string
+}
+
+type RemoveGroupResponse struct{}
+
+// DeviceInfo is what Pi-hole calls a client: a device identified by IP, MAC, hostname or subnet,
+// with the groups it belongs to. Group ids are per node.
+type DeviceInfo struct {
+	Client       string  `json:"client"`
+	Name         *string `json:"name,omitempty"` // hostname Pi-hole resolved for the client, if any
+	Comment      *string `json:"comment,omitempty"`
+	Groups       []int   `json:"groups"`
+	Id           int     `json:"id"`
+	DateAdded    int64   `json:"date_added"`
+	DateModified int64   `json:"date_modified"`
+}
+
+type GetDevicesResponse struct {
+	Devices []DeviceInfo `json:"clients"`
+	Took    float64      `json:"took"`
+}
+
+type GetDeviceOptions struct {
+	Client string
+}
+
+type AddDevicePayload struct {
+	Client     interface{} `json:"client"`               // string OR []string
+	Comment    *string     `json:"comment,omitempty"`    // optional
+	Groups     []int       `json:"groups,omitempty"`     // optional, default group when empty
+	GroupNames []string    `json:"groupNames,omitempty"` // optional, resolved to each node's own group ids instead of Groups
+}
+
+type AddDeviceOptions struct {
+	Payload AddDevicePayload // request body
+}
+
+type AddDeviceResponse struct {
+	Devices   []DeviceInfo     `json:"clients"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+// UpdateDevicePayload is a partial update. Nil fields keep each node's current value.
+type UpdateDevicePayload struct {
+	Comment    *string  `json:"comment,omitempty"`
+	Groups     []int    `json:"groups,omitempty"`
+	GroupNames []string `json:"groupNames,omitempty"` // resolved to each node's own group ids instead of Groups
+}
+
+type UpdateDeviceOptions struct {
+	Client  string
+	Payload UpdateDevicePayload
+}
+
+// updateDeviceRequest is the full client Pi-hole expects, since PUT replaces every field.
+type updateDeviceRequest struct {
+	Comment *string `json:"comment"`
+	Groups  []int   `json:"groups"`
+}
+
+type UpdateDeviceResponse struct {
+	Devices   []DeviceInfo     `json:"clients"`
+	Processed *ProcessedResult `json:"processed,omitempty"`
+	Took      float64          `json:"took"`
+}
+
+type RemoveDeviceOptions struct {
+	Client string
+}
+
+type RemoveDeviceResponse struct{}
+
+type UpdateGravityOptions struct {
+	Sequential bool // run on one node at a time instead of all at once
+}
+
+type UpdateGravityResponse struct {
+	Lines int     `json:"lines"` // lines of output relayed
+	Took  float64 `json:"took"`  // seconds, measured on our side
+}
+
+// StatsWindow limits a stats request to a time range in unix seconds. When both ends are nil the
+// node's in-memory statistics are used, which cover roughly the last 24 hours. Otherwise the
+// node's long-term database is queried.
+type StatsWindow struct {
+	From  *int64
+	Until *int64
+}
+
+func (w StatsWindow) isSet() bool {
+	return w.From != nil || w.Until != nil
+}
+
+type GetStatsSummaryOptions struct {
+	Window StatsWindow
+}
+
+type StatsSummaryResponse struct {
+	Queries StatsQueries `json:"queries"`
+	Clients StatsClients `json:"clients"`
+	Gravity StatsGravity `json:"gravity"` // not reported by the database summary
+	Took    float64      `json:"took"`
+}
+
+type StatsQueries struct {
+	Total          int64   `json:"total"`
+	Blocked        int64   `json:"blocked"`
+	PercentBlocked float64 `json:"percent_blocked"`
+	UniqueDomains  int64   `json:"unique_domains"`
+	Forwarded      int64   `json:"forwarded"`
+	Cached         int64   `json:"cached"`
+	Frequency      float64 `json:"frequency"` // queries per second; not reported by the database summary
+}
+
+type StatsClients struct {
+	Active int64 `json:"active"`
+	Total  int64 `json:"total"`
+}
+
+type StatsGravity struct {
+	DomainsBeingBlocked int64 `json:"domains_being_blocked"`
+	LastUpdate          int64 `json:"last_update"` // unix seconds
+}
+
+// databaseSummaryResponse is what /stats/database/summary returns. It is mapped onto
+// StatsSummaryResponse so callers don't need to care which endpoint answered.
+type databaseSummaryResponse struct {
+	SumQueries     int64   `json:"sum_queries"`
+	SumBlocked     int64   `json:"sum_blocked"`
+	PercentBlocked float64 `json:"percent_blocked"`
+	TotalClients   int64   `json:"total_clients"`
+	Took           float64 `json:"took"`
+}
+
+type GetTopDomainsOptions struct {
+	Window  StatsWindow
+	Blocked bool
+	Count   int // 0 uses the Pi-hole default
+}
+
+type TopDomain struct {
+	Domain string `json:"domain"`
+	Count  int64  `json:"count"`
+}
+
+type TopDomainsResponse struct {
+	Domains        []TopDomain `json:"domains"`
+	TotalQueries   int64       `json:"total_queries"`
+	BlockedQueries int64       `json:"blocked_queries"`
+	Took           float64     `json:"took"`
+}
+
+type GetTopClientsOptions struct {
+	Window  StatsWindow
+	Blocked bool
+	Count   int // 0 uses the Pi-hole default
+}
+
+type TopClient struct {
+	IP    string `json:"ip"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type TopClientsResponse struct {
+	Clients        []TopClient `json:"clients"`
+	TotalQueries   int64       `json:"total_queries"`
+	BlockedQueries int64       `json:"blocked_queries"`
+	Took           float64     `json:"took"`
+}
+
+type GetUpstreamsOptions struct {
+	Window StatsWindow
+}
+
+// Upstream is a forward destination. Pi-hole also reports the blocklist and its cache as
+// pseudo-upstreams with port -1.
+type Upstream struct {
+	IP         string             `json:"ip"`
+	Name       string             `json:"name"`
+	Port       int                `json:"port"`
+	Count      int64              `json:"count"`
+	Statistics UpstreamStatistics `json:"statistics"`
+}
+
+type UpstreamStatistics struct {
+	Response float64 `json:"response"` // mean response time in seconds
+	Variance float64 `json:"variance"`
+}
+
+type UpstreamsResponse struct {
+	Upstreams        []Upstream `json:"upstreams"`
+	ForwardedQueries int64      `json:"forwarded_queries"`
+	TotalQueries     int64      `json:"total_queries"`
+	Took             float64    `json:"took"`
+}
+
+// Blocking
+
+type BlockingResponse struct {
+	Blocking string   `json:"blocking"` // "enabled", "disabled", "failed" or "unknown"
+	Timer    *float64 `json:"timer"`    // seconds until the blocking state flips back, nil when not timed
+	Took     float64  `json:"took"`
+}
+
+// SetBlockingOptions turns blocking on or off. With a Timer, Pi-hole flips it back after that many
+// seconds.
+type SetBlockingOptions struct {
+	Blocking bool
+	Timer    *int
+}
+
+type setBlockingRequest struct {
+	Blocking bool `json:"blocking"`
+	Timer    *int `json:"timer"` // null for no timer
+}
+
+// Version
+
+type VersionResponse struct {
+	Version VersionInfo `json:"version"`
+	Took    float64     `json:"took"`
+}
+
+type VersionInfo struct {
+	Core ComponentVersion `json:"core"`
+	Web  ComponentVersion `json:"web"`
+	FTL  ComponentVersion `json:"ftl"`
+}
+
+// ComponentVersion holds what is installed and the latest release Pi-hole knows about.
+type ComponentVersion struct {
+	Local  VersionDetail `json:"local"`
+	Remote VersionDetail `json:"remote"`
+}
+
+type VersionDetail struct {
+	Version string `json:"version"`
+	Branch  string `json:"branch"`
+	Hash    string `json:"hash"`
+}
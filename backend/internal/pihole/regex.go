@@ -0,0 +1,129 @@
+package pihole
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Query types FTL accepts in the ;querytype= regex extension.
+var regexQueryTypes = map[string]struct{}{
+	"A": {}, "AAAA": {}, "ANY": {}, "SRV": {}, "SOA": {}, "PTR": {}, "TXT": {}, "NAPTR": {},
+	"MX": {}, "DS": {}, "RRSIG": {}, "DNSKEY": {}, "NS": {}, "SVCB": {}, "HTTPS": {}, "OTHER": {},
+}
+
+// Reply keywords FTL accepts in the ;reply= regex extension. IP addresses are accepted as well.
+var regexReplies = map[string]struct{}{
+	"nodata": {}, "nxdomain": {}, "refused": {}, "ip": {}, "null": {}, "none": {},
+}
+
+// Regex is a parsed Pi-hole FTL regex rule: a POSIX extended regular expression, matched
+// case-insensitively, optionally followed by ;querytype=, ;invert and ;reply= extensions.
+type Regex struct {
+	Pattern          string   `json:"pattern"`
+	QueryTypes       []string `json:"queryTypes,omitempty"`
+	InvertQueryTypes bool     `json:"invertQueryTypes,omitempty"` // ;querytype=!A,AAAA
+	Invert           bool     `json:"invert,omitempty"`
+	Reply            string   `json:"reply,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// ParseRegex validates a regex rule the way FTL would and compiles it for matching.
+func ParseRegex(rule string) (*Regex, error) {
+	if strings.TrimSpace(rule) == "" {
+		return nil, fmt.Errorf("regex is empty")
+	}
+
+	pattern, extensions := splitRegexExtensions(rule)
+	if pattern == "" {
+		return nil, fmt.Errorf("regex pattern is empty")
+	}
+	// Go's syntax is a superset of what FTL's TRE engine understands. Perl-style groups and flags
+	// are the most common thing that compiles here but is rejected on the node.
+	if strings.Contains(pattern, "(?") {
+		return nil, fmt.Errorf("perl-style groups and flags \"(?\" are not supported by FTL")
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &Regex{Pattern: pattern, re: re}
+	for _, ext := range extensions {
+		switch {
+		case ext == "invert":
+			parsed.Invert = true
+
+		case strings.HasPrefix(ext, "querytype="):
+			value := strings.TrimPrefix(ext, "querytype=")
+			if strings.HasPrefix(value, "!") {
+				parsed.InvertQueryTypes = true
+				value = value[1:]
+			}
+			for _, qt := range strings.Split(value, ",") {
+				qt = strings.ToUpper(strings.TrimSpace(qt))
+				if _, ok := regexQueryTypes[qt]; !ok {
+					return nil, fmt.Errorf("unknown query type %q", qt)
+				}
+				parsed.QueryTypes = append(parsed.QueryTypes, qt)
+			}
+
+		case strings.HasPrefix(ext, "reply="):
+			value := strings.TrimPrefix(ext, "reply=")
+			if _, ok := regexReplies[strings.ToLower(value)]; !ok && net.ParseIP(value) == nil {
+				return nil, fmt.Errorf("unknown reply %q", value)
+			}
+			parsed.Reply = value
+
+		default:
+			return nil, fmt.Errorf("unknown regex extension %q", ext)
+		}
+	}
+
+	return parsed, nil
+}
+
+// MatchDomain reports whether the rule would match the domain for a query of any type.
+func (r *Regex) MatchDomain(domain string) bool {
+	return r.re.MatchString(domain) != r.Invert
+}
+
+// Match reports whether the rule would match a query for domain with the given query type.
+func (r *Regex) Match(domain string, queryType string) bool {
+	if len(r.QueryTypes) > 0 {
+		listed := false
+		for _, qt := range r.QueryTypes {
+			if strings.EqualFold(qt, queryType) {
+				listed = true
+				break
+			}
+		}
+		if listed == r.InvertQueryTypes {
+			return false
+		}
+	}
+	return r.MatchDomain(domain)
+}
+
+// splitRegexExtensions separates the pattern from FTL's ";name[=value]" extensions. A ';' that is not
+// followed by a known extension name is part of the pattern.
+func splitRegexExtensions(rule string) (string, []string) {
+	cut := -1
+	for _, name := range []string{";querytype=", ";invert", ";reply="} {
+		if i := strings.Index(rule, name); i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return rule, nil
+	}
+	var extensions []string
+	for _, ext := range strings.Split(rule[cut+1:], ";") {
+		if ext = strings.TrimSpace(ext); ext != "" {
+			extensions = append(extensions, ext)
+		}
+	}
+	return rule[:cut], extensions
+}
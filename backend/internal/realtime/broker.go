@@ -9,6 +9,7 @@ type Broker struct {
 	mu                 sync.RWMutex
 	subscriptions      map[string]map[chan Event]struct{}
 	subscribersChanged chan struct{}
+	topicsChanged      map[string]chan struct{} // per-topic notifications, so a poller can wait on its own topic
 	n                  atomic.Int64
 }
 
@@ -16,6 +17,7 @@ func NewBroker() *Broker {
 	return &Broker{
 		subscriptions:      make(map[string]map[chan Event]struct{}),
 		subscribersChanged: make(chan struct{}, 1),
+		topicsChanged:      make(map[string]chan struct{}),
 	}
 }
 
@@ -26,6 +28,20 @@ func (b *Broker) poke() {
 	}
 }
 
+// pokeTopics must be called with b.mu held.
+func (b *Broker) pokeTopics(topics []string) {
+	for _, topic := range topics {
+		ch := b.topicsChanged[topic]
+		if ch == nil {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (b *Broker) Subscribe(topics []string) (<-chan Event, func()) {
 	ch := make(chan Event, 16)
 	b.mu.Lock()
@@ -35,6 +51,7 @@ func (b *Broker) Subscribe(topics []string) (<-chan Event, func()) {
 		}
 		b.subscriptions[topic][ch] = struct{}{}
 	}
+	b.pokeTopics(topics)
 	b.mu.Unlock()
 	b.n.Add(1)
 	b.poke()
@@ -49,6 +66,7 @@ func (b *Broker) Subscribe(topics []string) (<-chan Event, func()) {
 				}
 			}
 		}
+		b.pokeTopics(topics)
 		b.mu.Unlock()
 		b.n.Add(-1)
 		b.poke()
@@ -81,3 +99,23 @@ func (b *Broker) SubscriberCount() int64 {
 func (b *Broker) SubscribersChanged() <-chan struct{} {
 	return b.subscribersChanged
 }
+
+// TopicSubscriberCount returns the number of subscribers to a single topic.
+func (b *Broker) TopicSubscriberCount(topic string) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return int64(len(b.subscriptions[topic]))
+}
+
+// TopicSubscribersChanged returns a channel that is signalled whenever a topic gains or loses a
+// subscriber. Every call for the same topic returns the same channel.
+func (b *Broker) TopicSubscribersChanged(topic string) <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := b.topicsChanged[topic]
+	if ch == nil {
+		ch = make(chan struct{}, 1)
+		b.topicsChanged[topic] = ch
+	}
+	return ch
+}
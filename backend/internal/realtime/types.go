@@ -4,3 +4,12 @@ type Event struct {
 	Topic string
 	Data  []byte
 }
+
+// TopicQueryLog carries batches of new query log entries, as a JSON array of pihole.MergedDNSLogEntry.
+const TopicQueryLog = "query_log"
+
+// TopicGravity carries the progress of cluster gravity updates, one JSON gravityservice.Event per message.
+const TopicGravity = "gravity"
+
+// TopicBlocking carries blocking state changes across the cluster, one JSON blockingservice.Event per message.
+const TopicBlocking = "blocking"
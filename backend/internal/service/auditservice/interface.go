@@ -0,0 +1,11 @@
+package auditservice
+
+import (
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+)
+
+type auditStore interface {
+	AddAuditEntry(params store.AddAuditEntryParams) (*domain.AuditEntry, error)
+	GetAuditEntries(params store.GetAuditEntriesParams) ([]*domain.AuditEntry, int64, error)
+}
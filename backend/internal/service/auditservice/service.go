@@ -0,0 +1,130 @@
+package auditservice
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/sessions"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+type Service struct {
+	auditStore auditStore
+	logger     zerolog.Logger
+}
+
+func NewService(auditStore auditStore, logger zerolog.Logger) *Service {
+	return &Service{
+		auditStore: auditStore,
+		logger:     logger,
+	}
+}
+
+// Record persists an audit event. Failing to write the audit log never fails the audited action, so
+// errors are only logged.
+func (s *Service) Record(ctx context.Context, event Event) {
+	params := store.AddAuditEntryParams{
+		UserId:       event.UserId,
+		Action:       event.Action,
+		Route:        routeFrom(ctx),
+		Target:       event.Target,
+		NodeOutcomes: event.NodeOutcomes,
+		Success:      event.Success,
+	}
+	if params.UserId == nil {
+		if userId, ok := ctx.Value(sessions.UserIdContextKey).(int64); ok {
+			params.UserId = &userId
+		}
+	}
+	if event.Details != nil {
+		b, err := json.Marshal(event.Details)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("action", event.Action).Msg("error serializing audit details")
+		} else {
+			params.Details = b
+		}
+	}
+
+	if _, err := s.auditStore.AddAuditEntry(params); err != nil {
+		s.logger.Error().Err(err).Str("action", event.Action).Str("target", event.Target).Msg("error writing audit log entry")
+	}
+}
+
+func (s *Service) List(params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultListLimit
+	}
+	if params.Limit > maxListLimit {
+		params.Limit = maxListLimit
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	entries, total, err := s.auditStore.GetAuditEntries(store.GetAuditEntriesParams{
+		UserId:  params.UserId,
+		Action:  params.Action,
+		Success: params.Success,
+		From:    params.From,
+		Until:   params.Until,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResult{
+		Entries: entries,
+		Total:   total,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+	}, nil
+}
+
+// NodeOutcomes flattens a cluster fan-out result into per-node audit outcomes.
+func NodeOutcomes[T any](results map[int64]*domain.NodeResult[T]) []domain.AuditNodeOutcome {
+	outcomes := make([]domain.AuditNodeOutcome, 0, len(results))
+	for _, nr := range results {
+		if nr == nil {
+			continue
+		}
+		outcomes = append(outcomes, domain.AuditNodeOutcome{
+			PiholeNode: nr.PiholeNode,
+			Success:    nr.Success,
+			Error:      nr.ErrorString,
+		})
+	}
+	return outcomes
+}
+
+// AllSucceeded reports whether every node outcome succeeded.
+func AllSucceeded(outcomes []domain.AuditNodeOutcome) bool {
+	for _, o := range outcomes {
+		if !o.Success {
+			return false
+		}
+	}
+	return true
+}
+
+func routeFrom(ctx context.Context) string {
+	rctx := chi.RouteContext(ctx)
+	if rctx == nil {
+		return ""
+	}
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return ""
+	}
+	return strings.TrimSpace(rctx.RouteMethod + " " + pattern)
+}
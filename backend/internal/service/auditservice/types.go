@@ -0,0 +1,63 @@
+package auditservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+)
+
+const (
+	ActionLogin               = "auth.login"
+	ActionLoginFailed         = "auth.login_failed"
+	ActionLogout              = "auth.logout"
+	ActionBlockingEnable      = "blocking.enable"
+	ActionBlockingDisable     = "blocking.disable"
+	ActionDeviceAdd           = "device.add"
+	ActionDeviceUpdate        = "device.update"
+	ActionDeviceRemove        = "device.remove"
+	ActionDomainRuleAdd       = "domain_rule.add"
+	ActionDomainRuleUpdate    = "domain_rule.update"
+	ActionDomainRuleRemove    = "domain_rule.remove"
+	ActionDomainRuleReconcile = "domain_rule.reconcile"
+	ActionDomainRuleRevert    = "domain_rule.revert"
+	ActionDomainRuleReplay    = "domain_rule.replay"
+	ActionGravityUpdate       = "gravity.update"
+	ActionGroupAdd            = "group.add"
+	ActionGroupUpdate         = "group.update"
+	ActionGroupRemove         = "group.remove"
+	ActionListAdd             = "list.add"
+	ActionListUpdate          = "list.update"
+	ActionListRemove          = "list.remove"
+	ActionPiholeAdd           = "pihole.add"
+	ActionPiholeUpdate        = "pihole.update"
+	ActionPiholeRemove        = "pihole.remove"
+	ActionUserUpdate          = "user.update"
+	ActionUserPasswordChange  = "user.password_change"
+)
+
+// Event describes a mutating action. UserId is taken from the request context when not set.
+type Event struct {
+	UserId       *int64
+	Action       string
+	Target       string
+	Details      any
+	NodeOutcomes []domain.AuditNodeOutcome
+	Success      bool
+}
+
+type ListParams struct {
+	UserId  *int64
+	Action  *string
+	Success *bool
+	From    *time.Time
+	Until   *time.Time
+	Limit   int
+	Offset  int
+}
+
+type ListResult struct {
+	Entries []*domain.AuditEntry `json:"entries"`
+	Total   int64                `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
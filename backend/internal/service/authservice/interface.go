@@ -1,7 +1,10 @@
 package authservice
 
 import (
+	"context"
+
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 )
 
 type userStore interface {
@@ -14,3 +17,7 @@ type sessionIssuer interface {
 	DestroySession(userId string) error
 	GetUserId(sessionId string) (int64, bool, error)
 }
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
@@ -1,10 +1,12 @@
 package authservice
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
 	"github.com/rs/zerolog"
@@ -13,18 +15,39 @@ import (
 type Service struct {
 	userStore     userStore
 	sessionIssuer sessionIssuer
+	auditor       auditor
 	logger        zerolog.Logger
 }
 
-func NewService(userStore userStore, sessionIssuer sessionIssuer, logger zerolog.Logger) *Service {
+func NewService(userStore userStore, sessionIssuer sessionIssuer, auditor auditor, logger zerolog.Logger) *Service {
 	return &Service{
 		userStore:     userStore,
 		sessionIssuer: sessionIssuer,
+		auditor:       auditor,
 		logger:        logger,
 	}
 }
 
-func (s *Service) Login(params LoginParams) (*domain.User, string, error) {
+func (s *Service) Login(ctx context.Context, params LoginParams) (*domain.User, string, error) {
+	user, sessionId, err := s.login(params)
+
+	// There is no session yet, so the user id has to be set explicitly
+	event := auditservice.Event{
+		Action:  auditservice.ActionLogin,
+		Target:  params.Username,
+		Success: err == nil,
+	}
+	if err != nil {
+		event.Action = auditservice.ActionLoginFailed
+	} else {
+		event.UserId = &user.Id
+	}
+	s.auditor.Record(ctx, event)
+
+	return user, sessionId, err
+}
+
+func (s *Service) login(params LoginParams) (*domain.User, string, error) {
 	// Validate against the database
 	user, err := s.userStore.ValidateUser(params.Username, params.Password)
 	var wrongPasswordErr *store.WrongPasswordError
@@ -46,7 +69,7 @@ func (s *Service) Login(params LoginParams) (*domain.User, string, error) {
 	return user, sessionId, nil
 }
 
-func (s *Service) Logout(sessionId string) error {
+func (s *Service) Logout(ctx context.Context, sessionId string) error {
 	userId, ok, err := s.sessionIssuer.GetUserId(sessionId)
 	if err != nil {
 		s.logger.Error().Err(err).Int64("userId", userId).Msg("error getting user session")
@@ -55,7 +78,17 @@ func (s *Service) Logout(sessionId string) error {
 	} else {
 		s.logger.Warn().Msg("user attempted logout, but no username was found in the session")
 	}
-	return s.sessionIssuer.DestroySession(sessionId)
+
+	err = s.sessionIssuer.DestroySession(sessionId)
+	event := auditservice.Event{
+		Action:  auditservice.ActionLogout,
+		Success: err == nil,
+	}
+	if ok {
+		event.UserId = &userId
+	}
+	s.auditor.Record(ctx, event)
+	return err
 }
 
 func (s *Service) GetUser(id int64) (*domain.User, error) {
@@ -0,0 +1,22 @@
+package blockingservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type broker interface {
+	Publish(topic string, payload []byte)
+}
+
+type cluster interface {
+	GetBlocking(ctx context.Context) map[int64]*domain.NodeResult[pihole.BlockingResponse]
+	SetBlocking(ctx context.Context, opts pihole.SetBlockingOptions) map[int64]*domain.NodeResult[pihole.BlockingResponse]
+}
@@ -0,0 +1,92 @@
+package blockingservice
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/realtime"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/rs/zerolog"
+)
+
+// timerGrace is how long after a timer runs out the state is re-read, so every node has flipped back.
+const timerGrace = 2 * time.Second
+
+type setAuditDetails struct {
+	Timer *int `json:"timer,omitempty"`
+}
+
+type Service struct {
+	cluster cluster
+	broker  broker
+	auditor auditor
+	logger  zerolog.Logger
+	mu      sync.Mutex
+	timer   *time.Timer // re-reads the state once the latest timed change runs out
+}
+
+func NewService(cluster cluster, broker broker, auditor auditor, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		broker:  broker,
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+func (s *Service) Get(ctx context.Context) map[int64]*domain.NodeResult[pihole.BlockingResponse] {
+	return s.cluster.GetBlocking(ctx)
+}
+
+// Set turns blocking on or off on every node and publishes the resulting states. A timed change is
+// undone by Pi-hole itself, so the states are re-read and published again once the timer runs out.
+func (s *Service) Set(ctx context.Context, params SetParams) map[int64]*domain.NodeResult[pihole.BlockingResponse] {
+	results := s.cluster.SetBlocking(ctx, pihole.SetBlockingOptions{Blocking: params.Blocking, Timer: params.Timer})
+	s.publish(EventSet, results)
+	s.scheduleRecheck(params.Timer)
+
+	action := auditservice.ActionBlockingDisable
+	if params.Blocking {
+		action = auditservice.ActionBlockingEnable
+	}
+	outcomes := auditservice.NodeOutcomes(results)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       action,
+		Details:      setAuditDetails{Timer: params.Timer},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+
+	return results
+}
+
+// scheduleRecheck replaces any pending re-read, since a newer change supersedes the old timer.
+func (s *Service) scheduleRecheck(timer *int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if timer == nil {
+		return
+	}
+	s.timer = time.AfterFunc(time.Duration(*timer)*time.Second+timerGrace, func() {
+		s.logger.Debug().Msg("blocking timer elapsed, re-reading blocking status")
+		s.publish(EventTimerElapsed, s.cluster.GetBlocking(context.Background()))
+	})
+}
+
+func (s *Service) publish(eventType EventType, results map[int64]*domain.NodeResult[pihole.BlockingResponse]) {
+	b, err := json.Marshal(Event{Type: eventType, Results: results, Time: time.Now()})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("error serializing blocking event for broadcasting")
+		return
+	}
+	s.broker.Publish(realtime.TopicBlocking, b)
+}
@@ -0,0 +1,27 @@
+package blockingservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type SetParams struct {
+	Blocking bool
+	Timer    *int // seconds until Pi-hole flips blocking back, nil to keep the new state
+}
+
+type EventType string
+
+const (
+	EventSet          EventType = "set"           // blocking was changed through the API
+	EventTimerElapsed EventType = "timer_elapsed" // a timed change has run out and the state was re-read
+)
+
+// Event is published on the blocking topic with every node's blocking state after a change.
+type Event struct {
+	Type    EventType                                             `json:"type"`
+	Results map[int64]*domain.NodeResult[pihole.BlockingResponse] `json:"results"`
+	Time    time.Time                                             `json:"time"`
+}
@@ -0,0 +1,69 @@
+package deviceservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type addAuditDetails struct {
+	Clients    []string `json:"clients"`
+	Comment    *string  `json:"comment,omitempty"`
+	Groups     []int    `json:"groups,omitempty"`
+	GroupNames []string `json:"groupNames,omitempty"`
+}
+
+type updateAuditDetails struct {
+	Client     string   `json:"client"`
+	Comment    *string  `json:"comment,omitempty"`
+	Groups     []int    `json:"groups,omitempty"`
+	GroupNames []string `json:"groupNames,omitempty"`
+}
+
+type removeAuditDetails struct {
+	Client string `json:"client"`
+}
+
+func (s *Service) auditAdd(ctx context.Context, opts pihole.AddDeviceOptions, outcomes []domain.AuditNodeOutcome) {
+	clients := payloadClients(opts.Payload)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDeviceAdd,
+		Target: strings.Join(clients, ","),
+		Details: addAuditDetails{
+			Clients:    clients,
+			Comment:    opts.Payload.Comment,
+			Groups:     opts.Payload.Groups,
+			GroupNames: opts.Payload.GroupNames,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditUpdate(ctx context.Context, opts pihole.UpdateDeviceOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDeviceUpdate,
+		Target: opts.Client,
+		Details: updateAuditDetails{
+			Client:     opts.Client,
+			Comment:    opts.Payload.Comment,
+			Groups:     opts.Payload.Groups,
+			GroupNames: opts.Payload.GroupNames,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditRemove(ctx context.Context, opts pihole.RemoveDeviceOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionDeviceRemove,
+		Target:       opts.Client,
+		Details:      removeAuditDetails{Client: opts.Client},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
@@ -0,0 +1,21 @@
+package deviceservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type cluster interface {
+	GetDevices(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetDevicesResponse]
+	GetGroups(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+	AddDevice(ctx context.Context, opts pihole.AddDeviceOptions) map[int64]*domain.NodeResult[pihole.AddDeviceResponse]
+	UpdateDevice(ctx context.Context, opts pihole.UpdateDeviceOptions) map[int64]*domain.NodeResult[pihole.UpdateDeviceResponse]
+	RemoveDevice(ctx context.Context, opts pihole.RemoveDeviceOptions) map[int64]*domain.NodeResult[pihole.RemoveDeviceResponse]
+}
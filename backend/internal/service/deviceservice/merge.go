@@ -0,0 +1,73 @@
+package deviceservice
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// mergeDevices lines up each reachable node's devices by client identifier, which Pi-hole compares
+// case-insensitively. Group ids are translated to names using the same node's groups, since ids
+// differ between nodes.
+func mergeDevices(results map[int64]*domain.NodeResult[pihole.GetDevicesResponse], groups map[int64]*domain.NodeResult[pihole.GetGroupsResponse]) *MergedDevices {
+	nodes, unreachable, responses := nodemerge.Split(results)
+	merged := &MergedDevices{
+		Nodes:            nodes,
+		UnreachableNodes: unreachable,
+		Devices:          []MergedDevice{},
+	}
+
+	devicesByNode := make(map[int64]map[string]pihole.DeviceInfo, len(responses))
+	clients := make(map[string]string) // key -> identifier as the lowest node id spells it
+	spelledBy := make(map[string]int64)
+	for id, response := range responses {
+		devices := make(map[string]pihole.DeviceInfo, len(response.Devices))
+		for _, device := range response.Devices {
+			key := strings.ToLower(device.Client)
+			devices[key] = device
+			if by, ok := spelledBy[key]; !ok || id < by {
+				clients[key], spelledBy[key] = device.Client, id
+			}
+		}
+		devicesByNode[id] = devices
+	}
+	groupNames := nodemerge.GroupNames(groups)
+
+	for key, client := range clients {
+		md := MergedDevice{
+			Client:      client,
+			PresentOn:   []domain.PiholeNodeRef{},
+			MissingFrom: []domain.PiholeNodeRef{},
+			States:      []DeviceNodeState{},
+		}
+		for _, node := range merged.Nodes {
+			device, ok := devicesByNode[node.Id][key]
+			if !ok {
+				md.MissingFrom = append(md.MissingFrom, node)
+				continue
+			}
+			md.PresentOn = append(md.PresentOn, node)
+			md.States = append(md.States, DeviceNodeState{
+				PiholeNode: node,
+				Id:         device.Id,
+				Name:       device.Name,
+				Comment:    device.Comment,
+				Groups:     nodemerge.SortedGroups(device.Groups),
+				GroupNames: nodemerge.NamesFor(device.Groups, groupNames[node.Id]),
+			})
+		}
+		md.OnAllNodes = len(md.MissingFrom) == 0
+		// Devices have no enabled flag, so only comment and groups can conflict
+		md.Conflicts = nodemerge.Conflicts(md.States, func(state DeviceNodeState) nodemerge.Fields {
+			return nodemerge.Fields{Comment: state.Comment, Groups: state.GroupNames}
+		})
+		merged.Devices = append(merged.Devices, md)
+	}
+
+	sort.Slice(merged.Devices, func(i, j int) bool { return merged.Devices[i].Client < merged.Devices[j].Client })
+
+	return merged
+}
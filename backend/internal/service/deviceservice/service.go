@@ -0,0 +1,102 @@
+package deviceservice
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	cluster cluster
+	auditor auditor
+	logger  zerolog.Logger
+}
+
+func NewService(cluster cluster, auditor auditor, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetDevicesResponse] {
+	return s.cluster.GetDevices(ctx)
+}
+
+// GetMerged fetches every node's devices along with its groups, so group assignments can be
+// compared by name.
+func (s *Service) GetMerged(ctx context.Context) *MergedDevices {
+	var (
+		devices map[int64]*domain.NodeResult[pihole.GetDevicesResponse]
+		groups  map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+		wg      sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		devices = s.cluster.GetDevices(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		groups = s.cluster.GetGroups(ctx)
+	}()
+	wg.Wait()
+
+	return mergeDevices(devices, groups)
+}
+
+// ValidateAdd checks that every device has an identifier and that groups are given one way only.
+func (s *Service) ValidateAdd(opts pihole.AddDeviceOptions) error {
+	clients := payloadClients(opts.Payload)
+	if len(clients) == 0 {
+		return httpx.NewHttpError(httpx.ErrValidation, "at least one client is required")
+	}
+	for _, client := range clients {
+		if strings.TrimSpace(client) == "" {
+			return httpx.NewHttpError(httpx.ErrValidation, "clients cannot be empty")
+		}
+	}
+	if opts.Payload.Groups != nil && opts.Payload.GroupNames != nil {
+		return httpx.NewHttpError(httpx.ErrValidation, "only one of groups or groupNames may be set")
+	}
+	return nil
+}
+
+func (s *Service) Add(ctx context.Context, opts pihole.AddDeviceOptions) (map[int64]*domain.NodeResult[pihole.AddDeviceResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	results := s.cluster.AddDevice(ctx, opts)
+	s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results))
+	return results, nil
+}
+
+func (s *Service) Update(ctx context.Context, opts pihole.UpdateDeviceOptions) map[int64]*domain.NodeResult[pihole.UpdateDeviceResponse] {
+	results := s.cluster.UpdateDevice(ctx, opts)
+	s.auditUpdate(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func (s *Service) Remove(ctx context.Context, opts pihole.RemoveDeviceOptions) map[int64]*domain.NodeResult[pihole.RemoveDeviceResponse] {
+	results := s.cluster.RemoveDevice(ctx, opts)
+	s.auditRemove(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func payloadClients(payload pihole.AddDevicePayload) []string {
+	switch v := payload.Client.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
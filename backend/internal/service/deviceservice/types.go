@@ -0,0 +1,30 @@
+package deviceservice
+
+import "github.com/auto-dns/pihole-cluster-admin/internal/domain"
+
+// MergedDevices shows every device known to any reachable node and which groups each node puts it in.
+type MergedDevices struct {
+	Nodes            []domain.PiholeNodeRef   `json:"nodes"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+	Devices          []MergedDevice           `json:"devices"`
+}
+
+type MergedDevice struct {
+	Client      string                 `json:"client"` // IP, MAC, hostname or subnet
+	OnAllNodes  bool                   `json:"onAllNodes"`
+	PresentOn   []domain.PiholeNodeRef `json:"presentOn"`
+	MissingFrom []domain.PiholeNodeRef `json:"missingFrom"`
+	Conflicts   []string               `json:"conflicts"` // fields that differ between nodes: comment, groups
+	States      []DeviceNodeState      `json:"states"`
+}
+
+// DeviceNodeState is a device as one node has it. Groups are that node's own ids; GroupNames are
+// what they are compared by.
+type DeviceNodeState struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Id         int                  `json:"id"`
+	Name       *string              `json:"name,omitempty"`
+	Comment    *string              `json:"comment,omitempty"`
+	Groups     []int                `json:"groups"`
+	GroupNames []string             `json:"groupNames"`
+}
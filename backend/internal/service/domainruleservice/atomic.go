@@ -0,0 +1,177 @@
+package domainruleservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+// AddAtomic adds a rule to every node. If any node fails, or rejects any of the domains, the domains
+// that were added on the other nodes are removed again so the cluster is left as it was.
+func (s *Service) AddAtomic(ctx context.Context, opts pihole.AddDomainRuleOptions) (AtomicResult[pihole.AddDomainRuleResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return AtomicResult[pihole.AddDomainRuleResponse]{}, err
+	}
+	return s.addAtomic(ctx, opts, s.addSnapshot(ctx, opts)), nil
+}
+
+func (s *Service) addAtomic(ctx context.Context, opts pihole.AddDomainRuleOptions, before map[int64]map[ruleKey]pihole.DomainInfo) AtomicResult[pihole.AddDomainRuleResponse] {
+	results := s.cluster.AddDomainRule(ctx, opts)
+	result := AtomicResult[pihole.AddDomainRuleResponse]{Results: results}
+	defer func() {
+		s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results), true, result.RolledBack)
+		s.recordHistory(ctx, HistoryActionAdd, nil, remainingChanges(addChanges(opts, results, before), result.Rollback))
+	}()
+
+	if !addFailed(results) {
+		return result
+	}
+
+	var plans []ReconcileNodePlan
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		nodePlan := ReconcileNodePlan{PiholeNode: nr.PiholeNode, Operations: []ReconcileOperation{}}
+		// A rule the node already had was not created by this add, so it stays
+		for _, d := range createdDomains(opts, nr.Response, before[id]) {
+			nodePlan.Operations = append(nodePlan.Operations, ReconcileOperation{
+				Action: ReconcileActionRemove,
+				Type:   string(opts.Type),
+				Kind:   string(opts.Kind),
+				Domain: d,
+			})
+		}
+		if len(nodePlan.Operations) > 0 {
+			plans = append(plans, nodePlan)
+		}
+	}
+
+	result.RolledBack = true
+	result.Rollback = s.applyPlans(ctx, plans)
+	return result
+}
+
+// RemoveAtomic removes a rule from every node. If any node that had the rule fails, the rule is
+// re-added, with its previous comment, groups and enabled state, on the nodes it was removed from.
+func (s *Service) RemoveAtomic(ctx context.Context, opts pihole.RemoveDomainRuleOptions) AtomicResult[pihole.RemoveDomainRuleResponse] {
+	previous := s.cluster.GetDomainRulesByTypeKindDomain(ctx, pihole.GetDomainRulesByTypeKindDomainOptions{
+		Type:   opts.Type,
+		Kind:   opts.Kind,
+		Domain: opts.Domain,
+	})
+
+	results := s.cluster.RemoveDomainRule(ctx, opts)
+	result := AtomicResult[pihole.RemoveDomainRuleResponse]{Results: results}
+	defer func() {
+		s.auditRemove(ctx, opts, auditservice.NodeOutcomes(results), true, result.RolledBack)
+		s.recordHistory(ctx, HistoryActionRemove, nil, remainingChanges(removeChanges(opts, results, snapshot(previous)), result.Rollback))
+	}()
+
+	failed := false
+	for id, nr := range results {
+		if nr.Success {
+			continue
+		}
+		// A node that never had the rule has nothing to remove, so its failure does not break atomicity.
+		if _, had := previousRule(previous[id]); had || previous[id] == nil || !previous[id].Success {
+			failed = true
+		}
+	}
+	if !failed {
+		return result
+	}
+
+	var plans []ReconcileNodePlan
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		rule, had := previousRule(previous[id])
+		if !had {
+			continue
+		}
+		enabled := rule.Enabled
+		plans = append(plans, ReconcileNodePlan{
+			PiholeNode: nr.PiholeNode,
+			Operations: []ReconcileOperation{{
+				Action:  ReconcileActionAdd,
+				Type:    rule.Type,
+				Kind:    rule.Kind,
+				Domain:  rule.Domain,
+				Comment: rule.Comment,
+				Groups:  rule.Groups,
+				Enabled: &enabled,
+			}},
+		})
+	}
+
+	result.RolledBack = true
+	result.Rollback = s.applyPlans(ctx, plans)
+	return result
+}
+
+// addFailed reports whether any node failed the add or rejected one of its domains.
+func addFailed(results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]) bool {
+	for _, nr := range results {
+		if !nr.Success {
+			return true
+		}
+		if nr.Response != nil && processedError(nr.Response.Processed) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// addedDomains returns the domains a node reports as newly added, falling back to the requested
+// domains when Pi-hole does not report per-item results.
+func addedDomains(payload pihole.AddDomainPayload, response *pihole.AddDomainRuleResponse) []string {
+	if response != nil && response.Processed != nil {
+		out := make([]string, 0, len(response.Processed.Success))
+		for _, item := range response.Processed.Success {
+			out = append(out, item.Item)
+		}
+		return out
+	}
+	return payloadDomains(payload)
+}
+
+// createdDomains returns the domains an add created on a node, leaving out the ones the node had before.
+func createdDomains(opts pihole.AddDomainRuleOptions, response *pihole.AddDomainRuleResponse, before map[ruleKey]pihole.DomainInfo) []string {
+	var out []string
+	for _, d := range addedDomains(opts.Payload, response) {
+		if _, existed := before[ruleKey{Type: string(opts.Type), Kind: string(opts.Kind), Domain: d}]; !existed {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func payloadDomains(payload pihole.AddDomainPayload) []string {
+	switch v := payload.Domain.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func previousRule(nr *domain.NodeResult[pihole.GetDomainRulesResponse]) (pihole.DomainInfo, bool) {
+	if nr == nil || !nr.Success || nr.Response == nil || len(nr.Response.Domains) == 0 {
+		return pihole.DomainInfo{}, false
+	}
+	return nr.Response.Domains[0], true
+}
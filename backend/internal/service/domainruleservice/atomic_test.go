@@ -0,0 +1,116 @@
+package domainruleservice
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+func nodeRules(rules map[int64][]string) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse] {
+	out := make(map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse])
+	for id, domains := range rules {
+		response := &pihole.GetDomainRulesResponse{}
+		for _, d := range domains {
+			response.Domains = append(response.Domains, pihole.DomainInfo{Domain: d, Type: "deny", Kind: "exact", Enabled: true})
+		}
+		out[id] = &domain.NodeResult[pihole.GetDomainRulesResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: response}
+	}
+	return out
+}
+
+func processed(t *testing.T, raw string) *pihole.ProcessedResult {
+	t.Helper()
+	var p pihole.ProcessedResult
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
+
+func TestAddAtomicRollback(t *testing.T) {
+	ok := func(id int64, p *pihole.ProcessedResult) *domain.NodeResult[pihole.AddDomainRuleResponse] {
+		return &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: &pihole.AddDomainRuleResponse{Processed: p}}
+	}
+	failed := func(id int64) *domain.NodeResult[pihole.AddDomainRuleResponse] {
+		return &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, ErrorString: "unreachable"}
+	}
+
+	tests := []struct {
+		name           string
+		domains        []string
+		before         map[int64][]string
+		results        func(t *testing.T) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+		wantRolledBack bool
+		wantCalls      []string
+	}{
+		{
+			name:    "all nodes succeed",
+			domains: []string{"a.com"},
+			results: func(t *testing.T) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				return map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]{1: ok(1, nil), 2: ok(2, nil)}
+			},
+		},
+		{
+			name:    "failed node rolls back the others",
+			domains: []string{"a.com"},
+			results: func(t *testing.T) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				return map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]{1: ok(1, nil), 2: failed(2)}
+			},
+			wantRolledBack: true,
+			wantCalls:      []string{"1 remove a.com"},
+		},
+		{
+			name:    "rejected item rolls back the items that were added",
+			domains: []string{"a.com", "b.com"},
+			results: func(t *testing.T) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				return map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]{
+					1: ok(1, processed(t, `{"success":[{"item":"a.com"},{"item":"b.com"}],"errors":[]}`)),
+					2: ok(2, processed(t, `{"success":[{"item":"a.com"}],"errors":[{"item":"b.com","error":"invalid"}]}`)),
+				}
+			},
+			wantRolledBack: true,
+			wantCalls:      []string{"1 remove a.com", "1 remove b.com", "2 remove a.com"},
+		},
+		{
+			name:    "rules that existed before are kept",
+			domains: []string{"a.com", "b.com"},
+			before:  map[int64][]string{1: {"a.com"}},
+			results: func(t *testing.T) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				return map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]{1: ok(1, nil), 2: failed(2)}
+			},
+			wantRolledBack: true,
+			wantCalls:      []string{"1 remove b.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCluster{rules: nodeRules(tt.before)}
+			c.addFunc = func(opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				return tt.results(t)
+			}
+			s := newTestService(c, &fakeHistoryStore{})
+
+			result, err := s.AddAtomic(context.Background(), pihole.AddDomainRuleOptions{
+				Type:    pihole.RuleTypeDeny,
+				Kind:    pihole.RuleKindExact,
+				Payload: pihole.AddDomainPayload{Domain: tt.domains},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.RolledBack != tt.wantRolledBack {
+				t.Errorf("RolledBack = %v, want %v", result.RolledBack, tt.wantRolledBack)
+			}
+			slices.Sort(c.calls)
+			if !slices.Equal(c.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.calls, tt.wantCalls)
+			}
+		})
+	}
+}
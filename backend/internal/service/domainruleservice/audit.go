@@ -0,0 +1,137 @@
+package domainruleservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type addAuditDetails struct {
+	Type       pihole.RuleType `json:"type"`
+	Kind       pihole.RuleKind `json:"kind"`
+	Domains    []string        `json:"domains"`
+	Comment    *string         `json:"comment,omitempty"`
+	Groups     []int           `json:"groups,omitempty"`
+	GroupNames []string        `json:"groupNames,omitempty"`
+	Enabled    *bool           `json:"enabled,omitempty"`
+	Atomic     bool            `json:"atomic"`
+	RolledBack bool            `json:"rolledBack"`
+}
+
+type updateAuditDetails struct {
+	Type       pihole.RuleType `json:"type"`
+	Kind       pihole.RuleKind `json:"kind"`
+	Domain     string          `json:"domain"`
+	Comment    *string         `json:"comment,omitempty"`
+	Groups     []int           `json:"groups,omitempty"`
+	GroupNames []string        `json:"groupNames,omitempty"`
+	Enabled    *bool           `json:"enabled,omitempty"`
+}
+
+type removeAuditDetails struct {
+	Type       pihole.RuleType `json:"type"`
+	Kind       pihole.RuleKind `json:"kind"`
+	Domain     string          `json:"domain"`
+	Atomic     bool            `json:"atomic"`
+	RolledBack bool            `json:"rolledBack"`
+}
+
+type reconcileAuditDetails struct {
+	Source     ReconcileSource `json:"source"`
+	NodeId     *int64          `json:"nodeId,omitempty"`
+	Operations int             `json:"operations"`
+}
+
+type revertAuditDetails struct {
+	ChangeSetId int64 `json:"changeSetId"`
+	Changes     int   `json:"changes"`
+	Applied     int   `json:"applied"`
+}
+
+type replayAuditDetails struct {
+	Replayed  int `json:"replayed"`
+	Rejected  int `json:"rejected"`
+	Remaining int `json:"remaining"`
+}
+
+func (s *Service) auditAdd(ctx context.Context, opts pihole.AddDomainRuleOptions, outcomes []domain.AuditNodeOutcome, atomic, rolledBack bool) {
+	domains := payloadDomains(opts.Payload)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDomainRuleAdd,
+		Target: ruleTarget(opts.Type, opts.Kind, domains...),
+		Details: addAuditDetails{
+			Type:       opts.Type,
+			Kind:       opts.Kind,
+			Domains:    domains,
+			Comment:    opts.Payload.Comment,
+			Groups:     opts.Payload.Groups,
+			GroupNames: opts.Payload.GroupNames,
+			Enabled:    opts.Payload.Enabled,
+			Atomic:     atomic,
+			RolledBack: rolledBack,
+		},
+		NodeOutcomes: outcomes,
+		Success:      !rolledBack && auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditUpdate(ctx context.Context, opts pihole.UpdateDomainRuleOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDomainRuleUpdate,
+		Target: ruleTarget(opts.Type, opts.Kind, opts.Domain),
+		Details: updateAuditDetails{
+			Type:       opts.Type,
+			Kind:       opts.Kind,
+			Domain:     opts.Domain,
+			Comment:    opts.Payload.Comment,
+			Groups:     opts.Payload.Groups,
+			GroupNames: opts.Payload.GroupNames,
+			Enabled:    opts.Payload.Enabled,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditRemove(ctx context.Context, opts pihole.RemoveDomainRuleOptions, outcomes []domain.AuditNodeOutcome, atomic, rolledBack bool) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDomainRuleRemove,
+		Target: ruleTarget(opts.Type, opts.Kind, opts.Domain),
+		Details: removeAuditDetails{
+			Type:       opts.Type,
+			Kind:       opts.Kind,
+			Domain:     opts.Domain,
+			Atomic:     atomic,
+			RolledBack: rolledBack,
+		},
+		NodeOutcomes: outcomes,
+		Success:      !rolledBack && auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditReconcile(ctx context.Context, result *ReconcileResult) {
+	operations := 0
+	for _, nodePlan := range result.Plan.Nodes {
+		operations += len(nodePlan.Operations)
+	}
+	outcomes := auditservice.NodeOutcomes(result.Results)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionDomainRuleReconcile,
+		Target: string(result.Plan.Source),
+		Details: reconcileAuditDetails{
+			Source:     result.Plan.Source,
+			NodeId:     result.Plan.NodeId,
+			Operations: operations,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func ruleTarget(ruleType pihole.RuleType, ruleKind pihole.RuleKind, domains ...string) string {
+	return fmt.Sprintf("%s/%s/%s", ruleType, ruleKind, strings.Join(domains, ","))
+}
@@ -0,0 +1,117 @@
+package domainruleservice
+
+import (
+	"sort"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type ruleKey struct {
+	Type   string
+	Kind   string
+	Domain string
+}
+
+func keyOf(rule pihole.DomainInfo) ruleKey {
+	return ruleKey{Type: rule.Type, Kind: rule.Kind, Domain: rule.Domain}
+}
+
+// collectNodeRules indexes the rules of every node that responded successfully. Nodes that failed to
+// respond are returned separately so callers never treat them as "missing" a rule.
+func collectNodeRules(results map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]) ([]domain.PiholeNodeRef, []domain.UnreachableNode, map[int64]map[ruleKey]pihole.DomainInfo) {
+	nodes, unreachable, responses := nodemerge.Split(results)
+	rulesByNode := make(map[int64]map[ruleKey]pihole.DomainInfo, len(responses))
+	for id, response := range responses {
+		rules := make(map[ruleKey]pihole.DomainInfo, len(response.Domains))
+		for _, rule := range response.Domains {
+			rules[keyOf(rule)] = rule
+		}
+		rulesByNode[id] = rules
+	}
+
+	return nodes, unreachable, rulesByNode
+}
+
+// buildDriftReport compares the domain rules of every reachable node. Groups are compared by name,
+// since group ids differ between nodes.
+func buildDriftReport(results map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse], groupNames map[int64]map[int]string) DriftReport {
+	nodes, unreachable, rulesByNode := collectNodeRules(results)
+	report := DriftReport{
+		Nodes:            nodes,
+		UnreachableNodes: unreachable,
+		Rules:            []RuleDrift{},
+	}
+
+	keys := make(map[ruleKey]struct{})
+	for _, rules := range rulesByNode {
+		for key := range rules {
+			keys[key] = struct{}{}
+		}
+	}
+
+	for key := range keys {
+		drift := RuleDrift{
+			Type:        key.Type,
+			Kind:        key.Kind,
+			Domain:      key.Domain,
+			PresentOn:   []domain.PiholeNodeRef{},
+			MissingFrom: []domain.PiholeNodeRef{},
+			Conflicts:   []string{},
+			States:      []RuleNodeState{},
+		}
+		for _, node := range report.Nodes {
+			rule, ok := rulesByNode[node.Id][key]
+			if !ok {
+				drift.MissingFrom = append(drift.MissingFrom, node)
+				continue
+			}
+			drift.PresentOn = append(drift.PresentOn, node)
+			drift.States = append(drift.States, RuleNodeState{
+				PiholeNode: node,
+				Enabled:    rule.Enabled,
+				Comment:    rule.Comment,
+				Groups:     nodemerge.SortedGroups(rule.Groups),
+				GroupNames: nodemerge.NamesFor(rule.Groups, groupNames[node.Id]),
+			})
+		}
+		drift.Conflicts = findConflicts(drift.States)
+		drift.InSync = len(drift.MissingFrom) == 0 && len(drift.Conflicts) == 0
+
+		report.Summary.Total++
+		switch {
+		case drift.InSync:
+			report.Summary.InSync++
+		case len(drift.MissingFrom) > 0:
+			report.Summary.Missing++
+		}
+		if len(drift.Conflicts) > 0 {
+			report.Summary.Conflicting++
+		}
+		report.Rules = append(report.Rules, drift)
+	}
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		return ruleKeyLess(ruleKey{a.Type, a.Kind, a.Domain}, ruleKey{b.Type, b.Kind, b.Domain})
+	})
+
+	return report
+}
+
+func ruleKeyLess(a, b ruleKey) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.Domain < b.Domain
+}
+
+func findConflicts(states []RuleNodeState) []string {
+	return nodemerge.Conflicts(states, func(state RuleNodeState) nodemerge.Fields {
+		return nodemerge.Fields{Enabled: state.Enabled, Comment: state.Comment, Groups: state.GroupNames}
+	})
+}
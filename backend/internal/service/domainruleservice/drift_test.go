@@ -0,0 +1,176 @@
+package domainruleservice
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// groupedRules gives each node one deny rule on a.com in the given groups.
+func groupedRules(groups map[int64][]int) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse] {
+	out := make(map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse])
+	for id, ids := range groups {
+		out[id] = &domain.NodeResult[pihole.GetDomainRulesResponse]{
+			PiholeNode: domain.PiholeNodeRef{Id: id},
+			Success:    true,
+			Response: &pihole.GetDomainRulesResponse{Domains: []pihole.DomainInfo{
+				{Type: "deny", Kind: "exact", Domain: "a.com", Enabled: true, Groups: ids},
+			}},
+		}
+	}
+	return out
+}
+
+func TestDriftComparesGroupsByName(t *testing.T) {
+	names := map[int64]map[int]string{
+		1: {0: "Default", 3: "kids"},
+		2: {0: "Default", 7: "kids", 8: "guests"},
+	}
+
+	tests := []struct {
+		name          string
+		groups        map[int64][]int
+		names         map[int64]map[int]string
+		wantConflicts []string
+	}{
+		{name: "same names, different ids", groups: map[int64][]int{1: {0, 3}, 2: {7, 0}}, names: names, wantConflicts: []string{}},
+		{name: "different names", groups: map[int64][]int{1: {0, 3}, 2: {0, 8}}, names: names, wantConflicts: []string{"groups"}},
+		{name: "same ids, different names", groups: map[int64][]int{1: {3}, 2: {3}}, names: map[int64]map[int]string{1: {3: "kids"}, 2: {3: "guests"}}, wantConflicts: []string{"groups"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := buildDriftReport(groupedRules(tt.groups), tt.names)
+			if len(report.Rules) != 1 {
+				t.Fatalf("got %d rules, want 1", len(report.Rules))
+			}
+			if got := report.Rules[0].Conflicts; !slices.Equal(got, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", got, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestReconcileAddsGroupsByName(t *testing.T) {
+	results := groupedRules(map[int64][]int{1: {0, 3}})
+	results[2] = &domain.NodeResult[pihole.GetDomainRulesResponse]{PiholeNode: domain.PiholeNodeRef{Id: 2}, Success: true, Response: &pihole.GetDomainRulesResponse{}}
+
+	tests := []struct {
+		name       string
+		names      map[int64]map[int]string
+		wantGroups []int
+		wantNames  []string
+	}{
+		{name: "source groups known", names: map[int64]map[int]string{1: {0: "Default", 3: "kids"}}, wantNames: []string{"Default", "kids"}},
+		{name: "source groups unknown", names: map[int64]map[int]string{}, wantGroups: []int{0, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := buildReconcilePlan(ReconcileParams{Source: ReconcileSourceUnion}, results, tt.names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plan.Nodes) != 1 || len(plan.Nodes[0].Operations) != 1 {
+				t.Fatalf("plan = %+v, want one add on node 2", plan.Nodes)
+			}
+			op := plan.Nodes[0].Operations[0]
+			if !slices.Equal(op.Groups, tt.wantGroups) || !slices.Equal(op.GroupNames, tt.wantNames) {
+				t.Errorf("groups = %v, names = %v, want %v, %v", op.Groups, op.GroupNames, tt.wantGroups, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestPlanChangesRecordsResolvedGroups(t *testing.T) {
+	enabled := true
+	op := ReconcileOperation{Action: ReconcileActionAdd, Type: "deny", Kind: "exact", Domain: "a.com", GroupNames: []string{"kids"}, Enabled: &enabled}
+	results := map[int64]*domain.NodeResult[ReconcileNodeResponse]{
+		2: {PiholeNode: domain.PiholeNodeRef{Id: 2}, Success: true, Response: &ReconcileNodeResponse{Operations: []ReconcileOperationResult{{
+			ReconcileOperation: op,
+			Success:            true,
+			current:            &pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: "a.com", Enabled: true, Groups: []int{7}},
+		}}}},
+	}
+
+	changes := planChanges(results, nil)
+	if len(changes) != 1 || changes[0].Current == nil {
+		t.Fatalf("changes = %+v, want one add", changes)
+	}
+	if got := changes[0].Current.Groups; !slices.Equal(got, []int{7}) {
+		t.Errorf("groups = %v, want the node's id [7]", got)
+	}
+}
+
+func TestReconcilePlanUpdatesConflictingRules(t *testing.T) {
+	comment := func(s string) *string { return &s }
+	enabled := true
+	names := map[int64]map[int]string{1: {0: "Default", 3: "kids"}, 2: {0: "Default", 7: "kids"}}
+	rule := func(enabled bool, c *string, groups ...int) pihole.DomainInfo {
+		return pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: "a.com", Enabled: enabled, Comment: c, Groups: groups}
+	}
+
+	tests := []struct {
+		name string
+		have pihole.DomainInfo
+		want *ReconcileOperation // nil means no operation for node 2
+	}{
+		{name: "in sync, groups numbered differently", have: rule(true, comment("x"), 0, 7)},
+		{name: "groups listed in another order", have: rule(true, comment("x"), 7, 0)},
+		{
+			name: "enabled differs",
+			have: rule(false, comment("x"), 0, 7),
+			want: &ReconcileOperation{Enabled: &enabled},
+		},
+		{
+			name: "comment differs",
+			have: rule(true, nil, 0, 7),
+			want: &ReconcileOperation{Comment: comment("x")},
+		},
+		{
+			name: "groups differ",
+			have: rule(true, comment("x"), 0),
+			want: &ReconcileOperation{GroupNames: []string{"Default", "kids"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]{
+				1: {PiholeNode: domain.PiholeNodeRef{Id: 1}, Success: true, Response: &pihole.GetDomainRulesResponse{Domains: []pihole.DomainInfo{rule(true, comment("x"), 3, 0)}}},
+				2: {PiholeNode: domain.PiholeNodeRef{Id: 2}, Success: true, Response: &pihole.GetDomainRulesResponse{Domains: []pihole.DomainInfo{tt.have}}},
+			}
+			nodeId := int64(1)
+			plan, err := buildReconcilePlan(ReconcileParams{Source: ReconcileSourceNode, NodeId: &nodeId}, results, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == nil {
+				if len(plan.Nodes) != 0 {
+					t.Fatalf("plan = %+v, want no operations", plan.Nodes)
+				}
+				return
+			}
+			if len(plan.Nodes) != 1 || len(plan.Nodes[0].Operations) != 1 {
+				t.Fatalf("plan = %+v, want one update on node 2", plan.Nodes)
+			}
+			op := plan.Nodes[0].Operations[0]
+			if op.Action != ReconcileActionUpdate || op.Domain != "a.com" {
+				t.Fatalf("operation = %+v, want an update of a.com", op)
+			}
+			if !slices.Equal(op.GroupNames, tt.want.GroupNames) || op.Groups != nil {
+				t.Errorf("groups = %v, names = %v, want names %v", op.Groups, op.GroupNames, tt.want.GroupNames)
+			}
+			if nodemerge.CommentValue(op.Comment) != nodemerge.CommentValue(tt.want.Comment) || (op.Comment == nil) != (tt.want.Comment == nil) {
+				t.Errorf("comment = %v, want %v", op.Comment, tt.want.Comment)
+			}
+			if (op.Enabled == nil) != (tt.want.Enabled == nil) || (op.Enabled != nil && *op.Enabled != *tt.want.Enabled) {
+				t.Errorf("enabled = %v, want %v", op.Enabled, tt.want.Enabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package domainruleservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/sessions"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+// AddTemporary adds a rule like Add, or like AddAtomic when atomic is set, and schedules its removal
+// once ttl has passed. Only the nodes where the add created the rule get it removed again, so a node
+// that already had the rule keeps it. A rolled back add schedules nothing. A nil result with an error
+// means the rule was invalid and nothing was added.
+func (s *Service) AddTemporary(ctx context.Context, opts pihole.AddDomainRuleOptions, atomic bool, ttl time.Duration) (*TemporaryAddResult, error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	before := s.addSnapshot(ctx, opts)
+
+	var results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	result := &TemporaryAddResult{Expirations: []*domain.DomainRuleExpiration{}}
+	if atomic {
+		atomicResult := s.addAtomic(ctx, opts, before)
+		result.Results = atomicResult
+		if atomicResult.RolledBack {
+			return result, nil
+		}
+		results = atomicResult.Results
+	} else {
+		results = s.add(ctx, opts, before)
+		result.Results = results
+	}
+
+	created := make(map[string][]int64)
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		for _, d := range createdDomains(opts, nr.Response, before[id]) {
+			created[d] = append(created[d], id)
+		}
+	}
+
+	params := store.AddDomainRuleExpirationParams{
+		Type:      string(opts.Type),
+		Kind:      string(opts.Kind),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if userId, ok := ctx.Value(sessions.UserIdContextKey).(int64); ok {
+		params.UserId = &userId
+	}
+	for _, d := range payloadDomains(opts.Payload) {
+		ids, ok := created[d]
+		if !ok {
+			continue
+		}
+		slices.Sort(ids)
+		params.Domain = d
+		params.PiholeNodeIds = ids
+		expiration, err := s.expirationStore.AddExpiration(params)
+		if err != nil {
+			return result, err
+		}
+		result.Expirations = append(result.Expirations, expiration)
+	}
+	return result, nil
+}
+
+func (s *Service) GetExpirations() ([]*domain.DomainRuleExpiration, error) {
+	return s.expirationStore.GetExpirations()
+}
+
+// CancelExpiration makes a temporary rule permanent.
+func (s *Service) CancelExpiration(id int64) error {
+	found, err := s.expirationStore.RemoveExpiration(id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return httpx.NewHttpError(httpx.ErrNotFound, fmt.Sprintf("expiration %d not found", id))
+	}
+	return nil
+}
+
+// clearExpiration takes the nodes a rule is gone from off its expiration, so it is not removed from
+// them again later. A node counts as done when the removal succeeded, was queued because the node was
+// unreachable, or the node did not have the rule. Nodes that are no longer in the cluster are dropped
+// too. The expiration is deleted once no nodes are left.
+func (s *Service) clearExpiration(ctx context.Context, ids []int64, opts pihole.RemoveDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse], before map[int64]map[ruleKey]pihole.DomainInfo) {
+	logger := s.logger.With().Str("domain", opts.Domain).Logger()
+	if ctx.Err() != nil {
+		// An aborted fan-out leaves nodes out of the results without them having left the cluster
+		return
+	}
+
+	expiration, err := s.expirationStore.GetExpirationByRule(string(opts.Type), string(opts.Kind), opts.Domain)
+	if err != nil {
+		logger.Error().Err(err).Msg("error getting domain rule expiration")
+		return
+	}
+	if expiration == nil {
+		return
+	}
+
+	nodeIds := expiration.PiholeNodeIds
+	if nodeIds == nil {
+		for id := range results {
+			nodeIds = append(nodeIds, id)
+		}
+		slices.Sort(nodeIds)
+	}
+
+	key := ruleKey{Type: string(opts.Type), Kind: string(opts.Kind), Domain: opts.Domain}
+	remaining := []int64{}
+	for _, id := range nodeIds {
+		nr, inCluster := results[id]
+		if !inCluster {
+			// Only a node the removal was sent to is known to have left the cluster
+			if ids != nil && !slices.Contains(ids, id) {
+				remaining = append(remaining, id)
+			}
+			continue
+		}
+		rules, known := before[id]
+		_, had := rules[key]
+		if nr.Success || pihole.IsUnreachable(nr.Error) || (known && !had) {
+			continue
+		}
+		remaining = append(remaining, id)
+	}
+
+	if len(remaining) == 0 {
+		if _, err := s.expirationStore.RemoveExpiration(expiration.Id); err != nil {
+			logger.Error().Err(err).Msg("error clearing domain rule expiration")
+		}
+		return
+	}
+	if len(remaining) != len(nodeIds) || expiration.PiholeNodeIds == nil {
+		if err := s.expirationStore.SetExpirationNodes(expiration.Id, remaining); err != nil {
+			logger.Error().Err(err).Msg("error narrowing domain rule expiration")
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package domainruleservice
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+func TestAddTemporarySchedulesCreatedNodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		before    map[int64][]string
+		failed    []int64
+		atomic    bool
+		wantNodes map[string][]int64
+	}{
+		{
+			name:      "every node created the rule",
+			wantNodes: map[string][]int64{"a.com": {1, 2}},
+		},
+		{
+			name:      "node with a permanent rule keeps it",
+			before:    map[int64][]string{1: {"a.com"}},
+			wantNodes: map[string][]int64{"a.com": {2}},
+		},
+		{
+			name:      "failed node is not scheduled",
+			failed:    []int64{2},
+			wantNodes: map[string][]int64{"a.com": {1}},
+		},
+		{
+			name:      "add failed everywhere",
+			failed:    []int64{1, 2},
+			wantNodes: map[string][]int64{},
+		},
+		{
+			name:      "rolled back atomic add",
+			failed:    []int64{2},
+			atomic:    true,
+			wantNodes: map[string][]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCluster{nodes: []int64{1, 2}, rules: nodeRules(tt.before)}
+			c.addFunc = func(opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+				results := make(map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse])
+				for _, id := range c.nodes {
+					results[id] = &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: !slices.Contains(tt.failed, id)}
+				}
+				return results
+			}
+			expirations := &fakeExpirationStore{}
+			s := NewService(c, &fakeHistoryStore{}, &fakePendingStore{}, expirations, &fakeAuditor{}, testLogger)
+
+			result, err := s.AddTemporary(context.Background(), pihole.AddDomainRuleOptions{
+				Type:    pihole.RuleTypeDeny,
+				Kind:    pihole.RuleKindExact,
+				Payload: pihole.AddDomainPayload{Domain: []string{"a.com"}},
+			}, tt.atomic, time.Hour)
+			if err != nil {
+				t.Fatalf("AddTemporary: %v", err)
+			}
+
+			got := make(map[string][]int64)
+			for _, expiration := range result.Expirations {
+				got[expiration.Domain] = expiration.PiholeNodeIds
+			}
+			if len(got) != len(tt.wantNodes) {
+				t.Fatalf("expirations = %v, want %v", got, tt.wantNodes)
+			}
+			for d, want := range tt.wantNodes {
+				if !slices.Equal(got[d], want) {
+					t.Errorf("%s nodes = %v, want %v", d, got[d], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveClearsExpirationOnlyWhereRemoved(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name          string
+		scheduled     []int64
+		nodes         []int64
+		before        map[int64][]string
+		removeErr     map[int64]error
+		wantRemaining []int64 // nil when the expiration is gone
+	}{
+		{
+			name:      "removed everywhere",
+			scheduled: []int64{1, 2},
+			nodes:     []int64{1, 2},
+			before:    map[int64][]string{1: {"a.com"}, 2: {"a.com"}},
+		},
+		{
+			name:          "failed node stays scheduled",
+			scheduled:     []int64{1, 2},
+			nodes:         []int64{1, 2},
+			before:        map[int64][]string{1: {"a.com"}, 2: {"a.com"}},
+			removeErr:     map[int64]error{2: errors.New("unexpected status code 500")},
+			wantRemaining: []int64{2},
+		},
+		{
+			name:      "unreachable node is queued instead",
+			scheduled: []int64{1, 2},
+			nodes:     []int64{1, 2},
+			before:    map[int64][]string{1: {"a.com"}},
+			removeErr: map[int64]error{2: unreachable},
+		},
+		{
+			name:      "node without the rule is done",
+			scheduled: []int64{1, 2},
+			nodes:     []int64{1, 2},
+			before:    map[int64][]string{1: {"a.com"}, 2: {}},
+			removeErr: map[int64]error{2: errors.New("unexpected status code 404")},
+		},
+		{
+			name:      "node that left the cluster is dropped",
+			scheduled: []int64{1, 3},
+			nodes:     []int64{1, 2},
+			before:    map[int64][]string{1: {"a.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCluster{nodes: tt.nodes, rules: nodeRules(tt.before), removeErr: tt.removeErr}
+			expirations := &fakeExpirationStore{}
+			expirations.AddExpiration(storeExpiration("a.com", tt.scheduled))
+			s := NewService(c, &fakeHistoryStore{}, &fakePendingStore{}, expirations, &fakeAuditor{}, testLogger)
+
+			s.RemoveFromNodes(context.Background(), tt.scheduled, pihole.RemoveDomainRuleOptions{
+				Type:   pihole.RuleTypeDeny,
+				Kind:   pihole.RuleKindExact,
+				Domain: "a.com",
+			})
+
+			expiration, _ := expirations.GetExpirationByRule("deny", "exact", "a.com")
+			switch {
+			case tt.wantRemaining == nil && expiration != nil:
+				t.Errorf("expiration kept with nodes %v", expiration.PiholeNodeIds)
+			case tt.wantRemaining != nil && expiration == nil:
+				t.Errorf("expiration removed, want nodes %v", tt.wantRemaining)
+			case tt.wantRemaining != nil && !slices.Equal(expiration.PiholeNodeIds, tt.wantRemaining):
+				t.Errorf("nodes = %v, want %v", expiration.PiholeNodeIds, tt.wantRemaining)
+			}
+		})
+	}
+}
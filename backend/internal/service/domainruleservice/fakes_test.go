@@ -0,0 +1,256 @@
+package domainruleservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
+	"github.com/rs/zerolog"
+)
+
+var testLogger = zerolog.Nop()
+
+func newTestService(c cluster, h historyStore) *Service {
+	return NewService(c, h, &fakePendingStore{}, &fakeExpirationStore{}, &fakeAuditor{}, testLogger)
+}
+
+func storeExpiration(domainName string, piholeNodeIds []int64) store.AddDomainRuleExpirationParams {
+	return store.AddDomainRuleExpirationParams{Type: "deny", Kind: "exact", Domain: domainName, PiholeNodeIds: piholeNodeIds, ExpiresAt: time.Now()}
+}
+
+type fakeAuditor struct {
+	mu     sync.Mutex
+	events []auditservice.Event
+}
+
+func (a *fakeAuditor) Record(_ context.Context, event auditservice.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+}
+
+// fakeHistoryStore keeps change sets in memory. addErr makes every AddChangeSet fail.
+type fakeHistoryStore struct {
+	changeSets []*domain.DomainRuleChangeSet
+	addErr     error
+	added      int
+	marked     map[int64]*int64
+}
+
+func (h *fakeHistoryStore) AddChangeSet(params store.AddDomainRuleChangeSetParams) (*domain.DomainRuleChangeSet, error) {
+	if h.addErr != nil {
+		return nil, h.addErr
+	}
+	h.added++
+	changeSet := &domain.DomainRuleChangeSet{
+		Id:        int64(len(h.changeSets) + 1),
+		Action:    params.Action,
+		RevertsId: params.RevertsId,
+		Changes:   params.Changes,
+		CreatedAt: time.Now(),
+	}
+	h.changeSets = append(h.changeSets, changeSet)
+	return changeSet, nil
+}
+
+func (h *fakeHistoryStore) GetChangeSet(id int64) (*domain.DomainRuleChangeSet, error) {
+	for _, changeSet := range h.changeSets {
+		if changeSet.Id == id {
+			return changeSet, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (h *fakeHistoryStore) GetChangeSets(limit, offset int) ([]*domain.DomainRuleChangeSet, int64, error) {
+	return h.changeSets, int64(len(h.changeSets)), nil
+}
+
+// GetUnrevertedChangeSetsSince returns the unreverted change sets newest first, like the real store.
+func (h *fakeHistoryStore) GetUnrevertedChangeSetsSince(since time.Time) ([]*domain.DomainRuleChangeSet, error) {
+	var out []*domain.DomainRuleChangeSet
+	for i := len(h.changeSets) - 1; i >= 0; i-- {
+		changeSet := h.changeSets[i]
+		if changeSet.RevertedAt == nil && !changeSet.CreatedAt.Before(since) {
+			out = append(out, changeSet)
+		}
+	}
+	return out, nil
+}
+
+func (h *fakeHistoryStore) MarkReverted(id int64, revertedById *int64) error {
+	if h.marked == nil {
+		h.marked = make(map[int64]*int64)
+	}
+	h.marked[id] = revertedById
+	now := time.Now()
+	for _, changeSet := range h.changeSets {
+		if changeSet.Id == id {
+			changeSet.RevertedAt = &now
+			changeSet.RevertedById = revertedById
+		}
+	}
+	return nil
+}
+
+func (h *fakeHistoryStore) ClearReverted(id int64) error {
+	for _, changeSet := range h.changeSets {
+		if changeSet.Id == id {
+			changeSet.RevertedAt, changeSet.RevertedById = nil, nil
+		}
+	}
+	return nil
+}
+
+type fakePendingStore struct {
+	ops []store.AddPendingDomainRuleOpParams
+}
+
+func (p *fakePendingStore) AddPendingOp(params store.AddPendingDomainRuleOpParams) (*domain.PendingDomainRuleOp, error) {
+	p.ops = append(p.ops, params)
+	return &domain.PendingDomainRuleOp{Id: int64(len(p.ops))}, nil
+}
+
+func (p *fakePendingStore) GetPendingOps(piholeId *int64) ([]*domain.PendingDomainRuleOp, error) {
+	return nil, nil
+}
+
+func (p *fakePendingStore) RecordAttempt(id int64, lastError string) error {
+	return nil
+}
+
+func (p *fakePendingStore) RemovePendingOp(id int64) (bool, error) {
+	return true, nil
+}
+
+type fakeExpirationStore struct {
+	expirations []*domain.DomainRuleExpiration
+}
+
+func (e *fakeExpirationStore) AddExpiration(params store.AddDomainRuleExpirationParams) (*domain.DomainRuleExpiration, error) {
+	expiration := &domain.DomainRuleExpiration{
+		Id:            int64(len(e.expirations) + 1),
+		Type:          params.Type,
+		Kind:          params.Kind,
+		Domain:        params.Domain,
+		PiholeNodeIds: params.PiholeNodeIds,
+		ExpiresAt:     params.ExpiresAt,
+	}
+	e.expirations = append(e.expirations, expiration)
+	return expiration, nil
+}
+
+func (e *fakeExpirationStore) GetExpirations() ([]*domain.DomainRuleExpiration, error) {
+	return e.expirations, nil
+}
+
+func (e *fakeExpirationStore) GetExpirationByRule(ruleType, kind, domainName string) (*domain.DomainRuleExpiration, error) {
+	for _, expiration := range e.expirations {
+		if expiration.Type == ruleType && expiration.Kind == kind && expiration.Domain == domainName {
+			return expiration, nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *fakeExpirationStore) SetExpirationNodes(id int64, piholeNodeIds []int64) error {
+	for _, expiration := range e.expirations {
+		if expiration.Id == id {
+			expiration.PiholeNodeIds = piholeNodeIds
+		}
+	}
+	return nil
+}
+
+func (e *fakeExpirationStore) RemoveExpiration(id int64) (bool, error) {
+	for i, expiration := range e.expirations {
+		if expiration.Id == id {
+			e.expirations = append(e.expirations[:i], e.expirations[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeCluster answers the per-node writes used by reverts and rollbacks and records what it was asked
+// to do. Methods a test does not set up panic through the nil embedded interface.
+type fakeCluster struct {
+	cluster
+	mu         sync.Mutex
+	calls      []string
+	nodes      []int64
+	rules      map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
+	groups     map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+	addFunc    func(opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	importFunc func(ids []int64, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	removeErr  map[int64]error
+}
+
+func (c *fakeCluster) record(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func (c *fakeCluster) GetDomainRulesByTypeKind(ctx context.Context, opts pihole.GetDomainRulesByTypeKindOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse] {
+	return c.rules
+}
+
+func (c *fakeCluster) GetDomainRulesByTypeKindDomain(ctx context.Context, opts pihole.GetDomainRulesByTypeKindDomainOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse] {
+	return c.rules
+}
+
+func (c *fakeCluster) GetGroups(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse] {
+	return c.groups
+}
+
+func (c *fakeCluster) GetAllDomainRules(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse] {
+	return c.rules
+}
+
+func (c *fakeCluster) AddDomainRule(ctx context.Context, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+	return c.addFunc(opts)
+}
+
+func (c *fakeCluster) ImportDomainRules(ctx context.Context, ids []int64, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+	return c.importFunc(ids, opts)
+}
+
+func (c *fakeCluster) AddDomainRuleToNode(ctx context.Context, id int64, opts pihole.AddDomainRuleOptions) *domain.NodeResult[pihole.AddDomainRuleResponse] {
+	c.record(fmt.Sprintf("%d add %s", id, opts.Payload.Domain))
+	return &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: &pihole.AddDomainRuleResponse{}}
+}
+
+func (c *fakeCluster) UpdateDomainRuleOnNode(ctx context.Context, id int64, opts pihole.UpdateDomainRuleOptions) *domain.NodeResult[pihole.UpdateDomainRuleResponse] {
+	c.record(fmt.Sprintf("%d update %s", id, opts.Domain))
+	return &domain.NodeResult[pihole.UpdateDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: &pihole.UpdateDomainRuleResponse{}}
+}
+
+func (c *fakeCluster) RemoveDomainRuleFromNode(ctx context.Context, id int64, opts pihole.RemoveDomainRuleOptions) *domain.NodeResult[pihole.RemoveDomainRuleResponse] {
+	c.record(fmt.Sprintf("%d remove %s", id, opts.Domain))
+	return &domain.NodeResult[pihole.RemoveDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true}
+}
+
+func (c *fakeCluster) RemoveDomainRuleFromNodes(ctx context.Context, ids []int64, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse] {
+	if ids == nil {
+		ids = c.nodes
+	}
+	results := make(map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse])
+	for _, id := range ids {
+		if !slices.Contains(c.nodes, id) {
+			continue
+		}
+		c.record(fmt.Sprintf("%d remove %s", id, opts.Domain))
+		err := c.removeErr[id]
+		results[id] = &domain.NodeResult[pihole.RemoveDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: err == nil, Error: err, ErrorString: util.ErrorString(err)}
+	}
+	return results
+}
@@ -0,0 +1,18 @@
+package domainruleservice
+
+import "sort"
+
+// operationGroups turns a source node's group ids into the names an operation sends to another node.
+// When the source node's groups are unknown the ids are sent as they are, which is the best guess left.
+func operationGroups(ids []int, names map[int]string) ([]int, []string) {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			return ids, nil
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return nil, out
+}
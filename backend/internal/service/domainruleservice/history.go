@@ -0,0 +1,457 @@
+package domainruleservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/sessions"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+func (s *Service) ListHistory(params HistoryListParams) (*HistoryList, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultHistoryLimit
+	}
+	if params.Limit > maxHistoryLimit {
+		params.Limit = maxHistoryLimit
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	changeSets, total, err := s.historyStore.GetChangeSets(params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryList{
+		ChangeSets: changeSets,
+		Total:      total,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	}, nil
+}
+
+func (s *Service) GetHistory(id int64) (*domain.DomainRuleChangeSet, error) {
+	changeSet, err := s.historyStore.GetChangeSet(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, httpx.NewHttpError(httpx.ErrNotFound, fmt.Sprintf("change set %d not found", id))
+	}
+	return changeSet, err
+}
+
+// Revert undoes a single change set by replaying its inverse operations on the nodes it touched.
+func (s *Service) Revert(ctx context.Context, id int64) (*RevertResult, error) {
+	s.revertMu.Lock()
+	defer s.revertMu.Unlock()
+
+	changeSet, err := s.GetHistory(id)
+	if err != nil {
+		return nil, err
+	}
+	if changeSet.RevertedAt != nil {
+		return nil, httpx.NewHttpError(httpx.ErrConflict, fmt.Sprintf("change set %d has already been reverted", id))
+	}
+	return s.revert(ctx, changeSet), nil
+}
+
+// RevertSince undoes every change set made at or after since, newest first, stopping at the first
+// change set that cannot be fully reverted.
+func (s *Service) RevertSince(ctx context.Context, since time.Time) (*RevertSinceResult, error) {
+	s.revertMu.Lock()
+	defer s.revertMu.Unlock()
+
+	result := &RevertSinceResult{Since: since, Reverts: []RevertResult{}, Complete: true}
+
+	// Reverting a revert puts its target back in effect, so the list is re-read after every step.
+	// Change sets newer than the starting point are the reverts made here and are never picked up.
+	var maxId int64 = -1
+	for {
+		changeSets, err := s.historyStore.GetUnrevertedChangeSetsSince(since)
+		if err != nil {
+			return result, err
+		}
+		if maxId < 0 {
+			if len(changeSets) == 0 {
+				return result, nil
+			}
+			maxId = changeSets[0].Id
+		}
+
+		var next *domain.DomainRuleChangeSet
+		for _, changeSet := range changeSets {
+			if changeSet.Id <= maxId {
+				next = changeSet
+				break
+			}
+		}
+		if next == nil {
+			return result, nil
+		}
+
+		revert := s.revert(ctx, next)
+		result.Reverts = append(result.Reverts, *revert)
+		if !revert.Complete {
+			result.Complete = false
+			return result, nil
+		}
+	}
+}
+
+func (s *Service) revert(ctx context.Context, changeSet *domain.DomainRuleChangeSet) *RevertResult {
+	// Each op remembers which inverse change it belongs to, so partial failures are recorded accurately
+	inverses := make([]domain.DomainRuleChange, 0, len(changeSet.Changes))
+	plansByNode := make(map[int64]*ReconcileNodePlan)
+	owners := make(map[int64][]int)
+	for i := len(changeSet.Changes) - 1; i >= 0; i-- {
+		change := changeSet.Changes[i]
+		inverse := domain.DomainRuleChange{
+			PiholeNode: change.PiholeNode,
+			Type:       change.Type,
+			Kind:       change.Kind,
+			Domain:     change.Domain,
+			Previous:   change.Current,
+			Current:    change.Previous,
+		}
+		ops := transitionOperations(inverse)
+		if len(ops) == 0 {
+			continue
+		}
+
+		id := change.PiholeNode.Id
+		nodePlan, ok := plansByNode[id]
+		if !ok {
+			nodePlan = &ReconcileNodePlan{PiholeNode: change.PiholeNode, Operations: []ReconcileOperation{}}
+			plansByNode[id] = nodePlan
+		}
+		for range ops {
+			owners[id] = append(owners[id], len(inverses))
+		}
+		nodePlan.Operations = append(nodePlan.Operations, ops...)
+		inverses = append(inverses, inverse)
+	}
+
+	plans := make([]ReconcileNodePlan, 0, len(plansByNode))
+	for _, nodePlan := range plansByNode {
+		plans = append(plans, *nodePlan)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].PiholeNode.Id < plans[j].PiholeNode.Id })
+
+	results := s.applyPlans(ctx, plans)
+
+	failed := make([]bool, len(inverses))
+	for id, nr := range results {
+		if nr.Response == nil {
+			continue
+		}
+		for i, op := range nr.Response.Operations {
+			if !op.Success {
+				failed[owners[id][i]] = true
+			}
+		}
+	}
+	applied := make([]domain.DomainRuleChange, 0, len(inverses))
+	for i, inverse := range inverses {
+		if !failed[i] {
+			applied = append(applied, inverse)
+		}
+	}
+
+	result := &RevertResult{
+		Reverted: changeSet,
+		Complete: len(applied) == len(inverses),
+		Results:  results,
+	}
+	if len(applied) > 0 {
+		revertsId := changeSet.Id
+		result.ChangeSet = s.recordHistory(ctx, HistoryActionRevert, &revertsId, applied)
+		if result.ChangeSet == nil {
+			// Left unmarked, the change set would be picked up and reverted again by RevertSince
+			result.Complete = false
+			result.Error = "the revert was applied but could not be recorded"
+		}
+	}
+
+	// A change set with nothing to undo is marked reverted too, without a change set of its own
+	if result.Complete {
+		var revertedById *int64
+		if result.ChangeSet != nil {
+			revertedById = &result.ChangeSet.Id
+		}
+		if err := s.historyStore.MarkReverted(changeSet.Id, revertedById); err != nil {
+			s.logger.Error().Err(err).Int64("id", changeSet.Id).Msg("error marking change set as reverted")
+			result.Complete = false
+			result.Error = "the change set could not be marked as reverted"
+		} else if changeSet.RevertsId != nil {
+			// Undoing a revert puts the change set it reverted back in effect
+			if err := s.historyStore.ClearReverted(*changeSet.RevertsId); err != nil {
+				s.logger.Error().Err(err).Int64("id", *changeSet.RevertsId).Msg("error clearing reverted change set")
+			}
+		}
+	}
+
+	outcomes := auditservice.NodeOutcomes(results)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionDomainRuleRevert,
+		Target:       fmt.Sprintf("%d", changeSet.Id),
+		Details:      revertAuditDetails{ChangeSetId: changeSet.Id, Changes: len(inverses), Applied: len(applied)},
+		NodeOutcomes: outcomes,
+		Success:      result.Complete,
+	})
+
+	return result
+}
+
+// transitionOperations returns the operations that move a rule from its previous to its current state.
+func transitionOperations(change domain.DomainRuleChange) []ReconcileOperation {
+	if change.Previous != nil && change.Current != nil {
+		enabled := change.Current.Enabled
+		comment := nodemerge.CommentValue(change.Current.Comment)
+		groups := append([]int{}, change.Current.Groups...)
+		return []ReconcileOperation{{
+			Action:  ReconcileActionUpdate,
+			Type:    change.Type,
+			Kind:    change.Kind,
+			Domain:  change.Domain,
+			Comment: &comment,
+			Groups:  groups,
+			Enabled: &enabled,
+		}}
+	}
+
+	var ops []ReconcileOperation
+	if change.Previous != nil {
+		ops = append(ops, ReconcileOperation{
+			Action: ReconcileActionRemove,
+			Type:   change.Type,
+			Kind:   change.Kind,
+			Domain: change.Domain,
+		})
+	}
+	if change.Current != nil {
+		enabled := change.Current.Enabled
+		ops = append(ops, ReconcileOperation{
+			Action:  ReconcileActionAdd,
+			Type:    change.Type,
+			Kind:    change.Kind,
+			Domain:  change.Domain,
+			Comment: change.Current.Comment,
+			Groups:  change.Current.Groups,
+			Enabled: &enabled,
+		})
+	}
+	return ops
+}
+
+// recordHistory stores a change set. Like the audit log, a failure to record never fails the change
+// itself, so errors are only logged.
+func (s *Service) recordHistory(ctx context.Context, action string, revertsId *int64, changes []domain.DomainRuleChange) *domain.DomainRuleChangeSet {
+	if len(changes) == 0 {
+		return nil
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].PiholeNode.Id < changes[j].PiholeNode.Id })
+
+	params := store.AddDomainRuleChangeSetParams{
+		Action:    action,
+		RevertsId: revertsId,
+		Changes:   changes,
+	}
+	if userId, ok := ctx.Value(sessions.UserIdContextKey).(int64); ok {
+		params.UserId = &userId
+	}
+
+	changeSet, err := s.historyStore.AddChangeSet(params)
+	if err != nil {
+		s.logger.Error().Err(err).Str("action", action).Msg("error recording domain rule change set")
+		return nil
+	}
+	return changeSet
+}
+
+// snapshot indexes the rules each reachable node has, for capturing state before a change.
+func snapshot(results map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]) map[int64]map[ruleKey]pihole.DomainInfo {
+	_, _, rulesByNode := collectNodeRules(results)
+	return rulesByNode
+}
+
+func addChanges(opts pihole.AddDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], before map[int64]map[ruleKey]pihole.DomainInfo) []domain.DomainRuleChange {
+	var changes []domain.DomainRuleChange
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		echoed := make(map[string]pihole.DomainInfo)
+		if nr.Response != nil {
+			for _, rule := range nr.Response.Domains {
+				echoed[rule.Domain] = rule
+			}
+		}
+		for _, d := range addedDomains(opts.Payload, nr.Response) {
+			change := domain.DomainRuleChange{
+				PiholeNode: nr.PiholeNode,
+				Type:       string(opts.Type),
+				Kind:       string(opts.Kind),
+				Domain:     d,
+				Current:    payloadState(opts.Payload),
+			}
+			if rule, ok := echoed[d]; ok {
+				change.Current = ruleState(rule)
+			}
+			if rule, ok := before[id][ruleKey{Type: change.Type, Kind: change.Kind, Domain: d}]; ok {
+				change.Previous = ruleState(rule)
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func updateChanges(opts pihole.UpdateDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.UpdateDomainRuleResponse], before map[int64]map[ruleKey]pihole.DomainInfo) []domain.DomainRuleChange {
+	key := ruleKey{Type: string(opts.Type), Kind: string(opts.Kind), Domain: opts.Domain}
+	var changes []domain.DomainRuleChange
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		rule, ok := before[id][key]
+		if !ok {
+			continue
+		}
+		current := patchedState(ruleState(rule), opts.Payload)
+		if nr.Response != nil && len(nr.Response.Domains) > 0 {
+			current = ruleState(nr.Response.Domains[0])
+		}
+		changes = append(changes, domain.DomainRuleChange{
+			PiholeNode: nr.PiholeNode,
+			Type:       key.Type,
+			Kind:       key.Kind,
+			Domain:     key.Domain,
+			Previous:   ruleState(rule),
+			Current:    current,
+		})
+	}
+	return changes
+}
+
+func removeChanges(opts pihole.RemoveDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse], before map[int64]map[ruleKey]pihole.DomainInfo) []domain.DomainRuleChange {
+	key := ruleKey{Type: string(opts.Type), Kind: string(opts.Kind), Domain: opts.Domain}
+	var changes []domain.DomainRuleChange
+	for id, nr := range results {
+		if !nr.Success {
+			continue
+		}
+		// Without the previous state there is nothing a revert could restore
+		rule, ok := before[id][key]
+		if !ok {
+			continue
+		}
+		changes = append(changes, domain.DomainRuleChange{
+			PiholeNode: nr.PiholeNode,
+			Type:       key.Type,
+			Kind:       key.Kind,
+			Domain:     key.Domain,
+			Previous:   ruleState(rule),
+		})
+	}
+	return changes
+}
+
+func planChanges(results map[int64]*domain.NodeResult[ReconcileNodeResponse], before map[int64]map[ruleKey]pihole.DomainInfo) []domain.DomainRuleChange {
+	var changes []domain.DomainRuleChange
+	for id, nr := range results {
+		if nr.Response == nil {
+			continue
+		}
+		for _, op := range nr.Response.Operations {
+			if !op.Success {
+				continue
+			}
+			change := domain.DomainRuleChange{
+				PiholeNode: nr.PiholeNode,
+				Type:       op.Type,
+				Kind:       op.Kind,
+				Domain:     op.Domain,
+			}
+			if rule, ok := before[id][ruleKey{Type: op.Type, Kind: op.Kind, Domain: op.Domain}]; ok {
+				change.Previous = ruleState(rule)
+			}
+			switch {
+			case op.Action == ReconcileActionRemove:
+			case op.current != nil:
+				// The node's own view has group names resolved to its ids
+				change.Current = ruleState(*op.current)
+			case op.Action == ReconcileActionAdd:
+				change.Current = payloadState(pihole.AddDomainPayload{Comment: op.Comment, Groups: op.Groups, Enabled: op.Enabled})
+			case op.Action == ReconcileActionUpdate:
+				if change.Previous == nil || op.GroupNames != nil {
+					// Without the previous state, or the ids the names resolved to, the result is unknown
+					continue
+				}
+				change.Current = patchedState(change.Previous, pihole.UpdateDomainPayload{Comment: op.Comment, Groups: op.Groups, Enabled: op.Enabled})
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// remainingChanges drops the changes on nodes where an atomic rollback succeeded.
+func remainingChanges(changes []domain.DomainRuleChange, rollback map[int64]*domain.NodeResult[ReconcileNodeResponse]) []domain.DomainRuleChange {
+	var remaining []domain.DomainRuleChange
+	for _, change := range changes {
+		if nr, ok := rollback[change.PiholeNode.Id]; ok && nr.Success {
+			continue
+		}
+		remaining = append(remaining, change)
+	}
+	return remaining
+}
+
+func ruleState(rule pihole.DomainInfo) *domain.DomainRuleState {
+	return &domain.DomainRuleState{
+		Comment: rule.Comment,
+		Groups:  nodemerge.SortedGroups(rule.Groups),
+		Enabled: rule.Enabled,
+	}
+}
+
+// patchedState is the state a partial update leaves behind.
+func patchedState(state *domain.DomainRuleState, payload pihole.UpdateDomainPayload) *domain.DomainRuleState {
+	patched := *state
+	if payload.Comment != nil {
+		patched.Comment = payload.Comment
+	}
+	if payload.Groups != nil {
+		patched.Groups = nodemerge.SortedGroups(payload.Groups)
+	}
+	if payload.Enabled != nil {
+		patched.Enabled = *payload.Enabled
+	}
+	return &patched
+}
+
+// payloadState is the state an add leaves behind, used when Pi-hole does not echo the rule back.
+func payloadState(payload pihole.AddDomainPayload) *domain.DomainRuleState {
+	enabled := true
+	if payload.Enabled != nil {
+		enabled = *payload.Enabled
+	}
+	return &domain.DomainRuleState{
+		Comment: payload.Comment,
+		Groups:  nodemerge.SortedGroups(payload.Groups),
+		Enabled: enabled,
+	}
+}
@@ -0,0 +1,92 @@
+package domainruleservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+)
+
+func TestRevertSinceTerminates(t *testing.T) {
+	node := domain.PiholeNodeRef{Id: 1, Name: "node1"}
+	added := domain.DomainRuleChange{PiholeNode: node, Type: "deny", Kind: "exact", Domain: "example.com", Current: &domain.DomainRuleState{Enabled: true}}
+	noop := domain.DomainRuleChange{PiholeNode: node, Type: "deny", Kind: "exact", Domain: "example.com"}
+
+	tests := []struct {
+		name         string
+		change       domain.DomainRuleChange
+		addErr       error
+		wantComplete bool
+		wantMarked   bool
+		wantCalls    int
+	}{
+		{
+			name:         "nothing to undo is marked reverted",
+			change:       noop,
+			wantComplete: true,
+			wantMarked:   true,
+			wantCalls:    0,
+		},
+		{
+			name:         "unrecorded revert stops instead of repeating",
+			change:       added,
+			addErr:       errors.New("disk full"),
+			wantComplete: false,
+			wantMarked:   false,
+			wantCalls:    1,
+		},
+		{
+			name:         "recorded revert is marked",
+			change:       added,
+			wantComplete: true,
+			wantMarked:   true,
+			wantCalls:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since := time.Now().Add(-time.Minute)
+			history := &fakeHistoryStore{changeSets: []*domain.DomainRuleChangeSet{
+				{Id: 1, Action: HistoryActionAdd, Changes: []domain.DomainRuleChange{tt.change}, CreatedAt: time.Now()},
+			}}
+			history.addErr = tt.addErr
+			c := &fakeCluster{}
+			s := newTestService(c, history)
+
+			done := make(chan *RevertSinceResult, 1)
+			go func() {
+				result, err := s.RevertSince(context.Background(), since)
+				if err != nil {
+					t.Errorf("RevertSince: %v", err)
+				}
+				done <- result
+			}()
+
+			var result *RevertSinceResult
+			select {
+			case result = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("RevertSince did not return")
+			}
+
+			if result.Complete != tt.wantComplete {
+				t.Errorf("Complete = %v, want %v", result.Complete, tt.wantComplete)
+			}
+			if len(result.Reverts) != 1 {
+				t.Fatalf("got %d reverts, want 1", len(result.Reverts))
+			}
+			if !tt.wantComplete && result.Reverts[0].Error == "" {
+				t.Error("incomplete revert has no error")
+			}
+			if _, marked := history.marked[1]; marked != tt.wantMarked {
+				t.Errorf("marked = %v, want %v", marked, tt.wantMarked)
+			}
+			if len(c.calls) != tt.wantCalls {
+				t.Errorf("got node calls %v, want %d", c.calls, tt.wantCalls)
+			}
+		})
+	}
+}
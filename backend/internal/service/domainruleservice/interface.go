@@ -2,18 +2,57 @@ package domainruleservice
 
 import (
 	"context"
+	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 )
 
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type historyStore interface {
+	AddChangeSet(params store.AddDomainRuleChangeSetParams) (*domain.DomainRuleChangeSet, error)
+	GetChangeSet(id int64) (*domain.DomainRuleChangeSet, error)
+	GetChangeSets(limit, offset int) ([]*domain.DomainRuleChangeSet, int64, error)
+	GetUnrevertedChangeSetsSince(since time.Time) ([]*domain.DomainRuleChangeSet, error)
+	MarkReverted(id int64, revertedById *int64) error
+	ClearReverted(id int64) error
+}
+
+type pendingStore interface {
+	AddPendingOp(params store.AddPendingDomainRuleOpParams) (*domain.PendingDomainRuleOp, error)
+	GetPendingOps(piholeId *int64) ([]*domain.PendingDomainRuleOp, error)
+	RecordAttempt(id int64, lastError string) error
+	RemovePendingOp(id int64) (bool, error)
+}
+
+type expirationStore interface {
+	AddExpiration(params store.AddDomainRuleExpirationParams) (*domain.DomainRuleExpiration, error)
+	GetExpirations() ([]*domain.DomainRuleExpiration, error)
+	RemoveExpiration(id int64) (bool, error)
+	GetExpirationByRule(ruleType, kind, domainName string) (*domain.DomainRuleExpiration, error)
+	SetExpirationNodes(id int64, piholeNodeIds []int64) error
+}
+
 type cluster interface {
 	GetAllDomainRules(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
+	GetGroups(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
 	GetDomainRulesByType(ctx context.Context, opts pihole.GetDomainRulesByTypeOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetDomainRulesByKind(ctx context.Context, opts pihole.GetDomainRulesByKindOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetDomainRulesByDomain(ctx context.Context, opts pihole.GetDomainRulesByDomainOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetDomainRulesByTypeKind(ctx context.Context, opts pihole.GetDomainRulesByTypeKindOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	GetDomainRulesByTypeKindDomain(ctx context.Context, opts pihole.GetDomainRulesByTypeKindDomainOptions) map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]
 	AddDomainRule(ctx context.Context, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	ImportDomainRules(ctx context.Context, ids []int64, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]
+	UpdateDomainRule(ctx context.Context, opts pihole.UpdateDomainRuleOptions) map[int64]*domain.NodeResult[pihole.UpdateDomainRuleResponse]
 	RemoveDomainRule(ctx context.Context, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]
+	RemoveDomainRuleFromNodes(ctx context.Context, ids []int64, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]
+	AddDomainRuleToNode(ctx context.Context, id int64, opts pihole.AddDomainRuleOptions) *domain.NodeResult[pihole.AddDomainRuleResponse]
+	UpdateDomainRuleOnNode(ctx context.Context, id int64, opts pihole.UpdateDomainRuleOptions) *domain.NodeResult[pihole.UpdateDomainRuleResponse]
+	RemoveDomainRuleFromNode(ctx context.Context, id int64, opts pihole.RemoveDomainRuleOptions) *domain.NodeResult[pihole.RemoveDomainRuleResponse]
+	FetchQueryLogs(ctx context.Context, req pihole.FetchQueryLogClusterRequest) (*pihole.FetchQueryLogsClusterResponse, error)
 }
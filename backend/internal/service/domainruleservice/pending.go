@@ -0,0 +1,285 @@
+package domainruleservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
+)
+
+func (s *Service) GetPending(piholeId *int64) ([]*domain.PendingDomainRuleOp, error) {
+	return s.pendingStore.GetPendingOps(piholeId)
+}
+
+func (s *Service) DiscardPending(id int64) error {
+	found, err := s.pendingStore.RemovePendingOp(id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return httpx.NewHttpError(httpx.ErrNotFound, fmt.Sprintf("pending operation %d not found", id))
+	}
+	return nil
+}
+
+// HandleNodeOnline is called by the health service when a node comes back online.
+func (s *Service) HandleNodeOnline(ctx context.Context, id int64) {
+	if _, err := s.ReplayPending(ctx, id); err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("error replaying pending domain rule operations")
+	}
+}
+
+// ReplayPending applies a node's queued operations in order. Replay stops at the first operation that
+// still cannot reach the node, leaving it and the rest queued for the next recovery. Operations the
+// node rejects are dropped, since retrying them would never succeed.
+func (s *Service) ReplayPending(ctx context.Context, id int64) (*domain.NodeResult[ReconcileNodeResponse], error) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	ops, err := s.pendingStore.GetPendingOps(&id)
+	if err != nil || len(ops) == 0 {
+		return nil, err
+	}
+
+	logger := s.logger.With().Int64("id", id).Int("pending", len(ops)).Logger()
+	logger.Info().Msg("replaying pending domain rule operations")
+
+	nodeResult := &domain.NodeResult[ReconcileNodeResponse]{
+		PiholeNode: domain.PiholeNodeRef{Id: id},
+		Response:   &ReconcileNodeResponse{Operations: []ReconcileOperationResult{}},
+	}
+	failed := 0
+	for _, op := range ops {
+		operation := pendingOperation(op)
+		node, current, opErr := s.applyOperation(ctx, id, operation)
+		if node.Id != 0 {
+			nodeResult.PiholeNode = node
+		}
+
+		if pihole.IsUnreachable(opErr) {
+			if err := s.pendingStore.RecordAttempt(op.Id, opErr.Error()); err != nil {
+				logger.Error().Err(err).Int64("op_id", op.Id).Msg("error recording pending operation attempt")
+			}
+			logger.Warn().Err(opErr).Msg("node unreachable again, stopping replay")
+			break
+		}
+
+		if _, err := s.pendingStore.RemovePendingOp(op.Id); err != nil {
+			logger.Error().Err(err).Int64("op_id", op.Id).Msg("error removing replayed pending operation")
+		}
+		if opErr != nil {
+			failed++
+			logger.Warn().Err(opErr).Int64("op_id", op.Id).Str("domain", op.Domain).Msg("node rejected pending operation, dropping it")
+		}
+		nodeResult.Response.Operations = append(nodeResult.Response.Operations, ReconcileOperationResult{
+			ReconcileOperation: operation,
+			Success:            opErr == nil,
+			Error:              util.ErrorString(opErr),
+			current:            current,
+		})
+	}
+
+	remaining := len(ops) - len(nodeResult.Response.Operations)
+	nodeResult.Success = failed == 0 && remaining == 0
+	if !nodeResult.Success {
+		nodeResult.Error = fmt.Errorf("%d operations rejected, %d still pending", failed, remaining)
+		nodeResult.ErrorString = nodeResult.Error.Error()
+	}
+
+	if len(nodeResult.Response.Operations) > 0 {
+		results := map[int64]*domain.NodeResult[ReconcileNodeResponse]{id: nodeResult}
+		s.recordHistory(ctx, HistoryActionReplay, nil, planChanges(results, nil))
+		outcomes := auditservice.NodeOutcomes(results)
+		s.auditor.Record(ctx, auditservice.Event{
+			Action:       auditservice.ActionDomainRuleReplay,
+			Target:       nodeResult.PiholeNode.Name,
+			Details:      replayAuditDetails{Replayed: len(nodeResult.Response.Operations), Rejected: failed, Remaining: remaining},
+			NodeOutcomes: outcomes,
+			Success:      nodeResult.Success,
+		})
+	}
+
+	return nodeResult, nil
+}
+
+// applyOperation runs a single operation against a node, folding per-item add errors into the error.
+// For adds and updates it also returns the rule as the node reports it afterwards, if it did.
+func (s *Service) applyOperation(ctx context.Context, id int64, op ReconcileOperation) (domain.PiholeNodeRef, *pihole.DomainInfo, error) {
+	ruleType, _ := pihole.ParseRuleType(op.Type)
+	ruleKind, _ := pihole.ParseRuleKind(op.Kind)
+
+	switch op.Action {
+	case ReconcileActionRemove:
+		r := s.cluster.RemoveDomainRuleFromNode(ctx, id, pihole.RemoveDomainRuleOptions{Type: ruleType, Kind: ruleKind, Domain: op.Domain})
+		return r.PiholeNode, nil, r.Error
+	case ReconcileActionUpdate:
+		r := s.cluster.UpdateDomainRuleOnNode(ctx, id, pihole.UpdateDomainRuleOptions{
+			Type:   ruleType,
+			Kind:   ruleKind,
+			Domain: op.Domain,
+			Payload: pihole.UpdateDomainPayload{
+				Comment:    op.Comment,
+				Groups:     op.Groups,
+				GroupNames: op.GroupNames,
+				Enabled:    op.Enabled,
+			},
+		})
+		if r.Error != nil {
+			return r.PiholeNode, nil, r.Error
+		}
+		if e := processedError(r.Response.Processed); e != "" {
+			return r.PiholeNode, nil, fmt.Errorf("%s", e)
+		}
+		return r.PiholeNode, echoedRule(r.Response.Domains, op.Domain), nil
+	default:
+		r := s.cluster.AddDomainRuleToNode(ctx, id, pihole.AddDomainRuleOptions{
+			Type: ruleType,
+			Kind: ruleKind,
+			Payload: pihole.AddDomainPayload{
+				Domain:     op.Domain,
+				Comment:    op.Comment,
+				Groups:     op.Groups,
+				GroupNames: op.GroupNames,
+				Enabled:    op.Enabled,
+			},
+		})
+		if r.Error != nil {
+			return r.PiholeNode, nil, r.Error
+		}
+		if e := processedError(r.Response.Processed); e != "" {
+			return r.PiholeNode, nil, fmt.Errorf("%s", e)
+		}
+		return r.PiholeNode, echoedRule(r.Response.Domains, op.Domain), nil
+	}
+}
+
+func echoedRule(rules []pihole.DomainInfo, domainName string) *pihole.DomainInfo {
+	for _, rule := range rules {
+		if rule.Domain == domainName {
+			return &rule
+		}
+	}
+	return nil
+}
+
+// queueUnreachableAdds queues the add for every node that could not be reached.
+func (s *Service) queueUnreachableAdds(opts pihole.AddDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse]) {
+	for id, nr := range results {
+		if nr.Success || !pihole.IsUnreachable(nr.Error) {
+			continue
+		}
+		for _, d := range payloadDomains(opts.Payload) {
+			s.queue(store.AddPendingDomainRuleOpParams{
+				PiholeId:   id,
+				Action:     string(ReconcileActionAdd),
+				Type:       string(opts.Type),
+				Kind:       string(opts.Kind),
+				Domain:     d,
+				Comment:    opts.Payload.Comment,
+				Groups:     opts.Payload.Groups,
+				GroupNames: opts.Payload.GroupNames,
+				Enabled:    opts.Payload.Enabled,
+				LastError:  nr.ErrorString,
+			})
+		}
+	}
+}
+
+// queueUnreachableRemoves queues the remove for every node that could not be reached.
+func (s *Service) queueUnreachableRemoves(opts pihole.RemoveDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]) {
+	for id, nr := range results {
+		if nr.Success || !pihole.IsUnreachable(nr.Error) {
+			continue
+		}
+		s.queue(store.AddPendingDomainRuleOpParams{
+			PiholeId:  id,
+			Action:    string(ReconcileActionRemove),
+			Type:      string(opts.Type),
+			Kind:      string(opts.Kind),
+			Domain:    opts.Domain,
+			LastError: nr.ErrorString,
+		})
+	}
+}
+
+// queueUnreachableUpdates queues the update for every node that could not be reached.
+func (s *Service) queueUnreachableUpdates(opts pihole.UpdateDomainRuleOptions, results map[int64]*domain.NodeResult[pihole.UpdateDomainRuleResponse]) {
+	for id, nr := range results {
+		if nr.Success || !pihole.IsUnreachable(nr.Error) {
+			continue
+		}
+		s.queue(store.AddPendingDomainRuleOpParams{
+			PiholeId:   id,
+			Action:     string(ReconcileActionUpdate),
+			Type:       string(opts.Type),
+			Kind:       string(opts.Kind),
+			Domain:     opts.Domain,
+			Comment:    opts.Payload.Comment,
+			Groups:     opts.Payload.Groups,
+			GroupNames: opts.Payload.GroupNames,
+			Enabled:    opts.Payload.Enabled,
+			LastError:  nr.ErrorString,
+		})
+	}
+}
+
+func (s *Service) queue(params store.AddPendingDomainRuleOpParams) {
+	// An update only patches a rule, so it is folded into whatever is already queued for that rule
+	if params.Action == string(ReconcileActionUpdate) {
+		existing, err := s.pendingStore.GetPendingOps(&params.PiholeId)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("id", params.PiholeId).Msg("error getting pending domain rule operations")
+			return
+		}
+		for _, op := range existing {
+			if op.Type != params.Type || op.Kind != params.Kind || op.Domain != params.Domain {
+				continue
+			}
+			if op.Action == string(ReconcileActionRemove) {
+				// The rule is going away on this node anyway
+				return
+			}
+			params = mergePending(op, params)
+		}
+	}
+
+	if _, err := s.pendingStore.AddPendingOp(params); err != nil {
+		s.logger.Error().Err(err).Int64("id", params.PiholeId).Str("domain", params.Domain).Msg("error queueing pending domain rule operation")
+		return
+	}
+	s.logger.Info().Int64("id", params.PiholeId).Str("action", params.Action).Str("domain", params.Domain).Msg("node unreachable, queued domain rule operation for replay")
+}
+
+// mergePending applies an update on top of an already queued add or update.
+func mergePending(existing *domain.PendingDomainRuleOp, update store.AddPendingDomainRuleOpParams) store.AddPendingDomainRuleOpParams {
+	merged := update
+	merged.Action = existing.Action
+	if merged.Comment == nil {
+		merged.Comment = existing.Comment
+	}
+	if merged.Groups == nil && merged.GroupNames == nil {
+		merged.Groups, merged.GroupNames = existing.Groups, existing.GroupNames
+	}
+	if merged.Enabled == nil {
+		merged.Enabled = existing.Enabled
+	}
+	return merged
+}
+
+func pendingOperation(op *domain.PendingDomainRuleOp) ReconcileOperation {
+	return ReconcileOperation{
+		Action:     ReconcileAction(op.Action),
+		Type:       op.Type,
+		Kind:       op.Kind,
+		Domain:     op.Domain,
+		Comment:    op.Comment,
+		Groups:     op.Groups,
+		GroupNames: op.GroupNames,
+		Enabled:    op.Enabled,
+	}
+}
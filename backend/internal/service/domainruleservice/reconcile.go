@@ -0,0 +1,249 @@
+package domainruleservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
+)
+
+// desiredRule is a rule the plan wants on every node, with the node it was taken from, whose group ids
+// it carries.
+type desiredRule struct {
+	rule   pihole.DomainInfo
+	nodeId int64
+}
+
+// buildReconcilePlan works out the adds, updates and removes needed to bring every reachable node in
+// line with the desired rule set. Unreachable nodes are left out of the plan. Groups are sent by name,
+// so each node resolves them to its own ids.
+func buildReconcilePlan(params ReconcileParams, results map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse], groupNames map[int64]map[int]string) (ReconcilePlan, error) {
+	nodes, unreachable, rulesByNode := collectNodeRules(results)
+	plan := ReconcilePlan{
+		Source:           params.Source,
+		NodeId:           params.NodeId,
+		Nodes:            []ReconcileNodePlan{},
+		UnreachableNodes: unreachable,
+	}
+
+	desired := make(map[ruleKey]desiredRule)
+	switch params.Source {
+	case ReconcileSourceNode:
+		if params.NodeId == nil {
+			return plan, httpx.NewHttpError(httpx.ErrValidation, "nodeId is required when source is \"node\"")
+		}
+		rules, ok := rulesByNode[*params.NodeId]
+		if !ok {
+			return plan, httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("reference node %d is unknown or unreachable", *params.NodeId))
+		}
+		for key, rule := range rules {
+			desired[key] = desiredRule{rule: rule, nodeId: *params.NodeId}
+		}
+
+	case ReconcileSourceUnion:
+		// Nodes are sorted by id, so the lowest id node wins when nodes disagree on a rule's attributes.
+		for _, node := range nodes {
+			for key, rule := range rulesByNode[node.Id] {
+				if _, ok := desired[key]; !ok {
+					desired[key] = desiredRule{rule: rule, nodeId: node.Id}
+				}
+			}
+		}
+
+	case ReconcileSourceIntersection:
+		for _, node := range nodes {
+			for key, rule := range rulesByNode[node.Id] {
+				if _, ok := desired[key]; ok {
+					continue
+				}
+				onAll := true
+				for _, other := range nodes {
+					if _, ok := rulesByNode[other.Id][key]; !ok {
+						onAll = false
+						break
+					}
+				}
+				if onAll {
+					desired[key] = desiredRule{rule: rule, nodeId: node.Id}
+				}
+			}
+		}
+
+	default:
+		return plan, httpx.NewHttpError(httpx.ErrValidation, "source must be one of node, union, intersection")
+	}
+
+	for _, node := range nodes {
+		if params.Source == ReconcileSourceNode && node.Id == *params.NodeId {
+			continue
+		}
+		current := rulesByNode[node.Id]
+		nodePlan := ReconcileNodePlan{PiholeNode: node, Operations: []ReconcileOperation{}}
+
+		var removes, updates, adds []ruleKey
+		for key, rule := range current {
+			want, ok := desired[key]
+			switch {
+			case !ok:
+				removes = append(removes, key)
+			case len(ruleConflicts(want, desiredRule{rule: rule, nodeId: node.Id}, groupNames)) > 0:
+				updates = append(updates, key)
+			}
+		}
+		for key := range desired {
+			if _, ok := current[key]; !ok {
+				adds = append(adds, key)
+			}
+		}
+		sort.Slice(removes, func(i, j int) bool { return ruleKeyLess(removes[i], removes[j]) })
+		sort.Slice(updates, func(i, j int) bool { return ruleKeyLess(updates[i], updates[j]) })
+		sort.Slice(adds, func(i, j int) bool { return ruleKeyLess(adds[i], adds[j]) })
+
+		// Removes go first so that a rule moving between type/kind never exists twice on a node.
+		for _, key := range removes {
+			nodePlan.Operations = append(nodePlan.Operations, ReconcileOperation{
+				Action: ReconcileActionRemove,
+				Type:   key.Type,
+				Kind:   key.Kind,
+				Domain: key.Domain,
+			})
+		}
+		for _, key := range updates {
+			nodePlan.Operations = append(nodePlan.Operations, updateOperation(key, desired[key], desiredRule{rule: current[key], nodeId: node.Id}, groupNames))
+		}
+		for _, key := range adds {
+			want := desired[key]
+			enabled := want.rule.Enabled
+			groups, names := operationGroups(want.rule.Groups, groupNames[want.nodeId])
+			nodePlan.Operations = append(nodePlan.Operations, ReconcileOperation{
+				Action:     ReconcileActionAdd,
+				Type:       key.Type,
+				Kind:       key.Kind,
+				Domain:     key.Domain,
+				Comment:    want.rule.Comment,
+				Groups:     groups,
+				GroupNames: names,
+				Enabled:    &enabled,
+			})
+		}
+
+		if len(nodePlan.Operations) > 0 {
+			plan.Nodes = append(plan.Nodes, nodePlan)
+		}
+	}
+
+	return plan, nil
+}
+
+// ruleConflicts compares a rule on a node with the desired one, the same way drift does.
+func ruleConflicts(want, have desiredRule, groupNames map[int64]map[int]string) []string {
+	states := []RuleNodeState{
+		{Enabled: want.rule.Enabled, Comment: want.rule.Comment, GroupNames: nodemerge.NamesFor(want.rule.Groups, groupNames[want.nodeId])},
+		{Enabled: have.rule.Enabled, Comment: have.rule.Comment, GroupNames: nodemerge.NamesFor(have.rule.Groups, groupNames[have.nodeId])},
+	}
+	return findConflicts(states)
+}
+
+// updateOperation patches only the fields of a node's rule that differ from the desired rule.
+func updateOperation(key ruleKey, want, have desiredRule, groupNames map[int64]map[int]string) ReconcileOperation {
+	op := ReconcileOperation{Action: ReconcileActionUpdate, Type: key.Type, Kind: key.Kind, Domain: key.Domain}
+	for _, conflict := range ruleConflicts(want, have, groupNames) {
+		switch conflict {
+		case "enabled":
+			enabled := want.rule.Enabled
+			op.Enabled = &enabled
+		case "comment":
+			// An empty comment clears the node's, where nil would leave it alone
+			comment := nodemerge.CommentValue(want.rule.Comment)
+			op.Comment = &comment
+		case "groups":
+			op.Groups, op.GroupNames = operationGroups(want.rule.Groups, groupNames[want.nodeId])
+		}
+	}
+	return op
+}
+
+func (s *Service) Reconcile(ctx context.Context, params ReconcileParams) (*ReconcileResult, error) {
+	rules := s.cluster.GetAllDomainRules(ctx)
+	plan, err := buildReconcilePlan(params, rules, nodemerge.GroupNames(s.cluster.GetGroups(ctx)))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{DryRun: params.DryRun, Plan: plan}
+	if params.DryRun {
+		return result, nil
+	}
+
+	result.Results = s.applyPlans(ctx, plan.Nodes)
+	s.auditReconcile(ctx, result)
+	s.recordHistory(ctx, HistoryActionReconcile, nil, planChanges(result.Results, snapshot(rules)))
+	return result, nil
+}
+
+// applyPlans applies each node's plan concurrently.
+func (s *Service) applyPlans(ctx context.Context, plans []ReconcileNodePlan) map[int64]*domain.NodeResult[ReconcileNodeResponse] {
+	results := make(map[int64]*domain.NodeResult[ReconcileNodeResponse], len(plans))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nodePlan := range plans {
+		nodePlan := nodePlan
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nodeResult := s.applyNodePlan(ctx, nodePlan)
+			mu.Lock()
+			results[nodePlan.PiholeNode.Id] = nodeResult
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// applyNodePlan runs a node's operations in order and keeps going after a failure, so that one bad
+// rule does not block the rest of the reconcile.
+func (s *Service) applyNodePlan(ctx context.Context, nodePlan ReconcileNodePlan) *domain.NodeResult[ReconcileNodeResponse] {
+	id := nodePlan.PiholeNode.Id
+	response := ReconcileNodeResponse{Operations: make([]ReconcileOperationResult, 0, len(nodePlan.Operations))}
+	failed := 0
+
+	for _, op := range nodePlan.Operations {
+		_, current, err := s.applyOperation(ctx, id, op)
+		if err != nil {
+			failed++
+		}
+		response.Operations = append(response.Operations, ReconcileOperationResult{
+			ReconcileOperation: op,
+			Success:            err == nil,
+			Error:              util.ErrorString(err),
+			current:            current,
+		})
+	}
+
+	nodeResult := &domain.NodeResult[ReconcileNodeResponse]{
+		PiholeNode: nodePlan.PiholeNode,
+		Success:    failed == 0,
+		Response:   &response,
+	}
+	if failed > 0 {
+		nodeResult.Error = fmt.Errorf("%d of %d operations failed", failed, len(nodePlan.Operations))
+		nodeResult.ErrorString = nodeResult.Error.Error()
+	}
+	return nodeResult
+}
+
+// processedError surfaces per-item errors that Pi-hole reports inside an otherwise successful response.
+func processedError(processed *pihole.ProcessedResult) string {
+	if processed == nil || len(processed.Errors) == 0 {
+		return ""
+	}
+	return processed.Errors[0].Error
+}
@@ -0,0 +1,139 @@
+package domainruleservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+const (
+	defaultRegexTestQueryLogLength = 1000
+	maxRegexTestQueryLogLength     = 10000
+)
+
+// ValidateAdd checks regex rules for FTL-compatible syntax before they are fanned out, so a bad
+// pattern is rejected once instead of failing separately on every node.
+func (s *Service) ValidateAdd(opts pihole.AddDomainRuleOptions) error {
+	if opts.Payload.Groups != nil && opts.Payload.GroupNames != nil {
+		return httpx.NewHttpError(httpx.ErrValidation, "only one of groups or groupNames may be set")
+	}
+	if opts.Kind != pihole.RuleKindRegex {
+		return nil
+	}
+	for _, rule := range payloadDomains(opts.Payload) {
+		if _, err := pihole.ParseRegex(rule); err != nil {
+			return httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("invalid regex %q: %v", rule, err))
+		}
+	}
+	return nil
+}
+
+// TestRegex previews what a regex rule would match, against sample domains and, optionally, the
+// domains in each node's recent query log.
+func (s *Service) TestRegex(ctx context.Context, params RegexTestParams) (*RegexTestResult, error) {
+	result := &RegexTestResult{Regex: params.Regex, Domains: []RegexDomainMatch{}}
+
+	re, err := pihole.ParseRegex(params.Regex)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	result.Parsed = re
+
+	match := func(domainName, queryType string) bool {
+		if queryType == "" {
+			return re.MatchDomain(domainName)
+		}
+		return re.Match(domainName, queryType)
+	}
+
+	for _, d := range params.Domains {
+		result.Domains = append(result.Domains, RegexDomainMatch{Domain: d, Matched: match(d, params.QueryType)})
+	}
+
+	if !params.QueryLog {
+		return result, nil
+	}
+
+	length := defaultRegexTestQueryLogLength
+	if params.QueryLogLength != nil {
+		length = *params.QueryLogLength
+	}
+	if length <= 0 || length > maxRegexTestQueryLogLength {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("queryLogLength must be between 1 and %d", maxRegexTestQueryLogLength))
+	}
+
+	logs, err := s.cluster.FetchQueryLogs(ctx, pihole.FetchQueryLogClusterRequest{Length: &length})
+	if err != nil {
+		return nil, err
+	}
+
+	queryLog := &RegexQueryLogResult{Matches: []RegexQueryLogMatch{}, UnreachableNodes: []domain.UnreachableNode{}}
+	matches := make(map[string]*RegexQueryLogMatch)
+	for _, nr := range logs.Results {
+		if !nr.Success || nr.Response == nil {
+			queryLog.UnreachableNodes = append(queryLog.UnreachableNodes, domain.UnreachableNode{PiholeNode: nr.PiholeNode, Error: nr.ErrorString})
+			continue
+		}
+		for _, entry := range nr.Response.Queries {
+			queryLog.Scanned++
+			if !match(entry.Domain, entry.Type) {
+				continue
+			}
+			m, ok := matches[entry.Domain]
+			if !ok {
+				m = &RegexQueryLogMatch{Domain: entry.Domain, QueryTypes: []string{}, Nodes: []domain.PiholeNodeRef{}}
+				matches[entry.Domain] = m
+			}
+			m.Count++
+			if !containsString(m.QueryTypes, entry.Type) {
+				m.QueryTypes = append(m.QueryTypes, entry.Type)
+			}
+			if !containsNode(m.Nodes, nr.PiholeNode.Id) {
+				m.Nodes = append(m.Nodes, nr.PiholeNode)
+			}
+		}
+	}
+
+	for _, m := range matches {
+		sort.Strings(m.QueryTypes)
+		sort.Slice(m.Nodes, func(i, j int) bool { return m.Nodes[i].Id < m.Nodes[j].Id })
+		queryLog.Matches = append(queryLog.Matches, *m)
+	}
+	sort.Slice(queryLog.Matches, func(i, j int) bool {
+		a, b := queryLog.Matches[i], queryLog.Matches[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Domain < b.Domain
+	})
+	sort.Slice(queryLog.UnreachableNodes, func(i, j int) bool {
+		return queryLog.UnreachableNodes[i].PiholeNode.Id < queryLog.UnreachableNodes[j].PiholeNode.Id
+	})
+
+	result.QueryLog = queryLog
+	return result, nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsNode(nodes []domain.PiholeNodeRef, id int64) bool {
+	for _, node := range nodes {
+		if node.Id == id {
+			return true
+		}
+	}
+	return false
+}
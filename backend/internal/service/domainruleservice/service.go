@@ -2,18 +2,34 @@ package domainruleservice
 
 import (
 	"context"
+	"sync"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/rs/zerolog"
 )
 
 type Service struct {
-	cluster cluster
+	cluster         cluster
+	historyStore    historyStore
+	pendingStore    pendingStore
+	expirationStore expirationStore
+	auditor         auditor
+	logger          zerolog.Logger
+	revertMu        sync.Mutex
+	pendingMu       sync.Mutex
 }
 
-func NewService(cluster cluster) *Service {
+func NewService(cluster cluster, historyStore historyStore, pendingStore pendingStore, expirationStore expirationStore, auditor auditor, logger zerolog.Logger) *Service {
 	return &Service{
-		cluster: cluster,
+		cluster:         cluster,
+		historyStore:    historyStore,
+		pendingStore:    pendingStore,
+		expirationStore: expirationStore,
+		auditor:         auditor,
+		logger:          logger,
 	}
 }
 
@@ -41,10 +57,69 @@ func (s *Service) GetByTypeKindDomain(ctx context.Context, opts pihole.GetDomain
 	return s.cluster.GetDomainRulesByTypeKindDomain(ctx, opts)
 }
 
-func (s *Service) Add(ctx context.Context, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
-	return s.cluster.AddDomainRule(ctx, opts)
+func (s *Service) Add(ctx context.Context, opts pihole.AddDomainRuleOptions) (map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	return s.add(ctx, opts, s.addSnapshot(ctx, opts)), nil
+}
+
+// addSnapshot captures the rules of the type and kind being added, so the add can tell the rules it
+// created from the ones the nodes already had.
+func (s *Service) addSnapshot(ctx context.Context, opts pihole.AddDomainRuleOptions) map[int64]map[ruleKey]pihole.DomainInfo {
+	return snapshot(s.cluster.GetDomainRulesByTypeKind(ctx, pihole.GetDomainRulesByTypeKindOptions{Type: opts.Type, Kind: opts.Kind}))
+}
+
+func (s *Service) add(ctx context.Context, opts pihole.AddDomainRuleOptions, before map[int64]map[ruleKey]pihole.DomainInfo) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+	results := s.cluster.AddDomainRule(ctx, opts)
+	s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results), false, false)
+	s.recordHistory(ctx, HistoryActionAdd, nil, addChanges(opts, results, before))
+	s.queueUnreachableAdds(opts, results)
+	return results
+}
+
+func (s *Service) Update(ctx context.Context, opts pihole.UpdateDomainRuleOptions) map[int64]*domain.NodeResult[pihole.UpdateDomainRuleResponse] {
+	before := snapshot(s.cluster.GetDomainRulesByTypeKindDomain(ctx, pihole.GetDomainRulesByTypeKindDomainOptions{
+		Type:   opts.Type,
+		Kind:   opts.Kind,
+		Domain: opts.Domain,
+	}))
+	results := s.cluster.UpdateDomainRule(ctx, opts)
+	s.auditUpdate(ctx, opts, auditservice.NodeOutcomes(results))
+	s.recordHistory(ctx, HistoryActionUpdate, nil, updateChanges(opts, results, before))
+	s.queueUnreachableUpdates(opts, results)
+	return results
 }
 
 func (s *Service) Remove(ctx context.Context, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse] {
-	return s.cluster.RemoveDomainRule(ctx, opts)
+	return s.RemoveFromNodes(ctx, nil, opts)
+}
+
+// RemoveFromNodes removes a rule from the given nodes only. A nil slice removes it from every node.
+func (s *Service) RemoveFromNodes(ctx context.Context, ids []int64, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse] {
+	before := snapshot(s.cluster.GetDomainRulesByTypeKindDomain(ctx, pihole.GetDomainRulesByTypeKindDomainOptions{
+		Type:   opts.Type,
+		Kind:   opts.Kind,
+		Domain: opts.Domain,
+	}))
+	results := s.cluster.RemoveDomainRuleFromNodes(ctx, ids, opts)
+	s.auditRemove(ctx, opts, auditservice.NodeOutcomes(results), false, false)
+	s.recordHistory(ctx, HistoryActionRemove, nil, removeChanges(opts, results, before))
+	s.queueUnreachableRemoves(opts, results)
+	s.clearExpiration(ctx, ids, opts, results, before)
+	return results
+}
+
+func (s *Service) GetDrift(ctx context.Context, driftOnly bool) DriftReport {
+	report := buildDriftReport(s.cluster.GetAllDomainRules(ctx), nodemerge.GroupNames(s.cluster.GetGroups(ctx)))
+	if driftOnly {
+		rules := make([]RuleDrift, 0, len(report.Rules))
+		for _, rule := range report.Rules {
+			if !rule.InSync {
+				rules = append(rules, rule)
+			}
+		}
+		report.Rules = rules
+	}
+	return report
 }
@@ -0,0 +1,301 @@
+package domainruleservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+const (
+	maxImportDomains = 100000
+	// importBatchSize keeps each request to a node well within its import timeout
+	importBatchSize = 1000
+)
+
+// Export merges the rules of every reachable node into a single file. When nodes disagree on a
+// rule's attributes, the lowest node id wins, matching a union reconcile. Groups are exported by name.
+// A hosts file blocks every domain in it, so it only holds the enabled exact deny rules.
+func (s *Service) Export(ctx context.Context, params ExportParams) (*ExportFile, error) {
+	if params.Format == ExportFormatHosts && (params.Type == nil || *params.Type != pihole.RuleTypeDeny) {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "hosts export requires type=deny")
+	}
+
+	nodes, unreachable, rulesByNode := collectNodeRules(s.cluster.GetAllDomainRules(ctx))
+	groupNames := nodemerge.GroupNames(s.cluster.GetGroups(ctx))
+
+	merged := make(map[ruleKey]*ExportedRule)
+	for _, node := range nodes {
+		for key, rule := range rulesByNode[node.Id] {
+			if params.Type != nil && key.Type != string(*params.Type) {
+				continue
+			}
+			if params.Kind != nil && key.Kind != string(*params.Kind) {
+				continue
+			}
+			exported, ok := merged[key]
+			if !ok {
+				exported = &ExportedRule{
+					Type:    rule.Type,
+					Kind:    rule.Kind,
+					Domain:  rule.Domain,
+					Comment: rule.Comment,
+					Groups:  nodemerge.NamesFor(rule.Groups, groupNames[node.Id]),
+					Enabled: rule.Enabled,
+					Nodes:   []domain.PiholeNodeRef{},
+				}
+				merged[key] = exported
+			}
+			exported.Nodes = append(exported.Nodes, node)
+		}
+	}
+
+	keys := make([]ruleKey, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return ruleKeyLess(keys[i], keys[j]) })
+	rules := make([]ExportedRule, 0, len(keys))
+	for _, key := range keys {
+		rules = append(rules, *merged[key])
+	}
+
+	switch params.Format {
+	case ExportFormatJSON, "":
+		body, err := json.Marshal(ExportResult{Rules: rules, UnreachableNodes: unreachable})
+		if err != nil {
+			return nil, err
+		}
+		return &ExportFile{ContentType: "application/json", Filename: "domain-rules.json", Body: body}, nil
+
+	case ExportFormatCSV:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write([]string{"type", "kind", "domain", "enabled", "comment", "groups"})
+		for _, rule := range rules {
+			_ = writer.Write([]string{rule.Type, rule.Kind, rule.Domain, strconv.FormatBool(rule.Enabled), nodemerge.CommentValue(rule.Comment), strings.Join(rule.Groups, ";")})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, err
+		}
+		return &ExportFile{ContentType: "text/csv", Filename: "domain-rules.csv", Body: buf.Bytes()}, nil
+
+	case ExportFormatList:
+		var buf bytes.Buffer
+		for _, rule := range rules {
+			buf.WriteString(rule.Domain)
+			buf.WriteByte('\n')
+		}
+		return &ExportFile{ContentType: "text/plain", Filename: "domain-rules.txt", Body: buf.Bytes()}, nil
+
+	case ExportFormatHosts:
+		// A hosts file can only express exact domains, and has no way to switch an entry off
+		var buf bytes.Buffer
+		for _, rule := range rules {
+			if rule.Kind != string(pihole.RuleKindExact) || !rule.Enabled {
+				continue
+			}
+			fmt.Fprintf(&buf, "0.0.0.0 %s\n", rule.Domain)
+		}
+		return &ExportFile{ContentType: "text/plain", Filename: "hosts", Body: buf.Bytes()}, nil
+
+	default:
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "format must be one of json, csv, list, hosts")
+	}
+}
+
+// Import adds every domain in a list or hosts file as a rule of the given type and kind.
+func (s *Service) Import(ctx context.Context, ruleType pihole.RuleType, ruleKind pihole.RuleKind, params ImportParams) (*ImportResult, error) {
+	var domains []string
+	switch params.Format {
+	case ImportFormatList, "":
+		domains = parseDomainList(params.Content, ruleKind == pihole.RuleKindRegex)
+	case ImportFormatHosts:
+		if ruleKind != pihole.RuleKindExact {
+			return nil, httpx.NewHttpError(httpx.ErrValidation, "hosts files can only be imported as exact rules")
+		}
+		domains = parseHostsFile(params.Content)
+	default:
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "format must be one of list, hosts")
+	}
+
+	if len(domains) == 0 {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "no domains found in content")
+	}
+	if len(domains) > maxImportDomains {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("too many domains (%d), the limit is %d", len(domains), maxImportDomains))
+	}
+
+	opts := pihole.AddDomainRuleOptions{
+		Type: ruleType,
+		Kind: ruleKind,
+		Payload: pihole.AddDomainPayload{
+			Domain:     domains,
+			Comment:    params.Comment,
+			Groups:     params.Groups,
+			GroupNames: params.GroupNames,
+			Enabled:    params.Enabled,
+		},
+	}
+	results, err := s.addInBatches(ctx, opts, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportResult{
+		Domains: len(domains),
+		Results: results,
+	}, nil
+}
+
+// addInBatches adds the domains importBatchSize at a time and merges each node's results. A node that
+// cannot be reached is not sent the remaining batches; they are queued for it along with the one that
+// failed. The import is audited and recorded in history as a single add.
+func (s *Service) addInBatches(ctx context.Context, opts pihole.AddDomainRuleOptions, domains []string) (map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	before := s.addSnapshot(ctx, opts)
+
+	results := make(map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse])
+	unreachable := make(map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse])
+	var ids []int64 // nodes still being sent batches, nil for every node
+	var changes []domain.DomainRuleChange
+	for start := 0; start < len(domains); start += importBatchSize {
+		batch := opts
+		batch.Payload.Domain = domains[start:min(start+importBatchSize, len(domains))]
+
+		batchResults := make(map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse])
+		if ids == nil || len(ids) > 0 {
+			batchResults = s.cluster.ImportDomainRules(ctx, ids, batch)
+		}
+		changes = append(changes, addChanges(batch, batchResults, before)...)
+		for id, nr := range batchResults {
+			mergeAddResult(results, id, nr)
+			if !nr.Success && pihole.IsUnreachable(nr.Error) {
+				unreachable[id] = nr
+			}
+		}
+		for id, nr := range unreachable {
+			batchResults[id] = nr
+		}
+		s.queueUnreachableAdds(batch, batchResults)
+
+		ids = make([]int64, 0, len(results))
+		for id := range results {
+			if _, ok := unreachable[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results), false, false)
+	s.recordHistory(ctx, HistoryActionAdd, nil, changes)
+	return results, nil
+}
+
+// mergeAddResult folds one batch's result for a node into the node's result so far. The node fails if
+// any batch did, with the first error.
+func mergeAddResult(results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], id int64, nr *domain.NodeResult[pihole.AddDomainRuleResponse]) {
+	merged, ok := results[id]
+	if !ok {
+		merged = &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: nr.PiholeNode, Success: true}
+		results[id] = merged
+	}
+	if !nr.Success && merged.Success {
+		merged.Success, merged.Error, merged.ErrorString = false, nr.Error, nr.ErrorString
+	}
+	if nr.Response == nil {
+		return
+	}
+	if merged.Response == nil {
+		merged.Response = &pihole.AddDomainRuleResponse{Domains: []pihole.DomainInfo{}}
+	}
+	merged.Response.Domains = append(merged.Response.Domains, nr.Response.Domains...)
+	merged.Response.Took += nr.Response.Took
+	if nr.Response.Processed != nil {
+		if merged.Response.Processed == nil {
+			merged.Response.Processed = &pihole.ProcessedResult{}
+		}
+		merged.Response.Processed.Success = append(merged.Response.Processed.Success, nr.Response.Processed.Success...)
+		merged.Response.Processed.Errors = append(merged.Response.Processed.Errors, nr.Response.Processed.Errors...)
+	}
+}
+
+// parseDomainList reads one entry per line, skipping blank lines and # comments. Regexes are kept
+// verbatim since they may legitimately contain '#' or whitespace.
+func parseDomainList(content string, regex bool) []string {
+	var domains []string
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !regex {
+			if i := strings.Index(line, "#"); i >= 0 {
+				line = strings.TrimSpace(line[:i])
+			}
+			line = strings.ToLower(line)
+		}
+		if line == "" {
+			continue
+		}
+		if _, ok := seen[line]; ok {
+			continue
+		}
+		seen[line] = struct{}{}
+		domains = append(domains, line)
+	}
+	return domains
+}
+
+// parseHostsFile reads "<address> <host> [<host>...]" lines, ignoring the usual local host names.
+func parseHostsFile(content string) []string {
+	ignored := map[string]struct{}{
+		"localhost": {}, "localhost.localdomain": {}, "local": {}, "broadcasthost": {},
+		"ip6-localhost": {}, "ip6-loopback": {}, "ip6-localnet": {}, "ip6-mcastprefix": {},
+		"ip6-allnodes": {}, "ip6-allrouters": {}, "ip6-allhosts": {}, "0.0.0.0": {},
+	}
+
+	var domains []string
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, host := range fields[1:] {
+			host = strings.ToLower(host)
+			if _, ok := ignored[host]; ok {
+				continue
+			}
+			if _, ok := seen[host]; ok {
+				continue
+			}
+			seen[host] = struct{}{}
+			domains = append(domains, host)
+		}
+	}
+	return domains
+}
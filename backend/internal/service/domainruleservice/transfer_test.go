@@ -0,0 +1,174 @@
+package domainruleservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+func exportCluster() *fakeCluster {
+	rules := func(id int64, domains ...pihole.DomainInfo) *domain.NodeResult[pihole.GetDomainRulesResponse] {
+		return &domain.NodeResult[pihole.GetDomainRulesResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: &pihole.GetDomainRulesResponse{Domains: domains}}
+	}
+	groups := func(id int64, groups ...pihole.GroupInfo) *domain.NodeResult[pihole.GetGroupsResponse] {
+		return &domain.NodeResult[pihole.GetGroupsResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: &pihole.GetGroupsResponse{Groups: groups}}
+	}
+	return &fakeCluster{
+		rules: map[int64]*domain.NodeResult[pihole.GetDomainRulesResponse]{
+			1: rules(1,
+				pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: "ads.com", Enabled: true, Groups: []int{0, 5}},
+				pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: "off.com", Enabled: false},
+				pihole.DomainInfo{Type: "deny", Kind: "regex", Domain: `^ad\.`, Enabled: true},
+				pihole.DomainInfo{Type: "allow", Kind: "exact", Domain: "ok.com", Enabled: true},
+			),
+			2: rules(2, pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: "ads.com", Enabled: true, Groups: []int{0, 9}}),
+		},
+		groups: map[int64]*domain.NodeResult[pihole.GetGroupsResponse]{
+			1: groups(1, pihole.GroupInfo{Id: 0, Name: "Default"}, pihole.GroupInfo{Id: 5, Name: "kids"}),
+			2: groups(2, pihole.GroupInfo{Id: 0, Name: "Default"}, pihole.GroupInfo{Id: 9, Name: "kids"}),
+		},
+	}
+}
+
+func TestExportHosts(t *testing.T) {
+	deny, allow := pihole.RuleTypeDeny, pihole.RuleTypeAllow
+
+	tests := []struct {
+		name     string
+		ruleType *pihole.RuleType
+		want     string
+		wantErr  bool
+	}{
+		{name: "without a type", wantErr: true},
+		{name: "allow rules", ruleType: &allow, wantErr: true},
+		{name: "enabled exact deny rules only", ruleType: &deny, want: "0.0.0.0 ads.com\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(exportCluster(), &fakeHistoryStore{})
+			file, err := s.Export(context.Background(), ExportParams{Format: ExportFormatHosts, Type: tt.ruleType})
+			if tt.wantErr {
+				var httpErr *httpx.HttpError
+				if !errors.As(err, &httpErr) || httpErr.Kind != httpx.ErrValidation {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if string(file.Body) != tt.want {
+				t.Errorf("body = %q, want %q", file.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportGroupsByName(t *testing.T) {
+	s := newTestService(exportCluster(), &fakeHistoryStore{})
+	file, err := s.Export(context.Background(), ExportParams{Format: ExportFormatJSON})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	var result ExportResult
+	if err := json.Unmarshal(file.Body, &result); err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range result.Rules {
+		if rule.Domain != "ads.com" {
+			continue
+		}
+		if want := []string{"Default", "kids"}; !slices.Equal(rule.Groups, want) {
+			t.Errorf("groups = %v, want %v", rule.Groups, want)
+		}
+		if len(rule.Nodes) != 2 {
+			t.Errorf("nodes = %v, want both", rule.Nodes)
+		}
+		return
+	}
+	t.Fatal("ads.com not exported")
+}
+
+func TestImportSendsBatches(t *testing.T) {
+	var batches []string
+	c := &fakeCluster{
+		nodes: []int64{1, 2},
+		importFunc: func(ids []int64, opts pihole.AddDomainRuleOptions) map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] {
+			if ids == nil {
+				ids = []int64{1, 2}
+			}
+			domains := opts.Payload.Domain.([]string)
+			batches = append(batches, fmt.Sprintf("%v:%d", ids, len(domains)))
+
+			results := make(map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse])
+			for _, id := range ids {
+				if id == 2 {
+					// Node 2 is too slow for the batch and times out
+					err := context.DeadlineExceeded
+					results[id] = &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Error: err, ErrorString: err.Error()}
+					continue
+				}
+				response := &pihole.AddDomainRuleResponse{}
+				for _, d := range domains {
+					response.Domains = append(response.Domains, pihole.DomainInfo{Type: "deny", Kind: "exact", Domain: d, Enabled: true})
+				}
+				results[id] = &domain.NodeResult[pihole.AddDomainRuleResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, Success: true, Response: response}
+			}
+			return results
+		},
+	}
+	pending := &fakePendingStore{}
+	auditor := &fakeAuditor{}
+	history := &fakeHistoryStore{}
+	s := NewService(c, history, pending, &fakeExpirationStore{}, auditor, testLogger)
+
+	var content strings.Builder
+	for i := 0; i < 2*importBatchSize+500; i++ {
+		fmt.Fprintf(&content, "d%d.com\n", i)
+	}
+	result, err := s.Import(context.Background(), pihole.RuleTypeDeny, pihole.RuleKindExact, ImportParams{Content: content.String()})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	// The timed out node is not sent the later batches
+	want := []string{"[1 2]:1000", "[1]:1000", "[1]:500"}
+	if !slices.Equal(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+	if nr := result.Results[1]; !nr.Success || len(nr.Response.Domains) != 2500 {
+		t.Errorf("node 1 = success %v with %d domains, want success with 2500", nr.Success, len(nr.Response.Domains))
+	}
+	if nr := result.Results[2]; nr.Success || !errors.Is(nr.Error, context.DeadlineExceeded) {
+		t.Errorf("node 2 = success %v, error %v, want a deadline error", nr.Success, nr.Error)
+	}
+
+	// Every domain is queued for the node that timed out, once
+	queued := make(map[string]int)
+	for _, op := range pending.ops {
+		if op.PiholeId != 2 {
+			t.Errorf("queued an op for node %d", op.PiholeId)
+		}
+		queued[op.Domain]++
+	}
+	if len(pending.ops) != 2500 || len(queued) != 2500 {
+		t.Errorf("queued %d ops for %d domains, want 2500 each", len(pending.ops), len(queued))
+	}
+
+	if len(auditor.events) != 1 || len(history.changeSets) != 1 {
+		t.Errorf("recorded %d audit events and %d change sets, want one each", len(auditor.events), len(history.changeSets))
+	}
+	if changes := history.changeSets[0].Changes; len(changes) != 2500 {
+		t.Errorf("change set has %d changes, want 2500", len(changes))
+	}
+}
@@ -0,0 +1,262 @@
+package domainruleservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// Drift report
+
+type DriftReport struct {
+	Nodes            []domain.PiholeNodeRef   `json:"nodes"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+	Rules            []RuleDrift              `json:"rules"`
+	Summary          DriftSummary             `json:"summary"`
+}
+
+type RuleDrift struct {
+	Type        string                 `json:"type"`
+	Kind        string                 `json:"kind"`
+	Domain      string                 `json:"domain"`
+	InSync      bool                   `json:"inSync"`
+	PresentOn   []domain.PiholeNodeRef `json:"presentOn"`
+	MissingFrom []domain.PiholeNodeRef `json:"missingFrom"`
+	Conflicts   []string               `json:"conflicts"` // fields that differ between nodes: enabled, comment, groups
+	States      []RuleNodeState        `json:"states"`
+}
+
+type RuleNodeState struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Enabled    bool                 `json:"enabled"`
+	Comment    *string              `json:"comment,omitempty"`
+	Groups     []int                `json:"groups"`     // this node's group ids
+	GroupNames []string             `json:"groupNames"` // compared across nodes
+}
+
+type DriftSummary struct {
+	Total       int `json:"total"`
+	InSync      int `json:"inSync"`
+	Missing     int `json:"missing"`
+	Conflicting int `json:"conflicting"`
+}
+
+// Reconcile
+
+type ReconcileSource string
+
+const (
+	ReconcileSourceNode         ReconcileSource = "node"
+	ReconcileSourceUnion        ReconcileSource = "union"
+	ReconcileSourceIntersection ReconcileSource = "intersection"
+)
+
+type ReconcileAction string
+
+const (
+	ReconcileActionAdd    ReconcileAction = "add"
+	ReconcileActionUpdate ReconcileAction = "update"
+	ReconcileActionRemove ReconcileAction = "remove"
+)
+
+type ReconcileParams struct {
+	Source ReconcileSource `json:"source"`
+	NodeId *int64          `json:"nodeId,omitempty"` // required when source is "node"
+	DryRun bool            `json:"dryRun"`
+}
+
+type ReconcileOperation struct {
+	Action     ReconcileAction `json:"action"`
+	Type       string          `json:"type"`
+	Kind       string          `json:"kind"`
+	Domain     string          `json:"domain"`
+	Comment    *string         `json:"comment,omitempty"`
+	Groups     []int           `json:"groups,omitempty"`
+	GroupNames []string        `json:"groupNames,omitempty"` // resolved to ids on the node the operation runs on
+	Enabled    *bool           `json:"enabled,omitempty"`
+}
+
+type ReconcileNodePlan struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Operations []ReconcileOperation `json:"operations"`
+}
+
+type ReconcilePlan struct {
+	Source           ReconcileSource          `json:"source"`
+	NodeId           *int64                   `json:"nodeId,omitempty"`
+	Nodes            []ReconcileNodePlan      `json:"nodes"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+}
+
+type ReconcileOperationResult struct {
+	ReconcileOperation
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+
+	current *pihole.DomainInfo // the rule as the node reported it after an add or update
+}
+
+type ReconcileNodeResponse struct {
+	Operations []ReconcileOperationResult `json:"operations"`
+}
+
+type ReconcileResult struct {
+	DryRun  bool                                                `json:"dryRun"`
+	Plan    ReconcilePlan                                       `json:"plan"`
+	Results map[int64]*domain.NodeResult[ReconcileNodeResponse] `json:"results,omitempty"`
+}
+
+// Atomic changes
+
+type AtomicResult[T any] struct {
+	Results    map[int64]*domain.NodeResult[T]                     `json:"results"`
+	RolledBack bool                                                `json:"rolledBack"`
+	Rollback   map[int64]*domain.NodeResult[ReconcileNodeResponse] `json:"rollback,omitempty"`
+}
+
+// Import and export
+
+type ExportFormat string
+
+const (
+	ExportFormatJSON  ExportFormat = "json"
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatList  ExportFormat = "list"
+	ExportFormatHosts ExportFormat = "hosts"
+)
+
+type ExportParams struct {
+	Format ExportFormat
+	Type   *pihole.RuleType
+	Kind   *pihole.RuleKind
+}
+
+type ExportedRule struct {
+	Type    string                 `json:"type"`
+	Kind    string                 `json:"kind"`
+	Domain  string                 `json:"domain"`
+	Comment *string                `json:"comment,omitempty"`
+	Groups  []string               `json:"groups"` // group names, since group ids differ between nodes
+	Enabled bool                   `json:"enabled"`
+	Nodes   []domain.PiholeNodeRef `json:"nodes"` // reachable nodes that have the rule
+}
+
+type ExportResult struct {
+	Rules            []ExportedRule           `json:"rules"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+}
+
+// ExportFile is a rendered export, ready to be written out as a download.
+type ExportFile struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatList  ImportFormat = "list"  // one domain or regex per line, # comments allowed
+	ImportFormatHosts ImportFormat = "hosts" // "<address> <host>..." lines
+)
+
+type ImportParams struct {
+	Format     ImportFormat `json:"format"`
+	Content    string       `json:"content"`
+	Comment    *string      `json:"comment,omitempty"`
+	Groups     []int        `json:"groups,omitempty"`
+	GroupNames []string     `json:"groupNames,omitempty"`
+	Enabled    *bool        `json:"enabled,omitempty"`
+}
+
+type ImportResult struct {
+	Domains int                                                        `json:"domains"` // distinct entries parsed from the content
+	Results map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse] `json:"results"`
+}
+
+// Regex testing
+
+type RegexTestParams struct {
+	Regex          string   `json:"regex"`
+	Domains        []string `json:"domains"`
+	QueryType      string   `json:"queryType,omitempty"` // e.g. "AAAA"; when empty, ;querytype= is ignored for sample domains
+	QueryLog       bool     `json:"queryLog"`
+	QueryLogLength *int     `json:"queryLogLength,omitempty"` // entries fetched per node, default 1000
+}
+
+type RegexDomainMatch struct {
+	Domain  string `json:"domain"`
+	Matched bool   `json:"matched"`
+}
+
+type RegexQueryLogMatch struct {
+	Domain     string                 `json:"domain"`
+	QueryTypes []string               `json:"queryTypes"`
+	Count      int                    `json:"count"`
+	Nodes      []domain.PiholeNodeRef `json:"nodes"`
+}
+
+type RegexQueryLogResult struct {
+	Scanned          int                      `json:"scanned"`
+	Matches          []RegexQueryLogMatch     `json:"matches"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+}
+
+type RegexTestResult struct {
+	Regex    string               `json:"regex"`
+	Valid    bool                 `json:"valid"`
+	Error    string               `json:"error,omitempty"`
+	Parsed   *pihole.Regex        `json:"parsed,omitempty"`
+	Domains  []RegexDomainMatch   `json:"domains"`
+	QueryLog *RegexQueryLogResult `json:"queryLog,omitempty"`
+}
+
+// Temporary rules
+
+// TemporaryAddResult wraps the usual add response with the expirations scheduled for the new rules.
+type TemporaryAddResult struct {
+	Results     any                            `json:"results"`
+	Expirations []*domain.DomainRuleExpiration `json:"expirations"`
+}
+
+// History
+
+const (
+	HistoryActionAdd       = "add"
+	HistoryActionUpdate    = "update"
+	HistoryActionRemove    = "remove"
+	HistoryActionReconcile = "reconcile"
+	HistoryActionRevert    = "revert"
+	HistoryActionReplay    = "replay"
+)
+
+type HistoryListParams struct {
+	Limit  int
+	Offset int
+}
+
+type HistoryList struct {
+	ChangeSets []*domain.DomainRuleChangeSet `json:"changeSets"`
+	Total      int64                         `json:"total"`
+	Limit      int                           `json:"limit"`
+	Offset     int                           `json:"offset"`
+}
+
+type RevertSinceParams struct {
+	Since *time.Time `json:"since"`
+}
+
+type RevertResult struct {
+	Reverted  *domain.DomainRuleChangeSet                         `json:"reverted"`            // the change set that was undone
+	ChangeSet *domain.DomainRuleChangeSet                         `json:"changeSet,omitempty"` // the change set recording the revert
+	Complete  bool                                                `json:"complete"`
+	Error     string                                              `json:"error,omitempty"` // why a fully applied revert is still not complete
+	Results   map[int64]*domain.NodeResult[ReconcileNodeResponse] `json:"results"`
+}
+
+type RevertSinceResult struct {
+	Since    time.Time      `json:"since"`
+	Reverts  []RevertResult `json:"reverts"`
+	Complete bool           `json:"complete"`
+}
@@ -0,0 +1,68 @@
+package eventsservice
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+func (f QueryLogFilter) isEmpty() bool {
+	return f.Domain == "" && f.Client == "" && len(f.Statuses) == 0
+}
+
+func (f QueryLogFilter) matches(entry pihole.MergedDNSLogEntry) bool {
+	if f.Domain != "" {
+		domain := strings.ToLower(entry.Domain)
+		pattern := strings.ToLower(f.Domain)
+		if strings.Contains(pattern, "*") {
+			if ok, _ := path.Match(pattern, domain); !ok {
+				return false
+			}
+		} else if !strings.Contains(domain, pattern) {
+			return false
+		}
+	}
+	if f.Client != "" {
+		name := ""
+		if entry.Client.Name != nil {
+			name = strings.ToLower(*entry.Client.Name)
+		}
+		if entry.Client.IP != f.Client && !strings.Contains(name, strings.ToLower(f.Client)) {
+			return false
+		}
+	}
+	if len(f.Statuses) > 0 {
+		matched := false
+		for _, status := range f.Statuses {
+			if strings.EqualFold(status, entry.Status) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// apply filters a published batch of query log entries. It returns nil when nothing matches.
+func (f QueryLogFilter) apply(data []byte) ([]byte, error) {
+	var entries []pihole.MergedDNSLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	matched := make([]pihole.MergedDNSLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if f.matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(matched)
+}
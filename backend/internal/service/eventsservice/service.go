@@ -19,7 +19,7 @@ func NewService(broker broker, logger zerolog.Logger) *Service {
 	}
 }
 
-func (s *Service) Subscribe(ctx context.Context, topics []string) (<-chan realtime.Event, func()) {
+func (s *Service) Subscribe(ctx context.Context, topics []string, filter QueryLogFilter) (<-chan realtime.Event, func()) {
 	ch, cancel := s.broker.Subscribe(topics)
 	out := make(chan realtime.Event)
 	go func() {
@@ -30,7 +30,19 @@ func (s *Service) Subscribe(ctx context.Context, topics []string) (<-chan realti
 				if !ok {
 					return
 				}
-				out <- realtime.Event{Topic: event.Topic, Data: event.Data}
+				data := event.Data
+				if event.Topic == realtime.TopicQueryLog && !filter.isEmpty() {
+					filtered, err := filter.apply(data)
+					if err != nil {
+						s.logger.Error().Err(err).Msg("error filtering query log event")
+						continue
+					}
+					if filtered == nil {
+						continue
+					}
+					data = filtered
+				}
+				out <- realtime.Event{Topic: event.Topic, Data: data}
 			case <-ctx.Done():
 				return
 			}
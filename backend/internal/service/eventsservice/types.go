@@ -0,0 +1,8 @@
+package eventsservice
+
+// QueryLogFilter narrows the query_log topic for a single subscriber. Empty fields match everything.
+type QueryLogFilter struct {
+	Domain   string   // substring, or a pattern with '*' wildcards
+	Client   string   // client IP, or a substring of the client name
+	Statuses []string // any of these statuses, e.g. GRAVITY, FORWARDED
+}
@@ -0,0 +1,21 @@
+package gravityservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type broker interface {
+	Publish(topic string, payload []byte)
+}
+
+type cluster interface {
+	UpdateGravity(ctx context.Context, opts pihole.UpdateGravityOptions, progress pihole.GravityProgress) map[int64]*domain.NodeResult[pihole.UpdateGravityResponse]
+}
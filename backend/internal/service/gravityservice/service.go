@@ -0,0 +1,143 @@
+package gravityservice
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/realtime"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type runAuditDetails struct {
+	Sequential bool `json:"sequential"`
+}
+
+type Service struct {
+	cluster cluster
+	broker  broker
+	auditor auditor
+	logger  zerolog.Logger
+	mu      sync.Mutex
+	latest  *Run // the running or most recently finished run
+}
+
+func NewService(cluster cluster, broker broker, auditor auditor, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		broker:  broker,
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+// Start begins a gravity update on every node and returns straight away; progress is published on
+// the gravity topic. Only one update runs at a time. The update outlives ctx's cancellation, since
+// it is usually a request context and gravity takes minutes.
+func (s *Service) Start(ctx context.Context, params RunParams) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latest != nil && s.latest.Status == RunStatusRunning {
+		return nil, httpx.NewHttpError(httpx.ErrConflict, "a gravity update is already running")
+	}
+
+	run := &Run{
+		Id:         uuid.NewString(),
+		Sequential: params.Sequential,
+		Status:     RunStatusRunning,
+		StartedAt:  time.Now(),
+		Results:    make(map[int64]*domain.NodeResult[pihole.UpdateGravityResponse]),
+	}
+	s.latest = run
+	snapshot := copyRun(run)
+
+	go s.execute(context.WithoutCancel(ctx), run)
+
+	return snapshot, nil
+}
+
+// Latest returns the running or most recently finished update, or nil if there has been none.
+func (s *Service) Latest() *Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest == nil {
+		return nil
+	}
+	return copyRun(s.latest)
+}
+
+func (s *Service) execute(ctx context.Context, run *Run) {
+	logger := s.logger.With().Str("run_id", run.Id).Bool("sequential", run.Sequential).Logger()
+	logger.Info().Msg("gravity update started")
+	s.publish(Event{RunId: run.Id, Type: EventRunStarted})
+
+	results := s.cluster.UpdateGravity(ctx, pihole.UpdateGravityOptions{Sequential: run.Sequential}, &runProgress{service: s, run: run})
+
+	outcomes := auditservice.NodeOutcomes(results)
+	success := auditservice.AllSucceeded(outcomes)
+	finishedAt := time.Now()
+
+	s.mu.Lock()
+	run.Status = RunStatusFinished
+	run.FinishedAt = &finishedAt
+	s.mu.Unlock()
+
+	s.publish(Event{RunId: run.Id, Type: EventRunFinished, Success: &success})
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionGravityUpdate,
+		Target:       run.Id,
+		Details:      runAuditDetails{Sequential: run.Sequential},
+		NodeOutcomes: outcomes,
+		Success:      success,
+	})
+	logger.Info().Bool("success", success).Dur("took", finishedAt.Sub(run.StartedAt)).Msg("gravity update finished")
+}
+
+func (s *Service) publish(event Event) {
+	event.Time = time.Now()
+	b, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("error serializing gravity event for broadcasting")
+		return
+	}
+	s.broker.Publish(realtime.TopicGravity, b)
+}
+
+// runProgress relays a run's progress from the cluster to the broker.
+type runProgress struct {
+	service *Service
+	run     *Run
+}
+
+func (p *runProgress) NodeStarted(node domain.PiholeNodeRef) {
+	p.service.publish(Event{RunId: p.run.Id, Type: EventNodeStarted, PiholeNode: &node})
+}
+
+func (p *runProgress) NodeLine(node domain.PiholeNodeRef, line string) {
+	p.service.publish(Event{RunId: p.run.Id, Type: EventLine, PiholeNode: &node, Line: line})
+}
+
+func (p *runProgress) NodeFinished(result *domain.NodeResult[pihole.UpdateGravityResponse]) {
+	p.service.mu.Lock()
+	p.run.Results[result.PiholeNode.Id] = result
+	p.service.mu.Unlock()
+
+	node := result.PiholeNode
+	success := result.Success
+	p.service.publish(Event{RunId: p.run.Id, Type: EventNodeFinished, PiholeNode: &node, Success: &success, Error: result.ErrorString})
+}
+
+// copyRun takes a snapshot of run. The caller must hold the service's mutex.
+func copyRun(run *Run) *Run {
+	out := *run
+	out.Results = maps.Clone(run.Results)
+	return &out
+}
@@ -0,0 +1,51 @@
+package gravityservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type RunParams struct {
+	Sequential bool
+}
+
+type RunStatus string
+
+const (
+	RunStatusRunning  RunStatus = "running"
+	RunStatusFinished RunStatus = "finished"
+)
+
+// Run is a cluster gravity update. Results fill in as each node finishes.
+type Run struct {
+	Id         string                                                     `json:"id"`
+	Sequential bool                                                       `json:"sequential"`
+	Status     RunStatus                                                  `json:"status"`
+	StartedAt  time.Time                                                  `json:"startedAt"`
+	FinishedAt *time.Time                                                 `json:"finishedAt,omitempty"`
+	Results    map[int64]*domain.NodeResult[pihole.UpdateGravityResponse] `json:"results"`
+}
+
+type EventType string
+
+const (
+	EventRunStarted   EventType = "run_started"
+	EventNodeStarted  EventType = "node_started"
+	EventLine         EventType = "line"
+	EventNodeFinished EventType = "node_finished"
+	EventRunFinished  EventType = "run_finished"
+)
+
+// Event is published on the gravity topic. PiholeNode is set for node events, Line for line events
+// and Success for node_finished and run_finished.
+type Event struct {
+	RunId      string                `json:"runId"`
+	Type       EventType             `json:"type"`
+	PiholeNode *domain.PiholeNodeRef `json:"piholeNode,omitempty"`
+	Line       string                `json:"line,omitempty"`
+	Success    *bool                 `json:"success,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	Time       time.Time             `json:"time"`
+}
@@ -0,0 +1,67 @@
+package groupservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type addAuditDetails struct {
+	Names   []string `json:"names"`
+	Comment *string  `json:"comment,omitempty"`
+	Enabled *bool    `json:"enabled,omitempty"`
+}
+
+type updateAuditDetails struct {
+	Name    string  `json:"name"`
+	NewName *string `json:"newName,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+	Enabled *bool   `json:"enabled,omitempty"`
+}
+
+type removeAuditDetails struct {
+	Name string `json:"name"`
+}
+
+func (s *Service) auditAdd(ctx context.Context, opts pihole.AddGroupOptions, outcomes []domain.AuditNodeOutcome) {
+	names := payloadNames(opts.Payload)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionGroupAdd,
+		Target: strings.Join(names, ","),
+		Details: addAuditDetails{
+			Names:   names,
+			Comment: opts.Payload.Comment,
+			Enabled: opts.Payload.Enabled,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditUpdate(ctx context.Context, opts pihole.UpdateGroupOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionGroupUpdate,
+		Target: opts.Name,
+		Details: updateAuditDetails{
+			Name:    opts.Name,
+			NewName: opts.Payload.Name,
+			Comment: opts.Payload.Comment,
+			Enabled: opts.Payload.Enabled,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditRemove(ctx context.Context, opts pihole.RemoveGroupOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionGroupRemove,
+		Target:       opts.Name,
+		Details:      removeAuditDetails{Name: opts.Name},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
@@ -0,0 +1,20 @@
+package groupservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type cluster interface {
+	GetGroups(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+	AddGroup(ctx context.Context, opts pihole.AddGroupOptions) map[int64]*domain.NodeResult[pihole.AddGroupResponse]
+	UpdateGroup(ctx context.Context, opts pihole.UpdateGroupOptions) map[int64]*domain.NodeResult[pihole.UpdateGroupResponse]
+	RemoveGroup(ctx context.Context, opts pihole.RemoveGroupOptions) map[int64]*domain.NodeResult[pihole.RemoveGroupResponse]
+}
@@ -0,0 +1,62 @@
+package groupservice
+
+import (
+	"sort"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// mergeGroups lines up each reachable node's groups by name.
+func mergeGroups(results map[int64]*domain.NodeResult[pihole.GetGroupsResponse]) *MergedGroups {
+	nodes, unreachable, responses := nodemerge.Split(results)
+	merged := &MergedGroups{
+		Nodes:            nodes,
+		UnreachableNodes: unreachable,
+		Groups:           []MergedGroup{},
+	}
+
+	groupsByNode := make(map[int64]map[string]pihole.GroupInfo, len(responses))
+	names := make(map[string]struct{})
+	for id, response := range responses {
+		groups := make(map[string]pihole.GroupInfo, len(response.Groups))
+		for _, group := range response.Groups {
+			groups[group.Name] = group
+			names[group.Name] = struct{}{}
+		}
+		groupsByNode[id] = groups
+	}
+
+	for name := range names {
+		mg := MergedGroup{
+			Name:        name,
+			PresentOn:   []domain.PiholeNodeRef{},
+			MissingFrom: []domain.PiholeNodeRef{},
+			States:      []GroupNodeState{},
+		}
+		for _, node := range merged.Nodes {
+			group, ok := groupsByNode[node.Id][name]
+			if !ok {
+				mg.MissingFrom = append(mg.MissingFrom, node)
+				continue
+			}
+			mg.PresentOn = append(mg.PresentOn, node)
+			mg.States = append(mg.States, GroupNodeState{
+				PiholeNode: node,
+				Id:         group.Id,
+				Enabled:    group.Enabled,
+				Comment:    group.Comment,
+			})
+		}
+		mg.OnAllNodes = len(mg.MissingFrom) == 0
+		mg.Conflicts = nodemerge.Conflicts(mg.States, func(state GroupNodeState) nodemerge.Fields {
+			return nodemerge.Fields{Enabled: state.Enabled, Comment: state.Comment}
+		})
+		merged.Groups = append(merged.Groups, mg)
+	}
+
+	sort.Slice(merged.Groups, func(i, j int) bool { return merged.Groups[i].Name < merged.Groups[j].Name })
+
+	return merged
+}
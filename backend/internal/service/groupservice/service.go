@@ -0,0 +1,81 @@
+package groupservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	cluster cluster
+	auditor auditor
+	logger  zerolog.Logger
+}
+
+func NewService(cluster cluster, auditor auditor, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse] {
+	return s.cluster.GetGroups(ctx)
+}
+
+func (s *Service) GetMerged(ctx context.Context) *MergedGroups {
+	return mergeGroups(s.cluster.GetGroups(ctx))
+}
+
+// ValidateAdd checks that every group has a name, since names are what tie a group together
+// across nodes.
+func (s *Service) ValidateAdd(opts pihole.AddGroupOptions) error {
+	names := payloadNames(opts.Payload)
+	if len(names) == 0 {
+		return httpx.NewHttpError(httpx.ErrValidation, "at least one name is required")
+	}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return httpx.NewHttpError(httpx.ErrValidation, "group names cannot be empty")
+		}
+	}
+	return nil
+}
+
+func (s *Service) Add(ctx context.Context, opts pihole.AddGroupOptions) (map[int64]*domain.NodeResult[pihole.AddGroupResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	results := s.cluster.AddGroup(ctx, opts)
+	s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results))
+	return results, nil
+}
+
+func (s *Service) Update(ctx context.Context, opts pihole.UpdateGroupOptions) map[int64]*domain.NodeResult[pihole.UpdateGroupResponse] {
+	results := s.cluster.UpdateGroup(ctx, opts)
+	s.auditUpdate(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func (s *Service) Remove(ctx context.Context, opts pihole.RemoveGroupOptions) map[int64]*domain.NodeResult[pihole.RemoveGroupResponse] {
+	results := s.cluster.RemoveGroup(ctx, opts)
+	s.auditRemove(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func payloadNames(payload pihole.AddGroupPayload) []string {
+	switch v := payload.Name.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
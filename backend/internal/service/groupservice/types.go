@@ -0,0 +1,28 @@
+package groupservice
+
+import "github.com/auto-dns/pihole-cluster-admin/internal/domain"
+
+// MergedGroups shows every group known to any reachable node, matched by name since each node
+// assigns its own ids.
+type MergedGroups struct {
+	Nodes            []domain.PiholeNodeRef   `json:"nodes"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+	Groups           []MergedGroup            `json:"groups"`
+}
+
+type MergedGroup struct {
+	Name        string                 `json:"name"`
+	OnAllNodes  bool                   `json:"onAllNodes"`
+	PresentOn   []domain.PiholeNodeRef `json:"presentOn"`
+	MissingFrom []domain.PiholeNodeRef `json:"missingFrom"`
+	Conflicts   []string               `json:"conflicts"` // fields that differ between nodes: enabled, comment
+	States      []GroupNodeState       `json:"states"`
+}
+
+// GroupNodeState is a group as one node has it, including the id that node uses for it.
+type GroupNodeState struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Id         int                  `json:"id"`
+	Enabled    bool                 `json:"enabled"`
+	Comment    *string              `json:"comment,omitempty"`
+}
@@ -0,0 +1,265 @@
+package healthservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+const dnsProbeTimeout = 3 * time.Second
+
+// probes holds everything a sweep fetched, keyed by node id. Maps for disabled checks are nil.
+type probes struct {
+	auth     map[int64]*domain.NodeResult[domain.AuthStatus]
+	blocking map[int64]*domain.NodeResult[pihole.BlockingResponse]
+	summary  map[int64]*domain.NodeResult[pihole.StatsSummaryResponse]
+	version  map[int64]*domain.NodeResult[pihole.VersionResponse]
+	dns      map[int64]dnsProbeResult
+}
+
+type dnsProbeResult struct {
+	addrs []string
+	err   error
+}
+
+// runProbes fetches the status of every node and, for the enabled checks, their blocking state,
+// gravity age and version. The DNS probe needs the node's host, so it runs once the API calls are done
+// and only against nodes that answered.
+func (s *Service) runProbes(ctx context.Context) probes {
+	var p probes
+	checks := s.cfg.Checks
+
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	run(func() { p.auth = s.cluster.AuthStatus(ctx) })
+	if checks.Blocking {
+		run(func() { p.blocking = s.cluster.GetBlocking(ctx) })
+	}
+	if checks.GravityMaxAgeHours > 0 {
+		run(func() { p.summary = s.cluster.GetStatsSummary(ctx, pihole.GetStatsSummaryOptions{}) })
+	}
+	if checks.FTLVersion {
+		run(func() { p.version = s.cluster.GetVersion(ctx) })
+	}
+	wg.Wait()
+
+	if checks.DNSProbe.Enabled {
+		p.dns = make(map[int64]dnsProbeResult, len(p.auth))
+		var mu sync.Mutex
+		for id, r := range p.auth {
+			if r.Error != nil {
+				continue
+			}
+			host := r.PiholeNode.Host
+			run(func() {
+				addrs, err := probeDNS(ctx, host, checks.DNSProbe.Port, checks.DNSProbe.Domain)
+				mu.Lock()
+				p.dns[id] = dnsProbeResult{addrs: addrs, err: err}
+				mu.Unlock()
+			})
+		}
+		wg.Wait()
+	}
+
+	return p
+}
+
+// probeDNS resolves name using only the DNS server at host:port, bypassing the system resolver.
+func probeDNS(ctx context.Context, host string, port int, name string) ([]string, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dnsProbeTimeout)
+	defer cancel()
+	addrs, err := resolver.LookupHost(ctx, name)
+	if err != nil {
+		// The resolver reports the system nameserver in its error, not the one it actually dialled
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return nil, fmt.Errorf("resolving %s via %s: %s", name, addr, dnsErr.Err)
+		}
+		return nil, fmt.Errorf("resolving %s via %s: %w", name, addr, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses returned for %s", name)
+	}
+	return addrs, nil
+}
+
+// evaluate turns a node's probe results into its checks. When the API check fails the remaining
+// checks are skipped, since none of them can be trusted.
+func (s *Service) evaluate(p probes, id int64, now time.Time) []Check {
+	auth := p.auth[id]
+	apiCheck := Check{Name: CheckAPI, Status: CheckPass}
+	switch {
+	case auth.Error != nil:
+		apiCheck.Status, apiCheck.Detail = CheckFail, auth.Error.Error()
+	case auth.Response == nil || !auth.Response.Valid:
+		apiCheck.Status, apiCheck.Detail = CheckWarn, "session is not valid"
+	}
+	checks := []Check{apiCheck}
+
+	names := s.enabledChecks()
+	if apiCheck.Status == CheckFail {
+		for _, name := range names {
+			checks = append(checks, Check{Name: name, Status: CheckSkipped, Detail: "node is unreachable"})
+		}
+		return checks
+	}
+
+	for _, name := range names {
+		switch name {
+		case CheckBlocking:
+			checks = append(checks, blockingCheck(p.blocking[id]))
+		case CheckGravity:
+			checks = append(checks, gravityCheck(p.summary[id], time.Duration(s.cfg.Checks.GravityMaxAgeHours)*time.Hour, now))
+		case CheckFTLVersion:
+			checks = append(checks, versionCheck(p.version[id]))
+		case CheckDNS:
+			checks = append(checks, dnsCheck(p.dns[id], s.cfg.Checks.DNSProbe.Domain))
+		}
+	}
+	return checks
+}
+
+func (s *Service) enabledChecks() []string {
+	var names []string
+	if s.cfg.Checks.Blocking {
+		names = append(names, CheckBlocking)
+	}
+	if s.cfg.Checks.GravityMaxAgeHours > 0 {
+		names = append(names, CheckGravity)
+	}
+	if s.cfg.Checks.FTLVersion {
+		names = append(names, CheckFTLVersion)
+	}
+	if s.cfg.Checks.DNSProbe.Enabled {
+		names = append(names, CheckDNS)
+	}
+	return names
+}
+
+func blockingCheck(r *domain.NodeResult[pihole.BlockingResponse]) Check {
+	check := Check{Name: CheckBlocking}
+	switch {
+	case r == nil || r.Response == nil:
+		check.Status, check.Detail = CheckFail, resultError(r)
+	case r.Response.Blocking == "enabled":
+		check.Status, check.Detail = CheckPass, "enabled"
+	case r.Response.Blocking == "disabled" && r.Response.Timer != nil:
+		// A timed pause is deliberate and ends on its own, so it must not degrade the node
+		check.Status, check.Detail = CheckPass, fmt.Sprintf("blocking is paused, re-enabled in %ds", int(math.Ceil(*r.Response.Timer)))
+	case r.Response.Blocking == "disabled":
+		check.Status, check.Detail = CheckWarn, "blocking is disabled"
+	default:
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("blocking is %s", r.Response.Blocking)
+	}
+	return check
+}
+
+func gravityCheck(r *domain.NodeResult[pihole.StatsSummaryResponse], maxAge time.Duration, now time.Time) Check {
+	check := Check{Name: CheckGravity}
+	if r == nil || r.Response == nil {
+		check.Status, check.Detail = CheckFail, resultError(r)
+		return check
+	}
+
+	lastUpdate := r.Response.Gravity.LastUpdate
+	if lastUpdate == 0 {
+		check.Status, check.Detail = CheckWarn, "gravity has never been updated"
+		return check
+	}
+	age := now.Sub(time.Unix(lastUpdate, 0))
+	check.Detail = fmt.Sprintf("last updated %s ago, %d domains", formatAge(age), r.Response.Gravity.DomainsBeingBlocked)
+	if age > maxAge {
+		check.Status = CheckWarn
+	} else {
+		check.Status = CheckPass
+	}
+	return check
+}
+
+func versionCheck(r *domain.NodeResult[pihole.VersionResponse]) Check {
+	check := Check{Name: CheckFTLVersion}
+	if r == nil || r.Response == nil {
+		check.Status, check.Detail = CheckFail, resultError(r)
+		return check
+	}
+
+	ftl := r.Response.Version.FTL
+	check.Status, check.Detail = CheckPass, ftl.Local.Version
+	if ftl.Remote.Version != "" && ftl.Remote.Version != ftl.Local.Version {
+		check.Detail += fmt.Sprintf(" (%s available)", ftl.Remote.Version)
+	}
+	return check
+}
+
+func dnsCheck(r dnsProbeResult, name string) Check {
+	check := Check{Name: CheckDNS}
+	if r.err != nil {
+		check.Status, check.Detail = CheckFail, r.err.Error()
+		return check
+	}
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("%s resolved to %s", name, strings.Join(r.addrs, ", "))
+	return check
+}
+
+// deriveStatus rolls checks up into the node's status: offline when the API is unreachable, degraded
+// when any other check does not pass. The returned error describes the first problem found.
+func deriveStatus(checks []Check) (Status, string) {
+	status, lastErr := StatusOnline, ""
+	for _, check := range checks {
+		if check.Status == CheckPass || check.Status == CheckSkipped {
+			continue
+		}
+		if check.Name == CheckAPI && check.Status == CheckFail {
+			return StatusOffline, check.Detail
+		}
+		if lastErr == "" {
+			status, lastErr = StatusDegraded, fmt.Sprintf("%s: %s", check.Name, check.Detail)
+		}
+	}
+	return status, lastErr
+}
+
+func resultError[T any](r *domain.NodeResult[T]) string {
+	if r == nil {
+		return "no result"
+	}
+	if r.ErrorString != "" {
+		return r.ErrorString
+	}
+	return "empty response"
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,36 @@
+package healthservice
+
+import (
+	"testing"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+func TestBlockingCheckStatus(t *testing.T) {
+	timer := 299.5
+
+	tests := []struct {
+		name       string
+		response   *pihole.BlockingResponse
+		wantCheck  CheckStatus
+		wantStatus Status
+	}{
+		{name: "enabled", response: &pihole.BlockingResponse{Blocking: "enabled"}, wantCheck: CheckPass, wantStatus: StatusOnline},
+		{name: "paused with a timer", response: &pihole.BlockingResponse{Blocking: "disabled", Timer: &timer}, wantCheck: CheckPass, wantStatus: StatusOnline},
+		{name: "disabled", response: &pihole.BlockingResponse{Blocking: "disabled"}, wantCheck: CheckWarn, wantStatus: StatusDegraded},
+		{name: "no response", wantCheck: CheckFail, wantStatus: StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := blockingCheck(&domain.NodeResult[pihole.BlockingResponse]{Success: tt.response != nil, Response: tt.response})
+			if check.Status != tt.wantCheck {
+				t.Errorf("check = %s (%s), want %s", check.Status, check.Detail, tt.wantCheck)
+			}
+			if status, _ := deriveStatus([]Check{check}); status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
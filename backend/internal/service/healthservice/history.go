@@ -0,0 +1,277 @@
+package healthservice
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+// latencyBucketBounds are the upper bounds, in milliseconds, of the latency buckets used by the
+// hourly rollups. Latencies above the last bound fall into an overflow bucket. Rollups store bucket
+// indexes, so changing these bounds misreads existing history.
+var latencyBucketBounds = []int{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+const (
+	historyPruneInterval = time.Hour
+	hourlySeriesWindow   = 7 * 24 * time.Hour
+)
+
+var historyWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+func latencyBucket(ms int) int {
+	return sort.SearchInts(latencyBucketBounds, ms)
+}
+
+// recordHistory stores a sweep's results. History is best effort and never affects health polling.
+func (s *Service) recordHistory(nodes []NodeHealth, interval time.Duration) {
+	if !s.cfg.History.Enabled || len(nodes) == 0 {
+		return
+	}
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	samples := make([]store.AddNodeHealthSampleParams, 0, len(nodes))
+	for _, nh := range nodes {
+		samples = append(samples, store.AddNodeHealthSampleParams{
+			PiholeId:        nh.Id,
+			Time:            nh.UpdatedAt,
+			Status:          string(nh.Status),
+			LatencyMS:       nh.LatencyMS,
+			LatencyBucket:   latencyBucket(nh.LatencyMS),
+			IntervalSeconds: int(interval / time.Second),
+		})
+	}
+	if err := s.historyStore.AddNodeHealthSamples(samples); err != nil {
+		s.logger.Error().Err(err).Msg("error recording node health history")
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) < historyPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	sampleCutoff := now.Add(-time.Duration(s.cfg.History.SampleRetentionHours) * time.Hour)
+	if removed, err := s.historyStore.RemoveNodeHealthSamplesBefore(sampleCutoff); err != nil {
+		s.logger.Error().Err(err).Msg("error pruning node health samples")
+	} else if removed > 0 {
+		s.logger.Debug().Int64("removed", removed).Msg("pruned node health samples")
+	}
+	rollupCutoff := now.Add(-time.Duration(s.cfg.History.RetentionDays) * 24 * time.Hour)
+	if removed, err := s.historyStore.RemoveNodeHealthRollupsBefore(rollupCutoff); err != nil {
+		s.logger.Error().Err(err).Msg("error pruning node health rollups")
+	} else if removed > 0 {
+		s.logger.Debug().Int64("removed", removed).Msg("pruned node health rollups")
+	}
+}
+
+// GetNodeHistory reports uptime and latency over the last 24 hours, 7 days and 30 days, plus an
+// hourly series for spotting trends. The 24 hour window is computed from full resolution samples,
+// the longer windows from the hourly rollups. Status percentages weigh each sweep by the interval it
+// ran at, since sweeps run faster while someone has the UI open.
+func (s *Service) GetNodeHistory(id int64) (*NodeHistory, error) {
+	if !s.cfg.History.Enabled {
+		return nil, httpx.NewHttpError(httpx.ErrNotFound, "health history is disabled")
+	}
+
+	now := time.Now().UTC()
+	longest := historyWindows[len(historyWindows)-1].duration
+	rollups, err := s.historyStore.GetNodeHealthRollups(id, now.Add(-longest).Truncate(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	current, known := s.nodeHealth[id]
+	s.mu.RUnlock()
+	if !known && len(rollups) == 0 {
+		return nil, httpx.NewHttpError(httpx.ErrNotFound, fmt.Sprintf("no health history for node %d", id))
+	}
+
+	history := &NodeHistory{Id: id, Name: current.Name, Windows: []HistoryWindow{}, Hourly: []HourlyHealth{}}
+
+	for _, w := range historyWindows {
+		from := now.Add(-w.duration)
+		if w.duration <= time.Duration(s.cfg.History.SampleRetentionHours)*time.Hour {
+			samples, err := s.historyStore.GetNodeHealthSamples(id, from)
+			if err != nil {
+				return nil, err
+			}
+			history.Windows = append(history.Windows, windowFromSamples(w.name, from, samples))
+			continue
+		}
+		// Rollups are hourly, so the window starts at the beginning of the hour it falls in
+		history.Windows = append(history.Windows, windowFromRollups(w.name, from.Truncate(time.Hour), rollups))
+	}
+
+	seriesFrom := now.Add(-hourlySeriesWindow).Truncate(time.Hour)
+	byHour := make(map[time.Time]*rollupTally)
+	var hours []time.Time
+	for _, r := range rollups {
+		if r.BucketStart.Before(seriesFrom) {
+			continue
+		}
+		tally, ok := byHour[r.BucketStart]
+		if !ok {
+			tally = newRollupTally()
+			byHour[r.BucketStart] = tally
+			hours = append(hours, r.BucketStart)
+		}
+		tally.add(r)
+	}
+	for _, hour := range hours {
+		tally := byHour[hour]
+		history.Hourly = append(history.Hourly, HourlyHealth{
+			Start:         hour,
+			Samples:       tally.total,
+			UptimePercent: percentOf(tally.seconds[StatusOnline], tally.totalSeconds),
+			LatencyP50:    tally.latencyPercentile(0.50),
+			LatencyP95:    tally.latencyPercentile(0.95),
+		})
+	}
+
+	return history, nil
+}
+
+func windowFromSamples(name string, from time.Time, samples []*domain.NodeHealthSample) HistoryWindow {
+	window := HistoryWindow{Window: name, From: from, Samples: int64(len(samples))}
+	seconds := make(map[Status]int64)
+	var total int64
+	var latencies []int
+	for _, sample := range samples {
+		status := Status(sample.Status)
+		seconds[status] += int64(sample.IntervalSeconds)
+		total += int64(sample.IntervalSeconds)
+		if status == StatusOnline {
+			latencies = append(latencies, sample.LatencyMS)
+		}
+	}
+	window.setPercentages(seconds, total)
+
+	if len(latencies) > 0 {
+		sort.Ints(latencies)
+		window.Latency = LatencyPercentiles{
+			P50: exactPercentile(latencies, 0.50),
+			P90: exactPercentile(latencies, 0.90),
+			P95: exactPercentile(latencies, 0.95),
+			P99: exactPercentile(latencies, 0.99),
+		}
+	}
+	return window
+}
+
+func windowFromRollups(name string, from time.Time, rollups []*domain.NodeHealthRollup) HistoryWindow {
+	tally := newRollupTally()
+	for _, r := range rollups {
+		if !r.BucketStart.Before(from) {
+			tally.add(r)
+		}
+	}
+
+	window := HistoryWindow{Window: name, From: from, Samples: tally.total}
+	window.setPercentages(tally.seconds, tally.totalSeconds)
+	window.Latency = LatencyPercentiles{
+		P50: tally.latencyPercentile(0.50),
+		P90: tally.latencyPercentile(0.90),
+		P95: tally.latencyPercentile(0.95),
+		P99: tally.latencyPercentile(0.99),
+	}
+	return window
+}
+
+// setPercentages sets the share of time spent in each status, from the seconds the sweeps stand for.
+func (w *HistoryWindow) setPercentages(seconds map[Status]int64, total int64) {
+	if total == 0 {
+		return
+	}
+	uptime := percentOf(seconds[StatusOnline], total)
+	degraded := percentOf(seconds[StatusDegraded], total)
+	offline := percentOf(seconds[StatusOffline], total)
+	w.UptimePercent, w.DegradedPercent, w.OfflinePercent = &uptime, &degraded, &offline
+}
+
+// rollupTally merges rollup rows into the time spent in each status and a latency histogram of online
+// sweeps.
+type rollupTally struct {
+	total        int64 // sweeps
+	totalSeconds int64
+	seconds      map[Status]int64
+	latency      []int64 // per bucket, the last entry being the overflow bucket
+	online       int64
+}
+
+func newRollupTally() *rollupTally {
+	return &rollupTally{
+		seconds: make(map[Status]int64),
+		latency: make([]int64, len(latencyBucketBounds)+1),
+	}
+}
+
+func (t *rollupTally) add(r *domain.NodeHealthRollup) {
+	status := Status(r.Status)
+	t.total += r.Count
+	t.totalSeconds += r.Seconds
+	t.seconds[status] += r.Seconds
+	if status == StatusOnline && r.LatencyBucket >= 0 && r.LatencyBucket < len(t.latency) {
+		t.latency[r.LatencyBucket] += r.Count
+		t.online += r.Count
+	}
+}
+
+// latencyPercentile estimates the q-th percentile by interpolating linearly within the bucket it
+// falls in. Percentiles in the overflow bucket are reported as the last bound.
+func (t *rollupTally) latencyPercentile(q float64) *float64 {
+	if t.online == 0 {
+		return nil
+	}
+	rank := q * float64(t.online)
+	var cumulative int64
+	for i, count := range t.latency {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+		if i == len(latencyBucketBounds) {
+			v := float64(latencyBucketBounds[len(latencyBucketBounds)-1])
+			return &v
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = float64(latencyBucketBounds[i-1])
+		}
+		upper := float64(latencyBucketBounds[i])
+		v := lower + (upper-lower)*(rank-float64(cumulative))/float64(count)
+		return &v
+	}
+	v := float64(latencyBucketBounds[len(latencyBucketBounds)-1])
+	return &v
+}
+
+// exactPercentile uses the nearest-rank method on sorted values.
+func exactPercentile(sorted []int, q float64) *float64 {
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	v := float64(sorted[i])
+	return &v
+}
+
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
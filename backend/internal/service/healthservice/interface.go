@@ -2,8 +2,11 @@ package healthservice
 
 import (
 	"context"
+	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 )
 
 type broker interface {
@@ -12,6 +15,29 @@ type broker interface {
 	Publish(topic string, payload []byte)
 }
 
+type nodeRecoveryHandler interface {
+	HandleNodeOnline(ctx context.Context, id int64)
+}
+
 type cluster interface {
 	AuthStatus(ctx context.Context) map[int64]*domain.NodeResult[domain.AuthStatus]
+	GetBlocking(ctx context.Context) map[int64]*domain.NodeResult[pihole.BlockingResponse]
+	GetStatsSummary(ctx context.Context, opts pihole.GetStatsSummaryOptions) map[int64]*domain.NodeResult[pihole.StatsSummaryResponse]
+	GetVersion(ctx context.Context) map[int64]*domain.NodeResult[pihole.VersionResponse]
+}
+
+type metrics interface {
+	SetNodeHealth(nodes []NodeHealth)
+}
+
+type notifier interface {
+	NotifyTransition(ctx context.Context, t domain.NodeStatusTransition)
+}
+
+type historyStore interface {
+	AddNodeHealthSamples(samples []store.AddNodeHealthSampleParams) error
+	GetNodeHealthSamples(piholeId int64, since time.Time) ([]*domain.NodeHealthSample, error)
+	GetNodeHealthRollups(piholeId int64, since time.Time) ([]*domain.NodeHealthRollup, error)
+	RemoveNodeHealthSamplesBefore(cutoff time.Time) (int64, error)
+	RemoveNodeHealthRollupsBefore(cutoff time.Time) (int64, error)
 }
@@ -8,38 +8,65 @@ import (
 	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	"github.com/auto-dns/pihole-cluster-admin/internal/logger"
 	"github.com/rs/zerolog"
 )
 
 type Service struct {
-	broker     broker
-	cluster    cluster
-	logger     zerolog.Logger
-	cfg        config.HealthServiceConfig
-	mu         sync.RWMutex
-	nodeHealth map[int64]NodeHealth
-	summary    Summary
+	broker       broker
+	cluster      cluster
+	recovery     nodeRecoveryHandler
+	metrics      metrics
+	notifier     notifier
+	historyStore historyStore
+	logger       zerolog.Logger
+	cfg          config.HealthServiceConfig
+	mu           sync.RWMutex
+	nodeHealth   map[int64]NodeHealth
+	alerts       map[int64]*alertState
+	summary      Summary
+	historyMu    sync.Mutex // serializes history writes, which run in the background
+	lastPrune    time.Time  // guarded by historyMu
 }
 
-func NewService(broker broker, cluster cluster, cfg config.HealthServiceConfig, logger zerolog.Logger) *Service {
+func NewService(broker broker, cluster cluster, recovery nodeRecoveryHandler, metrics metrics, notifier notifier, historyStore historyStore, cfg config.HealthServiceConfig, logger zerolog.Logger) *Service {
 	return &Service{
-		broker:     broker,
-		cluster:    cluster,
-		cfg:        cfg,
-		logger:     logger,
-		nodeHealth: make(map[int64]NodeHealth),
+		broker:       broker,
+		cluster:      cluster,
+		recovery:     recovery,
+		metrics:      metrics,
+		notifier:     notifier,
+		historyStore: historyStore,
+		cfg:          cfg,
+		logger:       logger,
+		nodeHealth:   make(map[int64]NodeHealth),
+		alerts:       make(map[int64]*alertState),
 	}
 }
 
 func (s *Service) Start(ctx context.Context) {
 	s.logger.Info().Msg("Starting health service")
 
-	s.sweepOnce(ctx)
+	// The next sweep comes at the monitor interval if monitoring is on, and once someone opens the UI
+	// otherwise, which is at least the active interval away
+	interval := s.activeInterval()
+	if s.cfg.Monitor.Enabled {
+		interval = s.monitorInterval()
+	}
+	s.sweepOnce(ctx, interval)
 
 	go s.loop(ctx)
 }
 
+func (s *Service) activeInterval() time.Duration {
+	return time.Duration(max(1, s.cfg.PollingIntervalSeconds)) * time.Second
+}
+
+func (s *Service) monitorInterval() time.Duration {
+	return time.Duration(max(1, s.cfg.Monitor.PollingIntervalSeconds)) * time.Second
+}
+
 func (s *Service) GetSummary() Summary {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -53,13 +80,23 @@ func (s *Service) GetNodeHealth() map[int64]NodeHealth {
 }
 
 func (s *Service) loop(ctx context.Context) {
-	activeInterval := time.Duration(max(1, s.cfg.PollingIntervalSeconds)) * time.Second
+	activeInterval := s.activeInterval()
+	monitorInterval := s.monitorInterval()
 	stopGracePeriod := time.Duration(s.cfg.GracePeriodSeconds) * time.Second
 
 	newTicker := func(d time.Duration) *time.Ticker {
 		return time.NewTicker(jitter(d))
 	}
 
+	// With monitoring enabled, the idle state keeps sweeping at the slower monitor interval so status
+	// changes are still noticed and notified
+	var idleTick <-chan time.Time
+	if s.cfg.Monitor.Enabled {
+		idleTicker := newTicker(monitorInterval)
+		defer idleTicker.Stop()
+		idleTick = idleTicker.C
+	}
+
 	for {
 		// Idle state when no subscribers
 		if s.broker.SubscriberCount() == 0 {
@@ -68,20 +105,23 @@ func (s *Service) loop(ctx context.Context) {
 				if s.broker.SubscriberCount() == 0 {
 					continue
 				}
+			case <-idleTick:
+				s.sweepOnce(ctx, monitorInterval)
+				continue
 			case <-ctx.Done():
 				return
 			}
 		}
 
 		// Active: at least one subscriber
-		s.sweepOnce(ctx)
+		s.sweepOnce(ctx, activeInterval)
 		ticker := newTicker(activeInterval)
 		running := true
 
 		for running {
 			select {
 			case <-ticker.C:
-				s.sweepOnce(ctx)
+				s.sweepOnce(ctx, activeInterval)
 
 			case <-s.broker.SubscribersChanged():
 				if s.broker.SubscriberCount() == 0 {
@@ -114,51 +154,79 @@ func (s *Service) loop(ctx context.Context) {
 	}
 }
 
-func (s *Service) sweepOnce(ctx context.Context) {
+// sweepOnce runs a sweep and everything it triggers. interval is how often sweeps run at the moment,
+// so history can weigh each sweep by the time it stands for.
+func (s *Service) sweepOnce(ctx context.Context, interval time.Duration) {
+	result := s.sweep(ctx)
+
+	// Replays, notifications and history talk to other systems, so they run outside the sweep and never
+	// hold up polling
+	for _, id := range result.recovered {
+		go s.recovery.HandleNodeOnline(ctx, id)
+	}
+	for _, t := range result.transitions {
+		// Sends in the background, keeping each node's transitions in order
+		s.notifier.NotifyTransition(ctx, t)
+	}
+	go s.recordHistory(result.nodes, interval)
+}
+
+type sweepResult struct {
+	nodes       []NodeHealth                  // health of every node polled
+	recovered   []int64                       // nodes that have just come online
+	transitions []domain.NodeStatusTransition // debounced status changes
+}
+
+// sweep polls every node, runs the enabled checks against it and updates their health.
+func (s *Service) sweep(ctx context.Context) sweepResult {
 	pollLog := s.logger.With().Str("component", "health").Logger()
 	ctx = logger.WithContext(ctx, pollLog)
 	ctx = logger.WithMode(ctx, logger.ModeTrace)
 
-	results := s.cluster.AuthStatus(ctx)
+	p := s.runProbes(ctx)
 
 	now := time.Now()
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, r := range results {
-		var tookMs int
-		var valid bool
+	var result sweepResult
 
+	for id, r := range p.auth {
+		var tookMs int
 		if r.Response != nil {
 			tookMs = int(math.Round(r.Response.Took * 1000)) // server processing
-			valid = r.Response.Valid
 		}
 
+		checks := s.evaluate(p, id, now)
+		status, lastErr := deriveStatus(checks)
 		nodeHealth := NodeHealth{
 			Id:        r.PiholeNode.Id,
 			Name:      r.PiholeNode.Name,
-			Status:    pickStatus(valid, r.Error),
+			Status:    status,
 			LatencyMS: tookMs,
+			LastErr:   lastErr,
+			Checks:    checks,
 			UpdatedAt: now,
 		}
-		if r.Error != nil {
-			nodeHealth.LastErr = r.Error.Error()
+		// A node seen for the first time counts as recovered, so work queued before a restart is replayed.
+		// Only the API check matters here: a node with e.g. stale gravity still accepts changes.
+		if previous, ok := s.nodeHealth[r.PiholeNode.Id]; apiHealthy(nodeHealth) && (!ok || !apiHealthy(previous)) {
+			result.recovered = append(result.recovered, r.PiholeNode.Id)
 		}
 		s.nodeHealth[r.PiholeNode.Id] = nodeHealth
+		result.nodes = append(result.nodes, nodeHealth)
+		if t := s.detectTransitionLocked(r.PiholeNode, nodeHealth); t != nil {
+			result.transitions = append(result.transitions, *t)
+		}
 	}
 	s.recomputeLocked()
+
+	return result
 }
 
-func pickStatus(valid bool, err error) Status {
-	switch {
-	case err != nil:
-		return StatusOffline
-	case valid:
-		return StatusOnline
-	default:
-		return StatusDegraded
-	}
+func apiHealthy(nh NodeHealth) bool {
+	return len(nh.Checks) > 0 && nh.Checks[0].Name == CheckAPI && nh.Checks[0].Status == CheckPass
 }
 
 func (s *Service) recomputeLocked() {
@@ -187,6 +255,7 @@ func (s *Service) recomputeLocked() {
 	for _, nh := range s.nodeHealth {
 		list = append(list, nh)
 	}
+	s.metrics.SetNodeHealth(list)
 	if b, err := json.Marshal(list); err == nil {
 		s.broker.Publish("node_health", b)
 	} else {
@@ -0,0 +1,55 @@
+package healthservice
+
+import "github.com/auto-dns/pihole-cluster-admin/internal/domain"
+
+// alertState debounces a node's status, so a single failed sweep does not page anyone.
+type alertState struct {
+	reported  Status // last status reported; empty until the node's status is first established
+	candidate Status // status seen on the most recent sweeps that differs from reported
+	streak    int    // consecutive sweeps candidate has been seen for
+}
+
+// detectTransitionLocked feeds a sweep result into the node's debounce state and returns a
+// transition once a new status has held for long enough. Must be called with s.mu held.
+func (s *Service) detectTransitionLocked(node domain.PiholeNodeRef, nodeHealth NodeHealth) *domain.NodeStatusTransition {
+	state, ok := s.alerts[node.Id]
+	if !ok {
+		state = &alertState{}
+		s.alerts[node.Id] = state
+	}
+
+	if nodeHealth.Status == state.reported {
+		state.candidate, state.streak = "", 0
+		return nil
+	}
+	if nodeHealth.Status != state.candidate {
+		state.candidate, state.streak = nodeHealth.Status, 0
+	}
+	state.streak++
+
+	threshold := s.cfg.Monitor.FailureThreshold
+	if nodeHealth.Status == StatusOnline {
+		threshold = s.cfg.Monitor.RecoveryThreshold
+		if state.reported == "" {
+			// A node that is healthy from the start is not news
+			threshold = 1
+		}
+	}
+	if state.streak < max(1, threshold) {
+		return nil
+	}
+
+	from := state.reported
+	state.reported, state.candidate, state.streak = nodeHealth.Status, "", 0
+	if from == "" && nodeHealth.Status == StatusOnline {
+		return nil
+	}
+
+	return &domain.NodeStatusTransition{
+		PiholeNode: node,
+		From:       string(from),
+		To:         string(nodeHealth.Status),
+		Error:      nodeHealth.LastErr,
+		At:         nodeHealth.UpdatedAt,
+	}
+}
@@ -16,11 +16,69 @@ type NodeHealth struct {
 	Status    Status    `json:"status"`
 	LatencyMS int       `json:"latencyMs"`
 	LastErr   string    `json:"lastErr,omitempty"`
+	Checks    []Check   `json:"checks"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type CheckStatus string
+
+const (
+	CheckPass    CheckStatus = "pass"
+	CheckWarn    CheckStatus = "warn" // working, but not as it should, e.g. blocking disabled
+	CheckFail    CheckStatus = "fail" // the check could not be completed or found the node broken
+	CheckSkipped CheckStatus = "skipped"
+)
+
+const (
+	CheckAPI        = "api"
+	CheckBlocking   = "blocking"
+	CheckGravity    = "gravity"
+	CheckFTLVersion = "ftl_version"
+	CheckDNS        = "dns"
+)
+
+type Check struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
 type Summary struct {
 	Online    int       `json:"online"`
 	Total     int       `json:"total"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// NodeHistory summarises a node's recorded health. Latency figures only count sweeps where the node
+// was online, and every figure only counts sweeps that actually ran.
+type NodeHistory struct {
+	Id      int64           `json:"id"`
+	Name    string          `json:"name,omitempty"`
+	Windows []HistoryWindow `json:"windows"`
+	Hourly  []HourlyHealth  `json:"hourly"` // last 7 days, oldest first
+}
+
+type HistoryWindow struct {
+	Window          string             `json:"window"` // "24h", "7d" or "30d"
+	From            time.Time          `json:"from"`
+	Samples         int64              `json:"samples"`
+	UptimePercent   *float64           `json:"uptimePercent"` // nil when there are no samples
+	DegradedPercent *float64           `json:"degradedPercent"`
+	OfflinePercent  *float64           `json:"offlinePercent"`
+	Latency         LatencyPercentiles `json:"latency"`
+}
+
+type LatencyPercentiles struct {
+	P50 *float64 `json:"p50Ms"`
+	P90 *float64 `json:"p90Ms"`
+	P95 *float64 `json:"p95Ms"`
+	P99 *float64 `json:"p99Ms"`
+}
+
+type HourlyHealth struct {
+	Start         time.Time `json:"start"`
+	Samples       int64     `json:"samples"`
+	UptimePercent float64   `json:"uptimePercent"`
+	LatencyP50    *float64  `json:"latencyP50Ms"`
+	LatencyP95    *float64  `json:"latencyP95Ms"`
+}
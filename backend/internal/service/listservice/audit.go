@@ -0,0 +1,79 @@
+package listservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type addAuditDetails struct {
+	Type      pihole.ListType `json:"type"`
+	Addresses []string        `json:"addresses"`
+	Comment   *string         `json:"comment,omitempty"`
+	Groups    []int           `json:"groups,omitempty"`
+	Enabled   *bool           `json:"enabled,omitempty"`
+}
+
+type updateAuditDetails struct {
+	Type    pihole.ListType `json:"type"`
+	Address string          `json:"address"`
+	Comment *string         `json:"comment,omitempty"`
+	Groups  []int           `json:"groups,omitempty"`
+	Enabled *bool           `json:"enabled,omitempty"`
+}
+
+type removeAuditDetails struct {
+	Type    pihole.ListType `json:"type"`
+	Address string          `json:"address"`
+}
+
+func (s *Service) auditAdd(ctx context.Context, opts pihole.AddListOptions, outcomes []domain.AuditNodeOutcome) {
+	addresses := payloadAddresses(opts.Payload)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionListAdd,
+		Target: listTarget(opts.Type, addresses...),
+		Details: addAuditDetails{
+			Type:      opts.Type,
+			Addresses: addresses,
+			Comment:   opts.Payload.Comment,
+			Groups:    opts.Payload.Groups,
+			Enabled:   opts.Payload.Enabled,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditUpdate(ctx context.Context, opts pihole.UpdateListOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionListUpdate,
+		Target: listTarget(opts.Type, opts.Address),
+		Details: updateAuditDetails{
+			Type:    opts.Type,
+			Address: opts.Address,
+			Comment: opts.Payload.Comment,
+			Groups:  opts.Payload.Groups,
+			Enabled: opts.Payload.Enabled,
+		},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func (s *Service) auditRemove(ctx context.Context, opts pihole.RemoveListOptions, outcomes []domain.AuditNodeOutcome) {
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionListRemove,
+		Target:       listTarget(opts.Type, opts.Address),
+		Details:      removeAuditDetails{Type: opts.Type, Address: opts.Address},
+		NodeOutcomes: outcomes,
+		Success:      auditservice.AllSucceeded(outcomes),
+	})
+}
+
+func listTarget(listType pihole.ListType, addresses ...string) string {
+	return fmt.Sprintf("%s/%s", listType, strings.Join(addresses, ","))
+}
@@ -0,0 +1,21 @@
+package listservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+)
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
+
+type cluster interface {
+	GetLists(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetListsResponse]
+	AddList(ctx context.Context, opts pihole.AddListOptions) map[int64]*domain.NodeResult[pihole.AddListResponse]
+	UpdateList(ctx context.Context, opts pihole.UpdateListOptions) map[int64]*domain.NodeResult[pihole.UpdateListResponse]
+	RemoveList(ctx context.Context, opts pihole.RemoveListOptions) map[int64]*domain.NodeResult[pihole.RemoveListResponse]
+	GetGroups(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+}
@@ -0,0 +1,83 @@
+package listservice
+
+import (
+	"sort"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/nodemerge"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type listKey struct {
+	Type    string
+	Address string
+}
+
+// mergeLists lines up each reachable node's lists by type and address. Group ids are translated to
+// names using the same node's groups, since ids differ between nodes.
+func mergeLists(results map[int64]*domain.NodeResult[pihole.GetListsResponse], groups map[int64]*domain.NodeResult[pihole.GetGroupsResponse]) *MergedLists {
+	nodes, unreachable, responses := nodemerge.Split(results)
+	merged := &MergedLists{
+		Nodes:            nodes,
+		UnreachableNodes: unreachable,
+		Lists:            []MergedList{},
+	}
+
+	listsByNode := make(map[int64]map[listKey]pihole.ListInfo, len(responses))
+	keys := make(map[listKey]struct{})
+	for id, response := range responses {
+		lists := make(map[listKey]pihole.ListInfo, len(response.Lists))
+		for _, list := range response.Lists {
+			key := listKey{Type: list.Type, Address: list.Address}
+			lists[key] = list
+			keys[key] = struct{}{}
+		}
+		listsByNode[id] = lists
+	}
+	groupNames := nodemerge.GroupNames(groups)
+
+	for key := range keys {
+		ml := MergedList{
+			Type:        key.Type,
+			Address:     key.Address,
+			PresentOn:   []domain.PiholeNodeRef{},
+			MissingFrom: []domain.PiholeNodeRef{},
+			States:      []ListNodeState{},
+		}
+		for _, node := range merged.Nodes {
+			list, ok := listsByNode[node.Id][key]
+			if !ok {
+				ml.MissingFrom = append(ml.MissingFrom, node)
+				continue
+			}
+			ml.PresentOn = append(ml.PresentOn, node)
+			ml.States = append(ml.States, ListNodeState{
+				PiholeNode:     node,
+				Id:             list.Id,
+				Enabled:        list.Enabled,
+				Comment:        list.Comment,
+				Groups:         nodemerge.SortedGroups(list.Groups),
+				GroupNames:     nodemerge.NamesFor(list.Groups, groupNames[node.Id]),
+				Number:         list.Number,
+				InvalidDomains: list.InvalidDomains,
+				DateUpdated:    list.DateUpdated,
+				Status:         list.Status,
+			})
+		}
+		ml.OnAllNodes = len(ml.MissingFrom) == 0
+		ml.Conflicts = nodemerge.Conflicts(ml.States, func(state ListNodeState) nodemerge.Fields {
+			return nodemerge.Fields{Enabled: state.Enabled, Comment: state.Comment, Groups: state.GroupNames}
+		})
+		merged.Lists = append(merged.Lists, ml)
+	}
+
+	sort.Slice(merged.Lists, func(i, j int) bool {
+		a, b := merged.Lists[i], merged.Lists[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Address < b.Address
+	})
+
+	return merged
+}
@@ -0,0 +1,126 @@
+package listservice
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	cluster cluster
+	auditor auditor
+	logger  zerolog.Logger
+}
+
+func NewService(cluster cluster, auditor auditor, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context) map[int64]*domain.NodeResult[pihole.GetListsResponse] {
+	return s.cluster.GetLists(ctx)
+}
+
+// GetMerged fetches every node's lists along with its groups, so group assignments can be compared
+// by name.
+func (s *Service) GetMerged(ctx context.Context) *MergedLists {
+	var (
+		lists  map[int64]*domain.NodeResult[pihole.GetListsResponse]
+		groups map[int64]*domain.NodeResult[pihole.GetGroupsResponse]
+		wg     sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		lists = s.cluster.GetLists(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		groups = s.cluster.GetGroups(ctx)
+	}()
+	wg.Wait()
+
+	return mergeLists(lists, groups)
+}
+
+// ValidateAdd checks that every address is a URL Pi-hole can download a list from.
+func (s *Service) ValidateAdd(opts pihole.AddListOptions) error {
+	addresses := payloadAddresses(opts.Payload)
+	if len(addresses) == 0 {
+		return httpx.NewHttpError(httpx.ErrValidation, "at least one address is required")
+	}
+	for _, address := range addresses {
+		if err := validateAddress(address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) Add(ctx context.Context, opts pihole.AddListOptions) (map[int64]*domain.NodeResult[pihole.AddListResponse], error) {
+	if err := s.ValidateAdd(opts); err != nil {
+		return nil, err
+	}
+	results := s.cluster.AddList(ctx, opts)
+	s.auditAdd(ctx, opts, auditservice.NodeOutcomes(results))
+	return results, nil
+}
+
+func (s *Service) Update(ctx context.Context, opts pihole.UpdateListOptions) map[int64]*domain.NodeResult[pihole.UpdateListResponse] {
+	results := s.cluster.UpdateList(ctx, opts)
+	s.auditUpdate(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func (s *Service) SetEnabled(ctx context.Context, listType pihole.ListType, address string, enabled bool) map[int64]*domain.NodeResult[pihole.UpdateListResponse] {
+	return s.Update(ctx, pihole.UpdateListOptions{
+		Type:    listType,
+		Address: address,
+		Payload: pihole.UpdateListPayload{Enabled: &enabled},
+	})
+}
+
+func (s *Service) Remove(ctx context.Context, opts pihole.RemoveListOptions) map[int64]*domain.NodeResult[pihole.RemoveListResponse] {
+	results := s.cluster.RemoveList(ctx, opts)
+	s.auditRemove(ctx, opts, auditservice.NodeOutcomes(results))
+	return results
+}
+
+func payloadAddresses(payload pihole.AddListPayload) []string {
+	switch v := payload.Address.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
+
+func validateAddress(address string) error {
+	u, err := url.Parse(strings.TrimSpace(address))
+	if err != nil {
+		return httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("invalid address %q: %v", address, err))
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("invalid address %q: missing host", address))
+		}
+	case "file":
+	default:
+		return httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("invalid address %q: must be an http, https or file URL", address))
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package listservice
+
+import "github.com/auto-dns/pihole-cluster-admin/internal/domain"
+
+// MergedLists shows every list known to any reachable node and where it is configured.
+type MergedLists struct {
+	Nodes            []domain.PiholeNodeRef   `json:"nodes"`
+	UnreachableNodes []domain.UnreachableNode `json:"unreachableNodes"`
+	Lists            []MergedList             `json:"lists"`
+}
+
+type MergedList struct {
+	Type        string                 `json:"type"`
+	Address     string                 `json:"address"`
+	OnAllNodes  bool                   `json:"onAllNodes"`
+	PresentOn   []domain.PiholeNodeRef `json:"presentOn"`
+	MissingFrom []domain.PiholeNodeRef `json:"missingFrom"`
+	Conflicts   []string               `json:"conflicts"` // fields that differ between nodes: enabled, comment, groups
+	States      []ListNodeState        `json:"states"`
+}
+
+// ListNodeState is a list as one node has it. Groups are that node's own ids; GroupNames are what
+// they are compared by.
+type ListNodeState struct {
+	PiholeNode     domain.PiholeNodeRef `json:"piholeNode"`
+	Id             int                  `json:"id"`
+	Enabled        bool                 `json:"enabled"`
+	Comment        *string              `json:"comment,omitempty"`
+	Groups         []int                `json:"groups"`
+	GroupNames     []string             `json:"groupNames"`
+	Number         int64                `json:"number"`
+	InvalidDomains int64                `json:"invalidDomains"`
+	DateUpdated    int64                `json:"dateUpdated"`
+	Status         int                  `json:"status"`
+}
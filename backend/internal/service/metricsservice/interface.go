@@ -0,0 +1,5 @@
+package metricsservice
+
+type broker interface {
+	SubscriberCount() int64
+}
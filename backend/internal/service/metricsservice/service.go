@@ -0,0 +1,153 @@
+package metricsservice
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/metrics"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/healthservice"
+	"github.com/rs/zerolog"
+)
+
+const namespace = "pihole_cluster_admin_"
+
+// Service owns the exported metrics. Counters and histograms are fed by the cluster, its clients and
+// the HTTP middleware; gauges are computed at scrape time from the latest health sweep and the broker.
+type Service struct {
+	registry          *metrics.Registry
+	broker            broker
+	logger            zerolog.Logger
+	fanOutDuration    *metrics.HistogramVec
+	nodeRequests      *metrics.CounterVec
+	nodeRequestErrors *metrics.CounterVec
+	httpDuration      *metrics.HistogramVec
+	mu                sync.RWMutex
+	nodeHealth        []healthservice.NodeHealth
+}
+
+func NewService(broker broker, logger zerolog.Logger) *Service {
+	registry := metrics.NewRegistry()
+	s := &Service{
+		registry: registry,
+		broker:   broker,
+		logger:   logger,
+		fanOutDuration: registry.NewHistogramVec(namespace+"fanout_duration_seconds",
+			"Time taken by a cluster operation to reach every targeted node, by operation.", metrics.DefaultBuckets, "operation"),
+		nodeRequests: registry.NewCounterVec(namespace+"node_requests_total",
+			"Requests sent to a Pi-hole node.", "node_id"),
+		nodeRequestErrors: registry.NewCounterVec(namespace+"node_request_errors_total",
+			"Requests to a Pi-hole node that failed or returned a non-2xx status.", "node_id"),
+		httpDuration: registry.NewHistogramVec(namespace+"http_request_duration_seconds",
+			"Latency of HTTP requests served, by route pattern.", metrics.DefaultBuckets, "method", "route", "status"),
+	}
+
+	nodeLabels := []string{"node_id", "node_name"}
+	registry.NewGaugeFunc(namespace+"node_up",
+		"Whether the node was online at the last health sweep.", nodeLabels,
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			emit(boolToFloat(nh.Status == healthservice.StatusOnline), id, nh.Name)
+		}))
+	registry.NewGaugeFunc(namespace+"node_status",
+		"Node status at the last health sweep, one series per possible status.", append(nodeLabels, "status"),
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			for _, status := range []healthservice.Status{healthservice.StatusOnline, healthservice.StatusDegraded, healthservice.StatusOffline} {
+				emit(boolToFloat(nh.Status == status), id, nh.Name, string(status))
+			}
+		}))
+	registry.NewGaugeFunc(namespace+"node_latency_seconds",
+		"Processing time reported by the node at the last health sweep.", nodeLabels,
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			emit(float64(nh.LatencyMS)/1000, id, nh.Name)
+		}))
+	registry.NewGaugeFunc(namespace+"node_error_info",
+		"Last error seen for a node; only present while the node is failing.", append(nodeLabels, "error"),
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			if nh.LastErr != "" {
+				emit(1, id, nh.Name, nh.LastErr)
+			}
+		}))
+	registry.NewGaugeFunc(namespace+"node_check_passing",
+		"Whether each health check passed at the last health sweep; skipped checks are left out.", append(nodeLabels, "check"),
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			for _, check := range nh.Checks {
+				if check.Status != healthservice.CheckSkipped {
+					emit(boolToFloat(check.Status == healthservice.CheckPass), id, nh.Name, check.Name)
+				}
+			}
+		}))
+	registry.NewGaugeFunc(namespace+"node_health_updated_timestamp_seconds",
+		"Unix time of the node's last health sweep.", nodeLabels,
+		s.collectNodes(func(emit func(float64, ...string), nh healthservice.NodeHealth, id string) {
+			if !nh.UpdatedAt.IsZero() {
+				emit(float64(nh.UpdatedAt.UnixNano())/1e9, id, nh.Name)
+			}
+		}))
+	registry.NewGaugeFunc(namespace+"sse_subscribers",
+		"Open server-sent event connections.", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(s.broker.SubscriberCount()))
+		})
+
+	return s
+}
+
+func (s *Service) ObserveFanOut(op string, d time.Duration) {
+	s.fanOutDuration.Observe(d.Seconds(), op)
+}
+
+func (s *Service) ObserveNodeRequest(nodeId int64, failed bool) {
+	id := strconv.FormatInt(nodeId, 10)
+	s.nodeRequests.Inc(id)
+	if failed {
+		s.nodeRequestErrors.Inc(id)
+	} else {
+		// Make sure the error series exists, so rate() works before the first failure
+		s.nodeRequestErrors.Add(0, id)
+	}
+}
+
+// RemoveNode drops a removed node's request counters, so its series do not linger.
+func (s *Service) RemoveNode(nodeId int64) {
+	id := strconv.FormatInt(nodeId, 10)
+	s.nodeRequests.Delete(id)
+	s.nodeRequestErrors.Delete(id)
+}
+
+func (s *Service) ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	s.httpDuration.Observe(d.Seconds(), method, route, strconv.Itoa(status))
+}
+
+// SetNodeHealth replaces the node health snapshot exported as gauges.
+func (s *Service) SetNodeHealth(nodes []healthservice.NodeHealth) {
+	sorted := make([]healthservice.NodeHealth, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodeHealth = sorted
+}
+
+func (s *Service) WriteMetrics(w io.Writer) error {
+	return s.registry.Write(w)
+}
+
+func (s *Service) collectNodes(f func(emit func(float64, ...string), nh healthservice.NodeHealth, id string)) func(emit func(float64, ...string)) {
+	return func(emit func(float64, ...string)) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		for _, nh := range s.nodeHealth {
+			f(emit, nh, strconv.FormatInt(nh.Id, 10))
+		}
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
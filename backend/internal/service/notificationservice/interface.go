@@ -0,0 +1,20 @@
+package notificationservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+)
+
+type deliveryStore interface {
+	AddNotificationDelivery(params store.AddNotificationDeliveryParams) error
+	GetNotificationDeliveries(params store.GetNotificationDeliveriesParams) ([]*domain.NotificationDelivery, int64, error)
+	RemoveNotificationDeliveriesBefore(cutoff time.Time) (int64, error)
+}
+
+type notifier interface {
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
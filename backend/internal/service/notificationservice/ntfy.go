@@ -0,0 +1,64 @@
+package notificationservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+)
+
+// ntfyNotifier publishes to an ntfy-style topic: the body is the message, and the title, priority
+// and tags travel as headers.
+type ntfyNotifier struct {
+	cfg  config.NtfyNotifierConfig
+	http *http.Client
+}
+
+func newNtfyNotifier(cfg config.NtfyNotifierConfig, httpClient *http.Client) *ntfyNotifier {
+	return &ntfyNotifier{cfg: cfg, http: httpClient}
+}
+
+func (n *ntfyNotifier) Channel() string {
+	return ChannelNtfy
+}
+
+func (n *ntfyNotifier) Send(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, strings.NewReader(msg.Body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "pihole-cluster-admin/6")
+	req.Header.Set("Title", msg.Title)
+
+	priority, tags := n.cfg.Priority, "warning"
+	switch msg.Event {
+	case EventNodeOnline:
+		// Recoveries are good news and need not wake anyone up
+		priority, tags = "default", "white_check_mark"
+	case EventNodeOffline:
+		tags = "rotating_light"
+	case EventTest:
+		priority, tags = "low", "test_tube"
+	}
+	if priority != "" {
+		req.Header.Set("Priority", priority)
+	}
+	req.Header.Set("Tags", tags)
+	if n.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.Token)
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package notificationservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultListLimit      = 50
+	maxListLimit          = 500
+	deliveryPruneInterval = time.Hour
+)
+
+type Service struct {
+	notifiers     []notifier
+	deliveryStore deliveryStore
+	cfg           config.NotificationsConfig
+	logger        zerolog.Logger
+
+	nodeMu   sync.Mutex
+	nodeLast map[int64]chan struct{} // per node, closed when its latest transition has been sent
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+func NewService(cfg config.NotificationsConfig, deliveryStore deliveryStore, logger zerolog.Logger) *Service {
+	// Timeouts come from the per-attempt context
+	httpClient := &http.Client{}
+
+	var notifiers []notifier
+	if cfg.Webhook.Enabled {
+		notifiers = append(notifiers, newWebhookNotifier(cfg.Webhook, httpClient))
+	}
+	if cfg.SMTP.Enabled {
+		notifiers = append(notifiers, newSMTPNotifier(cfg.SMTP))
+	}
+	if cfg.Ntfy.Enabled {
+		notifiers = append(notifiers, newNtfyNotifier(cfg.Ntfy, httpClient))
+	}
+
+	return &Service{
+		notifiers:     notifiers,
+		deliveryStore: deliveryStore,
+		cfg:           cfg,
+		logger:        logger,
+		nodeLast:      make(map[int64]chan struct{}),
+	}
+}
+
+// NotifyTransition sends a node status change on every enabled channel in the background, retrying
+// failed deliveries. A node's transitions are sent one after another in the order they were reported,
+// so a slow retry cannot let "back online" arrive before the "offline" it follows.
+func (s *Service) NotifyTransition(ctx context.Context, t domain.NodeStatusTransition) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+	msg := transitionMessage(t)
+	id := t.PiholeNode.Id
+
+	s.nodeMu.Lock()
+	previous := s.nodeLast[id]
+	done := make(chan struct{})
+	s.nodeLast[id] = done
+	s.nodeMu.Unlock()
+
+	go func() {
+		defer func() {
+			close(done)
+			s.nodeMu.Lock()
+			if s.nodeLast[id] == done {
+				delete(s.nodeLast, id)
+			}
+			s.nodeMu.Unlock()
+		}()
+		if previous != nil {
+			select {
+			case <-previous:
+			case <-ctx.Done():
+				return
+			}
+		}
+		s.dispatch(ctx, msg, s.cfg.MaxAttempts)
+	}()
+}
+
+// SendTest sends a test message on every enabled channel, once, and reports how each went.
+func (s *Service) SendTest(ctx context.Context) (*TestResult, error) {
+	if len(s.notifiers) == 0 {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "no notification channels are enabled")
+	}
+	msg := Message{
+		Event:  EventTest,
+		Title:  "Pi-hole cluster admin test notification",
+		Body:   "Notifications from Pi-hole cluster admin are working.",
+		SentAt: time.Now().UTC(),
+	}
+	return &TestResult{Channels: s.dispatch(ctx, msg, 1)}, nil
+}
+
+func (s *Service) List(params ListParams) (*ListResult, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultListLimit
+	}
+	if params.Limit > maxListLimit {
+		params.Limit = maxListLimit
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	deliveries, total, err := s.deliveryStore.GetNotificationDeliveries(store.GetNotificationDeliveriesParams{
+		Channel: params.Channel,
+		Event:   params.Event,
+		Success: params.Success,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResult{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	}, nil
+}
+
+// dispatch delivers the message on every channel concurrently, so a slow mail server does not hold
+// up the push notification.
+func (s *Service) dispatch(ctx context.Context, msg Message, maxAttempts int) []ChannelResult {
+	results := make([]ChannelResult, 0, len(s.notifiers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, n := range s.notifiers {
+		n := n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.deliver(ctx, n, msg, maxAttempts)
+			mu.Lock()
+			results = append(results, ChannelResult{Channel: n.Channel(), Success: err == nil, Error: util.ErrorString(err)})
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Channel < results[j].Channel })
+	return results
+}
+
+// deliver sends on one channel, recording every attempt, and returns the last error.
+func (s *Service) deliver(ctx context.Context, n notifier, msg Message, maxAttempts int) error {
+	logger := s.logger.With().Str("channel", n.Channel()).Str("event", msg.Event).Logger()
+	timeout := time.Duration(s.cfg.TimeoutSeconds) * time.Second
+	retryDelay := time.Duration(s.cfg.RetryDelaySeconds) * time.Second
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = n.Send(attemptCtx, msg)
+		cancel()
+
+		s.recordDelivery(n.Channel(), msg, attempt, err)
+		if err == nil {
+			logger.Debug().Int("attempt", attempt).Msg("notification delivered")
+			return nil
+		}
+		logger.Warn().Err(err).Int("attempt", attempt).Int("max_attempts", maxAttempts).Msg("notification delivery failed")
+
+		if attempt < maxAttempts {
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return err
+			}
+		}
+	}
+	return err
+}
+
+// recordDelivery persists a delivery attempt. A failure to record never affects the delivery itself.
+func (s *Service) recordDelivery(channel string, msg Message, attempt int, err error) {
+	params := store.AddNotificationDeliveryParams{
+		Channel: channel,
+		Event:   msg.Event,
+		Subject: msg.Title,
+		Attempt: attempt,
+		Success: err == nil,
+	}
+	if msg.Transition != nil {
+		params.PiholeId = &msg.Transition.PiholeNode.Id
+		params.PiholeName = &msg.Transition.PiholeNode.Name
+	}
+	if err != nil {
+		errString := err.Error()
+		params.Error = &errString
+	}
+	if err := s.deliveryStore.AddNotificationDelivery(params); err != nil {
+		s.logger.Error().Err(err).Str("channel", channel).Str("event", msg.Event).Msg("error recording notification delivery")
+	}
+	s.pruneDeliveries()
+}
+
+// pruneDeliveries removes delivery records older than the retention period, at most once an hour.
+func (s *Service) pruneDeliveries() {
+	s.pruneMu.Lock()
+	defer s.pruneMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) < deliveryPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	cutoff := now.Add(-time.Duration(s.cfg.RetentionDays) * 24 * time.Hour)
+	if removed, err := s.deliveryStore.RemoveNotificationDeliveriesBefore(cutoff); err != nil {
+		s.logger.Error().Err(err).Msg("error pruning notification deliveries")
+	} else if removed > 0 {
+		s.logger.Debug().Int64("removed", removed).Msg("pruned notification deliveries")
+	}
+}
+
+func transitionMessage(t domain.NodeStatusTransition) Message {
+	name := t.PiholeNode.Name
+	if name == "" {
+		name = fmt.Sprintf("node %d", t.PiholeNode.Id)
+	}
+	from := t.From
+	if from == "" {
+		from = "unknown"
+	}
+
+	msg := Message{
+		Event:      "node_" + t.To,
+		Transition: &t,
+		SentAt:     time.Now().UTC(),
+	}
+	switch t.To {
+	case "online":
+		msg.Title = fmt.Sprintf("Pi-hole %s is back online", name)
+	default:
+		msg.Title = fmt.Sprintf("Pi-hole %s is %s", name, t.To)
+	}
+	msg.Body = fmt.Sprintf("%s went from %s to %s at %s.", name, from, t.To, t.At.UTC().Format(time.RFC3339))
+	if t.Error != "" {
+		msg.Body += "\nLast error: " + t.Error
+	}
+	return msg
+}
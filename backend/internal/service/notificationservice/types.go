@@ -0,0 +1,54 @@
+package notificationservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+)
+
+const (
+	ChannelWebhook = "webhook"
+	ChannelSMTP    = "smtp"
+	ChannelNtfy    = "ntfy"
+)
+
+const (
+	EventNodeOnline   = "node_online"
+	EventNodeDegraded = "node_degraded"
+	EventNodeOffline  = "node_offline"
+	EventTest         = "test"
+)
+
+// Message is what every notifier renders. The webhook notifier posts it as JSON.
+type Message struct {
+	Event      string                       `json:"event"`
+	Title      string                       `json:"title"`
+	Body       string                       `json:"body"`
+	Transition *domain.NodeStatusTransition `json:"transition,omitempty"`
+	SentAt     time.Time                    `json:"sentAt"`
+}
+
+type ListParams struct {
+	Channel *string
+	Event   *string
+	Success *bool
+	Limit   int
+	Offset  int
+}
+
+type ListResult struct {
+	Deliveries []*domain.NotificationDelivery `json:"deliveries"`
+	Total      int64                          `json:"total"`
+	Limit      int                            `json:"limit"`
+	Offset     int                            `json:"offset"`
+}
+
+type ChannelResult struct {
+	Channel string `json:"channel"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type TestResult struct {
+	Channels []ChannelResult `json:"channels"`
+}
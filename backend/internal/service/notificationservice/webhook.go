@@ -0,0 +1,53 @@
+package notificationservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+)
+
+// webhookNotifier posts the message as JSON to a configured URL.
+type webhookNotifier struct {
+	cfg  config.WebhookNotifierConfig
+	http *http.Client
+}
+
+func newWebhookNotifier(cfg config.WebhookNotifierConfig, httpClient *http.Client) *webhookNotifier {
+	return &webhookNotifier{cfg: cfg, http: httpClient}
+}
+
+func (n *webhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pihole-cluster-admin/6")
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
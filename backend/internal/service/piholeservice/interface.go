@@ -5,6 +5,7 @@ import (
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	p "github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 )
 
@@ -23,3 +24,7 @@ type piholeStore interface {
 	UpdatePiholeNode(id int64, params store.UpdatePiholeParams) (*domain.PiholeNode, error)
 	RemovePiholeNode(id int64) (bool, error)
 }
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
@@ -2,27 +2,33 @@ package piholeservice
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+	"github.com/auto-dns/pihole-cluster-admin/internal/util"
 	"github.com/rs/zerolog"
 )
 
 type Service struct {
 	cluster     cluster
 	piholeStore piholeStore
+	auditor     auditor
 	logger      zerolog.Logger
 }
 
-func NewService(cluster cluster, piholeStore piholeStore, logger zerolog.Logger) *Service {
+func NewService(cluster cluster, piholeStore piholeStore, auditor auditor, logger zerolog.Logger) *Service {
 	return &Service{
 		cluster:     cluster,
 		piholeStore: piholeStore,
+		auditor:     auditor,
 		logger:      logger,
 	}
 }
@@ -32,6 +38,25 @@ func (s *Service) GetAll() ([]*domain.PiholeNode, error) {
 }
 
 func (s *Service) Add(ctx context.Context, params store.AddPiholeParams) (*domain.PiholeNode, error) {
+	insertedNode, err := s.add(ctx, params)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionPiholeAdd,
+		Target: params.Name,
+		Details: piholeAuditDetails{
+			Scheme:          &params.Scheme,
+			Host:            &params.Host,
+			Port:            &params.Port,
+			Name:            &params.Name,
+			Description:     &params.Description,
+			PasswordChanged: true,
+		},
+		NodeOutcomes: nodeOutcomes(insertedNode, 0, err),
+		Success:      err == nil,
+	})
+	return insertedNode, err
+}
+
+func (s *Service) add(ctx context.Context, params store.AddPiholeParams) (*domain.PiholeNode, error) {
 	insertedNode, err := s.piholeStore.AddPiholeNode(params)
 	if err != nil {
 		return nil, parseSqlError(err)
@@ -60,6 +85,29 @@ func (s *Service) Add(ctx context.Context, params store.AddPiholeParams) (*domai
 }
 
 func (s *Service) Update(ctx context.Context, id int64, params store.UpdatePiholeParams) (*domain.PiholeNode, error) {
+	updatedNode, err := s.update(ctx, id, params)
+	target := fmt.Sprintf("%d", id)
+	if updatedNode != nil {
+		target = updatedNode.Name
+	}
+	s.auditor.Record(ctx, auditservice.Event{
+		Action: auditservice.ActionPiholeUpdate,
+		Target: target,
+		Details: piholeAuditDetails{
+			Scheme:          params.Scheme,
+			Host:            params.Host,
+			Port:            params.Port,
+			Name:            params.Name,
+			Description:     params.Description,
+			PasswordChanged: params.Password != nil,
+		},
+		NodeOutcomes: nodeOutcomes(updatedNode, id, err),
+		Success:      err == nil,
+	})
+	return updatedNode, err
+}
+
+func (s *Service) update(ctx context.Context, id int64, params store.UpdatePiholeParams) (*domain.PiholeNode, error) {
 	updatedNode, err := s.piholeStore.UpdatePiholeNode(id, params)
 	if err != nil {
 		return nil, parseSqlError(err)
@@ -88,6 +136,31 @@ func (s *Service) Update(ctx context.Context, id int64, params store.UpdatePihol
 }
 
 func (s *Service) Remove(ctx context.Context, id int64) (bool, error) {
+	// Look the node up first so the audit entry can name it after it is gone
+	var node *domain.PiholeNode
+	if existing, err := s.piholeStore.GetPiholeNode(id); err == nil {
+		node = existing
+	}
+
+	found, err := s.remove(ctx, id)
+	target := fmt.Sprintf("%d", id)
+	if node != nil {
+		target = node.Name
+	}
+	outcomeErr := err
+	if err == nil && !found {
+		outcomeErr = errors.New("pihole not found")
+	}
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:       auditservice.ActionPiholeRemove,
+		Target:       target,
+		NodeOutcomes: nodeOutcomes(node, id, outcomeErr),
+		Success:      outcomeErr == nil,
+	})
+	return found, err
+}
+
+func (s *Service) remove(ctx context.Context, id int64) (bool, error) {
 	found, err := s.piholeStore.RemovePiholeNode(id)
 	if err != nil {
 		return false, err
@@ -183,6 +256,18 @@ func (s *Service) TestExistingConnection(ctx context.Context, id int64, params T
 	return nil
 }
 
+func nodeOutcomes(node *domain.PiholeNode, id int64, err error) []domain.AuditNodeOutcome {
+	ref := domain.PiholeNodeRef{Id: id}
+	if node != nil {
+		ref = domain.PiholeNodeRef{Id: node.Id, Name: node.Name, Host: node.Host}
+	}
+	return []domain.AuditNodeOutcome{{
+		PiholeNode: ref,
+		Success:    err == nil,
+		Error:      util.ErrorString(err),
+	}}
+}
+
 func parseSqlError(err error) error {
 	if strings.Contains(err.Error(), "piholes.host") {
 		return httpx.NewHttpError(httpx.ErrValidation, "duplicate host:port")
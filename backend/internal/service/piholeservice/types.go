@@ -13,3 +13,13 @@ type TestInstanceConnectionParams struct {
 	Port     int    `json:"port"`
 	Password string `json:"password"`
 }
+
+// Passwords are never written to the audit log, only whether one was set
+type piholeAuditDetails struct {
+	Scheme          *string `json:"scheme,omitempty"`
+	Host            *string `json:"host,omitempty"`
+	Port            *int    `json:"port,omitempty"`
+	Name            *string `json:"name,omitempty"`
+	Description     *string `json:"description,omitempty"`
+	PasswordChanged bool    `json:"passwordChanged"`
+}
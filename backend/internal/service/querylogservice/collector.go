@@ -0,0 +1,263 @@
+package querylogservice
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/realtime"
+)
+
+// maxPagesPerPoll bounds how much one poll reads from a node, so a node with a large backlog is
+// caught up over several polls instead of stalling the others.
+const maxPagesPerPoll = 20
+
+// Start runs the collector, which copies each node's query log into the local store and drops
+// entries older than the retention window. Newly stored entries are published on the query log topic.
+// While the topic has subscribers, nodes are polled at the stream interval, whether or not the
+// collector is enabled; with the collector disabled, nodes are not polled at all without subscribers.
+func (s *Service) Start(ctx context.Context) {
+	if s.cfg.CollectorEnabled {
+		s.logger.Info().Msg("Starting query log collector")
+		// Backfilled history is not news to anyone subscribed
+		s.streamFrom = float64(time.Now().Unix())
+		s.collectOnce(ctx)
+	} else {
+		s.logger.Info().Msg("Query log collector disabled, polling only for query log subscribers")
+	}
+	s.loop(ctx)
+}
+
+func (s *Service) loop(ctx context.Context) {
+	streamInterval := time.Duration(s.cfg.Stream.PollingIntervalSeconds) * time.Second
+	stopGracePeriod := time.Duration(s.cfg.Stream.GracePeriodSeconds) * time.Second
+	subscribersChanged := s.broker.TopicSubscribersChanged(realtime.TopicQueryLog)
+	subscribed := func() bool {
+		return s.broker.TopicSubscriberCount(realtime.TopicQueryLog) > 0
+	}
+
+	// With the collector enabled, the idle state keeps collecting at the collector interval
+	var idleTick <-chan time.Time
+	if s.cfg.CollectorEnabled {
+		idleTicker := time.NewTicker(time.Duration(s.cfg.PollingIntervalSeconds) * time.Second)
+		defer idleTicker.Stop()
+		idleTick = idleTicker.C
+	}
+
+	for {
+		// Idle state when no subscribers
+		if !subscribed() {
+			select {
+			case <-subscribersChanged:
+				if !subscribed() {
+					continue
+				}
+			case <-idleTick:
+				s.collectOnce(ctx)
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// Active: at least one subscriber. Without the collector there is no stored history to catch up
+		// on, so only entries logged from now on are read and streamed.
+		if !s.cfg.CollectorEnabled {
+			s.streamFrom = float64(time.Now().Unix())
+		}
+		s.collectOnce(ctx)
+		ticker := time.NewTicker(streamInterval)
+		running := true
+
+		for running {
+			select {
+			case <-ticker.C:
+				s.collectOnce(ctx)
+
+			case <-subscribersChanged:
+				if subscribed() {
+					continue
+				}
+				if stopGracePeriod == 0 {
+					running = false
+					continue
+				}
+				g := time.NewTimer(stopGracePeriod)
+				select {
+				case <-g.C:
+					running = subscribed()
+				case <-subscribersChanged:
+					// Subscribers came back during the grace period, keep running
+				case <-ctx.Done():
+					g.Stop()
+					ticker.Stop()
+					return
+				}
+				g.Stop()
+
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+
+		ticker.Stop()
+	}
+}
+
+func (s *Service) collectOnce(ctx context.Context) {
+	var batch []pihole.MergedDNSLogEntry
+	for _, node := range s.cluster.GetNodes(ctx) {
+		if ctx.Err() != nil {
+			return
+		}
+		for _, e := range s.collectNode(ctx, node) {
+			if e.Time >= s.streamFrom {
+				batch = append(batch, pihole.MergedDNSLogEntry{DNSLogEntry: toDNSLogEntry(&e), PiholeNode: node})
+			}
+		}
+	}
+	s.publish(batch)
+
+	cutoff := time.Now().Add(-time.Duration(s.cfg.RetentionHours) * time.Hour)
+	removed, err := s.queryLogStore.RemoveQueryLogEntriesBefore(float64(cutoff.Unix()))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("error pruning query log")
+		return
+	}
+	if removed > 0 {
+		s.logger.Debug().Int64("removed", removed).Msg("pruned query log")
+	}
+}
+
+// collectNode reads everything a node logged since the newest stored entry. Entries are read newest
+// first, pinned by the cursor the node returns for the first page. Entries in the second of the
+// newest stored entry are read again and skipped by the store. A read that does not finish within
+// maxPagesPerPoll pages, or fails partway, resumes from its position on the next poll. The position is
+// stored before the first page is, since once the newest entries are stored the newest stored entry
+// no longer marks where the read began. It returns the entries that were new.
+func (s *Service) collectNode(ctx context.Context, node domain.PiholeNodeRef) []domain.QueryLogEntry {
+	logger := s.logger.With().Int64("id", node.Id).Logger()
+
+	position, err := s.queryLogStore.GetQueryLogCollectPosition(node.Id)
+	if err != nil {
+		logger.Error().Err(err).Msg("error getting query log collect position")
+		return nil
+	}
+	resuming := position != nil
+	if !resuming {
+		now := time.Now()
+		from := now.Add(-time.Duration(s.cfg.RetentionHours) * time.Hour).Unix()
+		if !s.cfg.CollectorEnabled {
+			// Streaming only: nothing before the stream started is wanted
+			from = max(from, int64(s.streamFrom))
+		}
+		latest, err := s.queryLogStore.GetLatestQueryLogTime(node.Id)
+		if err != nil {
+			logger.Error().Err(err).Msg("error getting latest stored query time")
+			return nil
+		}
+		if latest != nil && int64(math.Floor(*latest)) > from {
+			from = int64(math.Floor(*latest))
+		}
+		position = &domain.QueryLogCollectPosition{PiholeId: node.Id, From: from, Until: now.Unix()}
+	}
+
+	length := s.cfg.BatchSize
+	var cursor *int
+	if resuming {
+		cursor = &position.Cursor
+	}
+	var added []domain.QueryLogEntry
+	done := false
+	for page := 0; page < maxPagesPerPoll; page++ {
+		pageStart := position.Start
+		nr := s.cluster.FetchQueryLogsFromNode(ctx, node.Id, pihole.FetchQueryLogNodeRequest{
+			Filters: pihole.FetchQueryLogFilters{From: &position.From, Until: &position.Until},
+			Cursor:  cursor,
+			Length:  &length,
+			Start:   &pageStart,
+		})
+		if !nr.Success || nr.Response == nil {
+			// The node is offline; its backlog is collected once it is back
+			logger.Debug().Str("error", nr.ErrorString).Msg("skipping query log collection for node")
+			break
+		}
+		full := len(nr.Response.Queries) == length
+		if cursor == nil {
+			position.Cursor = nr.Response.Cursor
+			cursor = &position.Cursor
+			// A read that fits in one page needs no position
+			if full {
+				if err := s.queryLogStore.SetQueryLogCollectPosition(*position); err != nil {
+					logger.Error().Err(err).Msg("error storing query log collect position")
+					return added
+				}
+				resuming = true
+			}
+		}
+
+		entries := make([]domain.QueryLogEntry, 0, len(nr.Response.Queries))
+		for _, q := range nr.Response.Queries {
+			entries = append(entries, toQueryLogEntry(node.Id, q))
+		}
+		stored, err := s.queryLogStore.AddQueryLogEntries(entries)
+		if err != nil {
+			logger.Error().Err(err).Msg("error storing query log entries")
+			break
+		}
+		added = append(added, stored...)
+
+		if !full {
+			done = true
+			break
+		}
+		position.Start += len(nr.Response.Queries)
+	}
+
+	// A read without a stored position either failed its first page or fit in it, and the next poll
+	// can start from the newest stored entry either way
+	switch {
+	case done && resuming:
+		if err := s.queryLogStore.RemoveQueryLogCollectPosition(node.Id); err != nil {
+			logger.Error().Err(err).Msg("error removing query log collect position")
+		}
+	case resuming:
+		if err := s.queryLogStore.SetQueryLogCollectPosition(*position); err != nil {
+			logger.Error().Err(err).Msg("error storing query log collect position")
+		}
+		logger.Debug().Int("start", position.Start).Msg("query log backlog not caught up, resuming next poll")
+	}
+
+	if len(added) > 0 {
+		logger.Debug().Int("added", len(added)).Msg("collected query log entries")
+	}
+	return added
+}
+
+// publish sends newly collected entries to query log subscribers as one batch, oldest first.
+func (s *Service) publish(batch []pihole.MergedDNSLogEntry) {
+	if len(batch) == 0 || s.broker.TopicSubscriberCount(realtime.TopicQueryLog) == 0 {
+		return
+	}
+	sort.Slice(batch, func(i, j int) bool {
+		if batch[i].Time != batch[j].Time {
+			return batch[i].Time < batch[j].Time
+		}
+		if batch[i].PiholeNode.Id != batch[j].PiholeNode.Id {
+			return batch[i].PiholeNode.Id < batch[j].PiholeNode.Id
+		}
+		return batch[i].Id < batch[j].Id
+	})
+
+	b, err := json.Marshal(batch)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("error serializing query log batch for broadcasting")
+		return
+	}
+	s.broker.Publish(realtime.TopicQueryLog, b)
+}
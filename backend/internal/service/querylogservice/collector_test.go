@@ -0,0 +1,307 @@
+package querylogservice
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/rs/zerolog"
+)
+
+// fakeNodeLog serves one node's query log the way Pi-hole does: newest first, filtered by time and
+// limited to ids at or below the cursor.
+type fakeNodeLog struct {
+	cluster
+	queries []pihole.DNSLogEntry // oldest first
+	down    bool
+}
+
+func (f *fakeNodeLog) log(n int, at float64) {
+	for i := 0; i < n; i++ {
+		f.queries = append(f.queries, pihole.DNSLogEntry{Id: int64(len(f.queries) + 1), Time: at})
+	}
+}
+
+func (f *fakeNodeLog) FetchQueryLogsFromNode(ctx context.Context, id int64, req pihole.FetchQueryLogNodeRequest) *domain.NodeResult[pihole.FetchQueryLogResponse] {
+	if f.down {
+		return &domain.NodeResult[pihole.FetchQueryLogResponse]{PiholeNode: domain.PiholeNodeRef{Id: id}, ErrorString: "unreachable"}
+	}
+	cursor := len(f.queries)
+	if req.Cursor != nil {
+		cursor = *req.Cursor
+	}
+	var matching []pihole.DNSLogEntry
+	for i := cursor - 1; i >= 0; i-- {
+		q := f.queries[i]
+		if int64(q.Time) >= *req.Filters.From && int64(q.Time) <= *req.Filters.Until {
+			matching = append(matching, q)
+		}
+	}
+	start := min(*req.Start, len(matching))
+	end := min(start+*req.Length, len(matching))
+	return &domain.NodeResult[pihole.FetchQueryLogResponse]{
+		PiholeNode: domain.PiholeNodeRef{Id: id},
+		Success:    true,
+		Response:   &pihole.FetchQueryLogResponse{Queries: matching[start:end], Cursor: cursor},
+	}
+}
+
+// fakeQueryLogStore keeps entries by query id, skipping duplicates like the real store.
+type fakeQueryLogStore struct {
+	queryLogStore
+	entries   map[int64]domain.QueryLogEntry
+	positions map[int64]domain.QueryLogCollectPosition
+}
+
+func newFakeQueryLogStore() *fakeQueryLogStore {
+	return &fakeQueryLogStore{entries: make(map[int64]domain.QueryLogEntry), positions: make(map[int64]domain.QueryLogCollectPosition)}
+}
+
+func (f *fakeQueryLogStore) GetQueryLogCollectPosition(piholeId int64) (*domain.QueryLogCollectPosition, error) {
+	position, ok := f.positions[piholeId]
+	if !ok {
+		return nil, nil
+	}
+	return &position, nil
+}
+
+func (f *fakeQueryLogStore) SetQueryLogCollectPosition(position domain.QueryLogCollectPosition) error {
+	f.positions[position.PiholeId] = position
+	return nil
+}
+
+func (f *fakeQueryLogStore) RemoveQueryLogCollectPosition(piholeId int64) error {
+	delete(f.positions, piholeId)
+	return nil
+}
+
+func (f *fakeQueryLogStore) AddQueryLogEntries(entries []domain.QueryLogEntry) ([]domain.QueryLogEntry, error) {
+	var added []domain.QueryLogEntry
+	for _, e := range entries {
+		if _, ok := f.entries[e.QueryId]; !ok {
+			f.entries[e.QueryId] = e
+			added = append(added, e)
+		}
+	}
+	return added, nil
+}
+
+func (f *fakeQueryLogStore) RemoveQueryLogEntriesBefore(cutoff float64) (int64, error) {
+	return 0, nil
+}
+
+type fakeBroker struct {
+	mu          sync.Mutex
+	subscribers int64
+	published   [][]byte
+	changed     chan struct{}
+}
+
+func (f *fakeBroker) TopicSubscriberCount(topic string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.subscribers
+}
+
+func (f *fakeBroker) TopicSubscribersChanged(topic string) <-chan struct{} { return f.changed }
+
+func (f *fakeBroker) Publish(topic string, payload []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, payload)
+}
+
+func (f *fakeBroker) subscribe() {
+	f.mu.Lock()
+	f.subscribers++
+	f.mu.Unlock()
+	f.changed <- struct{}{}
+}
+
+func (f *fakeQueryLogStore) GetLatestQueryLogTime(piholeId int64) (*float64, error) {
+	var latest *float64
+	for _, e := range f.entries {
+		if latest == nil || e.Time > *latest {
+			t := e.Time
+			latest = &t
+		}
+	}
+	return latest, nil
+}
+
+func TestCollectNodeCatchesUpBacklog(t *testing.T) {
+	const batchSize = 10
+	node := domain.PiholeNodeRef{Id: 1}
+	base := float64(time.Now().Add(-time.Hour).Unix())
+
+	tests := []struct {
+		name    string
+		backlog int
+		polls   []bool // whether the node is down during each poll
+		restart bool   // whether the service restarts before each poll
+	}{
+		{name: "fits in one poll", backlog: 5 * batchSize, polls: []bool{false}},
+		{name: "larger than one poll", backlog: 3*maxPagesPerPoll*batchSize + 7, polls: []bool{false, false, false, false}},
+		{name: "node drops out while catching up", backlog: 2*maxPagesPerPoll*batchSize + 3, polls: []bool{false, true, false, false}},
+		{name: "restarted while catching up", backlog: 3*maxPagesPerPoll*batchSize + 7, polls: []bool{false, false, false, false}, restart: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeLog := &fakeNodeLog{}
+			store := newFakeQueryLogStore()
+			cfg := config.QueryLogServiceConfig{RetentionHours: 24, BatchSize: batchSize}
+			s := NewService(nodeLog, &fakeBroker{}, store, nil, nil, cfg, zerolog.Nop())
+
+			// Spread the backlog over distinct seconds, then keep logging while the collector polls
+			for i := 0; i < tt.backlog; i++ {
+				nodeLog.log(1, base+float64(i/batchSize))
+			}
+			for _, down := range tt.polls {
+				if tt.restart {
+					s = NewService(nodeLog, &fakeBroker{}, store, nil, nil, cfg, zerolog.Nop())
+				}
+				nodeLog.down = down
+				s.collectNode(context.Background(), node)
+				nodeLog.log(3, float64(time.Now().Unix()))
+			}
+			nodeLog.down = false
+			s.collectNode(context.Background(), node)
+
+			if len(store.entries) != len(nodeLog.queries) {
+				t.Errorf("stored %d entries, node logged %d", len(store.entries), len(nodeLog.queries))
+			}
+			if _, ok := store.positions[node.Id]; ok {
+				t.Error("position kept after catching up")
+			}
+		})
+	}
+}
+
+// fakeNodes adds the node list to a node log, for tests that run a whole collection.
+type fakeNodes struct {
+	*fakeNodeLog
+}
+
+func (f fakeNodes) GetNodes(ctx context.Context) []domain.PiholeNodeRef {
+	return []domain.PiholeNodeRef{{Id: 1, Name: "one"}}
+}
+
+func TestCollectOncePublishesNewEntries(t *testing.T) {
+	started := float64(time.Now().Unix())
+
+	tests := []struct {
+		name        string
+		subscribers int64
+		want        []int64 // ids published in the one batch, or nil for no batch
+	}{
+		{name: "new entries are published once, oldest first", subscribers: 1, want: []int64{3, 4}},
+		{name: "nothing is published without subscribers", subscribers: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeLog := &fakeNodeLog{}
+			store := newFakeQueryLogStore()
+			broker := &fakeBroker{subscribers: tt.subscribers}
+			s := NewService(fakeNodes{nodeLog}, broker, store, nil, nil, config.QueryLogServiceConfig{RetentionHours: 24, BatchSize: 10}, zerolog.Nop())
+			s.streamFrom = started
+
+			// History from before the collector started is stored but not streamed
+			nodeLog.log(2, started-60)
+			nodeLog.log(2, started)
+			s.collectOnce(context.Background())
+			// Entries collected again are not new, so nothing more is published
+			s.collectOnce(context.Background())
+
+			if tt.want == nil {
+				if len(broker.published) != 0 {
+					t.Fatalf("published %d batches, want none", len(broker.published))
+				}
+				return
+			}
+			if len(broker.published) != 1 {
+				t.Fatalf("published %d batches, want 1", len(broker.published))
+			}
+			var batch []pihole.MergedDNSLogEntry
+			if err := json.Unmarshal(broker.published[0], &batch); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, e := range batch {
+				ids = append(ids, e.Id)
+				if e.PiholeNode.Name != "one" {
+					t.Errorf("entry %d node = %+v, want node one", e.Id, e.PiholeNode)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("published ids %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamPollsOnlyWhileSubscribed(t *testing.T) {
+	nodeLog := &fakeNodeLog{}
+	store := newFakeQueryLogStore()
+	broker := &fakeBroker{changed: make(chan struct{}, 1)}
+	cfg := config.QueryLogServiceConfig{
+		RetentionHours: 24,
+		BatchSize:      10,
+		Stream:         config.QueryLogStreamConfig{PollingIntervalSeconds: 60, GracePeriodSeconds: 0},
+	}
+	s := NewService(fakeNodes{nodeLog}, broker, store, nil, nil, cfg, zerolog.Nop())
+
+	// Logged before anyone subscribed, so neither read nor streamed
+	nodeLog.log(2, float64(time.Now().Add(-time.Minute).Unix()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	// Without the collector, an idle loop leaves the nodes alone
+	time.Sleep(50 * time.Millisecond)
+	if len(store.entries) != 0 {
+		t.Fatalf("stored %d entries without subscribers, want none", len(store.entries))
+	}
+
+	// Log and subscribe within the same second, so the entry is not older than the stream
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	nodeLog.log(1, float64(time.Now().Unix()))
+	broker.subscribe()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		broker.mu.Lock()
+		n := len(broker.published)
+		broker.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("nothing published after subscribing")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	var batch []pihole.MergedDNSLogEntry
+	if err := json.Unmarshal(broker.published[0], &batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 1 || batch[0].Id != 3 {
+		t.Errorf("published %+v, want only entry 3", batch)
+	}
+	if _, ok := store.entries[1]; ok {
+		t.Error("read entries logged before the stream started")
+	}
+}
@@ -0,0 +1,147 @@
+package querylogservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+// Explain resolves a query's status and list id into the rule or adlist that decided it on the node
+// that answered. For domain rule statuses the list id is a domain rule id; for gravity it is an adlist id.
+func (s *Service) Explain(ctx context.Context, params ExplainParams) (*Explanation, error) {
+	status := strings.ToUpper(strings.TrimSpace(params.Status))
+	if status == "" {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "status is required")
+	}
+
+	var node *domain.PiholeNodeRef
+	for _, n := range s.cluster.GetNodes(ctx) {
+		if n.Id == params.NodeId {
+			node = &n
+			break
+		}
+	}
+	if node == nil {
+		return nil, httpx.NewHttpError(httpx.ErrNotFound, fmt.Sprintf("node %d not found", params.NodeId))
+	}
+
+	explanation := &Explanation{
+		PiholeNode: *node,
+		Domain:     params.Domain,
+		Status:     status,
+		ListId:     params.ListId,
+	}
+	base := strings.TrimSuffix(status, "_CNAME")
+	explanation.ViaCNAME = base != status
+	via := ""
+	if explanation.ViaCNAME {
+		via = " via CNAME inspection"
+	}
+
+	switch base {
+	case "DENYLIST", "REGEX":
+		explanation.Blocked = true
+		explanation.Cause = BlockCauseExactDeny
+		kind := "exact deny rule"
+		if base == "REGEX" {
+			explanation.Cause = BlockCauseRegexDeny
+			kind = "regex deny rule"
+		}
+		rule, err := s.findDomainRule(ctx, explanation, params.ListId)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			explanation.DomainRule = rule
+			explanation.Summary = fmt.Sprintf("Blocked%s by %s %q", via, kind, rule.Domain)
+		} else {
+			explanation.Summary = fmt.Sprintf("Blocked%s by a %s that no longer exists on the node", via, kind)
+		}
+
+	case "GRAVITY":
+		explanation.Blocked = true
+		explanation.Cause = BlockCauseAdlist
+		list, err := s.findList(ctx, explanation, params.ListId)
+		if err != nil {
+			return nil, err
+		}
+		if list != nil {
+			explanation.List = list
+			explanation.Summary = fmt.Sprintf("Blocked%s by adlist %s", via, list.Address)
+		} else {
+			explanation.Summary = fmt.Sprintf("Blocked%s by an adlist that no longer exists on the node", via)
+		}
+
+	case "EXTERNAL_BLOCKED_IP", "EXTERNAL_BLOCKED_NULL", "EXTERNAL_BLOCKED_NXRA", "EXTERNAL_BLOCKED_EDE15":
+		explanation.Blocked = true
+		explanation.Cause = BlockCauseUpstream
+		explanation.Summary = "Blocked by the upstream DNS server, not by Pi-hole"
+
+	case "SPECIAL_DOMAIN":
+		explanation.Blocked = true
+		explanation.Cause = BlockCauseSpecial
+		explanation.Summary = "Blocked as a special domain, such as the Mozilla canary or iCloud Private Relay domains"
+
+	case "DBBUSY":
+		explanation.Blocked = true
+		explanation.Cause = BlockCauseDatabase
+		explanation.Summary = "Blocked because the gravity database was busy"
+
+	case "FORWARDED", "CACHE", "CACHE_STALE", "RETRIED", "RETRIED_DNSSEC", "IN_PROGRESS":
+		explanation.Cause = BlockCauseNone
+		explanation.Summary = "Not blocked"
+		// An allowed query with a list id was allowed by an allow rule overriding a block
+		if params.ListId != nil {
+			rule, err := s.findDomainRule(ctx, explanation, params.ListId)
+			if err != nil {
+				return nil, err
+			}
+			if rule != nil && rule.Type == string(pihole.RuleTypeAllow) {
+				explanation.DomainRule = rule
+				explanation.Summary = fmt.Sprintf("Not blocked: allowed by %s allow rule %q", rule.Kind, rule.Domain)
+			}
+		}
+
+	default:
+		explanation.Cause = BlockCauseUnknown
+		explanation.Summary = fmt.Sprintf("Unknown status %s", status)
+	}
+
+	return explanation, nil
+}
+
+func (s *Service) findDomainRule(ctx context.Context, explanation *Explanation, listId *int64) (*pihole.DomainInfo, error) {
+	if listId == nil {
+		return nil, nil
+	}
+	nr := s.cluster.GetDomainRulesFromNode(ctx, explanation.PiholeNode.Id)
+	if !nr.Success || nr.Response == nil {
+		return nil, httpx.NewHttpError(httpx.ErrInternalService, fmt.Sprintf("could not get domain rules from node %d: %s", explanation.PiholeNode.Id, nr.ErrorString))
+	}
+	for _, rule := range nr.Response.Domains {
+		if int64(rule.Id) == *listId {
+			return &rule, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Service) findList(ctx context.Context, explanation *Explanation, listId *int64) (*pihole.ListInfo, error) {
+	if listId == nil {
+		return nil, nil
+	}
+	nr := s.cluster.GetListsFromNode(ctx, explanation.PiholeNode.Id)
+	if !nr.Success || nr.Response == nil {
+		return nil, httpx.NewHttpError(httpx.ErrInternalService, fmt.Sprintf("could not get lists from node %d: %s", explanation.PiholeNode.Id, nr.ErrorString))
+	}
+	for _, list := range nr.Response.Lists {
+		if int64(list.Id) == *listId {
+			return &list, nil
+		}
+	}
+	return nil, nil
+}
@@ -3,9 +3,43 @@ package querylogservice
 import (
 	"context"
 
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 )
 
+type broker interface {
+	TopicSubscriberCount(topic string) int64
+	TopicSubscribersChanged(topic string) <-chan struct{}
+	Publish(topic string, payload []byte)
+}
+
 type cluster interface {
+	GetNodes(ctx context.Context) []domain.PiholeNodeRef
 	FetchQueryLogs(ctx context.Context, params pihole.FetchQueryLogClusterRequest) (*pihole.FetchQueryLogsClusterResponse, error)
+	FetchMergedQueryLogs(ctx context.Context, params pihole.FetchQueryLogClusterRequest) (*pihole.FetchMergedQueryLogsClusterResponse, error)
+	GetDomainRulesFromNode(ctx context.Context, id int64) *domain.NodeResult[pihole.GetDomainRulesResponse]
+	GetListsFromNode(ctx context.Context, id int64) *domain.NodeResult[pihole.GetListsResponse]
+	FetchQueryLogsFromNode(ctx context.Context, id int64, req pihole.FetchQueryLogNodeRequest) *domain.NodeResult[pihole.FetchQueryLogResponse]
+}
+
+type queryLogStore interface {
+	AddQueryLogEntries(entries []domain.QueryLogEntry) ([]domain.QueryLogEntry, error)
+	GetLatestQueryLogTime(piholeId int64) (*float64, error)
+	GetMaxQueryLogId() (int64, error)
+	GetQueryLogEntries(params store.GetQueryLogEntriesParams) ([]*domain.QueryLogEntry, error)
+	CountQueryLogEntriesByNode(params store.GetQueryLogEntriesParams) (map[int64]int64, error)
+	RemoveQueryLogEntriesBefore(cutoff float64) (int64, error)
+	GetQueryLogCollectPosition(piholeId int64) (*domain.QueryLogCollectPosition, error)
+	SetQueryLogCollectPosition(position domain.QueryLogCollectPosition) error
+	RemoveQueryLogCollectPosition(piholeId int64) error
+}
+
+type ruleEditor interface {
+	Add(ctx context.Context, opts pihole.AddDomainRuleOptions) (map[int64]*domain.NodeResult[pihole.AddDomainRuleResponse], error)
+	Remove(ctx context.Context, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]
+}
+
+type userGetter interface {
+	GetUser(id int64) (*domain.User, error)
 }
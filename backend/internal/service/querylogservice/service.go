@@ -2,23 +2,82 @@ package querylogservice
 
 import (
 	"context"
+	"time"
 
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
 	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
 	"github.com/rs/zerolog"
 )
 
 type Service struct {
-	cluster cluster
-	logger  zerolog.Logger
+	cluster       cluster
+	broker        broker
+	queryLogStore queryLogStore
+	rules         ruleEditor
+	users         userGetter
+	cursors       *pihole.CursorManager[storedSearch]
+	streamFrom    float64 // entries logged before the collector or stream started are not streamed
+	cfg           config.QueryLogServiceConfig
+	logger        zerolog.Logger
 }
 
-func NewService(cluster cluster, logger zerolog.Logger) *Service {
+func NewService(cluster cluster, broker broker, queryLogStore queryLogStore, rules ruleEditor, users userGetter, cfg config.QueryLogServiceConfig, logger zerolog.Logger) *Service {
 	return &Service{
-		cluster: cluster,
+		cluster:       cluster,
+		broker:        broker,
+		queryLogStore: queryLogStore,
+		rules:         rules,
+		users:         users,
+		// Entries older than the retention window are gone, so cursors need not outlive it
+		cursors: pihole.NewCursorManager[storedSearch](cfg.RetentionHours),
+		cfg:     cfg,
 		logger:  logger,
 	}
 }
 
+// Fetch serves the query log from the local store when it covers the request, and from the nodes
+// otherwise.
 func (s *Service) Fetch(ctx context.Context, params pihole.FetchQueryLogClusterRequest) (*pihole.FetchQueryLogsClusterResponse, error) {
+	if state, ok := s.storedState(params); ok {
+		return s.fetchStored(ctx, params, state)
+	}
 	return s.cluster.FetchQueryLogs(ctx, params)
 }
+
+func (s *Service) FetchMerged(ctx context.Context, params pihole.FetchQueryLogClusterRequest) (*pihole.FetchMergedQueryLogsClusterResponse, error) {
+	if state, ok := s.storedState(params); ok {
+		return s.fetchStoredMerged(ctx, params, state)
+	}
+	return s.cluster.FetchMergedQueryLogs(ctx, params)
+}
+
+// storedState decides whether a request can be served locally. A cursor is served locally if it was
+// created locally. A new search is served locally if the collector runs, unless it asks for the
+// on-disk Pi-hole database or reaches back past the retention window. A search without a time range,
+// such as the default newest first page, is served from whatever the store holds.
+func (s *Service) storedState(params pihole.FetchQueryLogClusterRequest) (*storedCursorState, bool) {
+	if !s.cfg.CollectorEnabled {
+		return nil, false
+	}
+	if params.Cursor != nil && *params.Cursor != "" {
+		state, ok := s.cursors.GetSearchState(*params.Cursor)
+		if !ok {
+			return nil, false
+		}
+		return &storedCursorState{search: state.GetRequestParams(), state: state}, true
+	}
+
+	f := params.Filters
+	if f.Disk != nil && *f.Disk {
+		return nil, false
+	}
+	retainedSince := time.Now().Add(-time.Duration(s.cfg.RetentionHours) * time.Hour).Unix()
+	// A range starting before the window may need entries the store has dropped
+	if f.From != nil && *f.From < retainedSince {
+		return nil, false
+	}
+	if f.Until != nil && *f.Until < retainedSince {
+		return nil, false
+	}
+	return &storedCursorState{search: storedSearch{Filters: f}}, true
+}
@@ -0,0 +1,40 @@
+package querylogservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/rs/zerolog"
+)
+
+func TestStoredStateServesWhatTheStoreCovers(t *testing.T) {
+	now := time.Now().Unix()
+	recent, old := now-3600, now-48*3600
+	disk := true
+
+	tests := []struct {
+		name      string
+		collector bool
+		filters   pihole.FetchQueryLogFilters
+		want      bool
+	}{
+		{name: "default newest first page", collector: true, want: true},
+		{name: "range within retention", collector: true, filters: pihole.FetchQueryLogFilters{From: &recent}, want: true},
+		{name: "until within retention", collector: true, filters: pihole.FetchQueryLogFilters{Until: &recent}, want: true},
+		{name: "range starting before retention", collector: true, filters: pihole.FetchQueryLogFilters{From: &old}},
+		{name: "range ending before retention", collector: true, filters: pihole.FetchQueryLogFilters{Until: &old}},
+		{name: "on-disk database", collector: true, filters: pihole.FetchQueryLogFilters{Disk: &disk}},
+		{name: "collector disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(nil, nil, nil, nil, nil, config.QueryLogServiceConfig{CollectorEnabled: tt.collector, RetentionHours: 24}, zerolog.Nop())
+			if _, got := s.storedState(pihole.FetchQueryLogClusterRequest{Filters: tt.filters}); got != tt.want {
+				t.Errorf("storedState() served locally = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package querylogservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/store"
+)
+
+const defaultQueryLogLength = 100
+
+// fetchStored pages each node's entries from the local store, mirroring the live per-node response.
+func (s *Service) fetchStored(ctx context.Context, params pihole.FetchQueryLogClusterRequest, cs *storedCursorState) (*pihole.FetchQueryLogsClusterResponse, error) {
+	if cs.state != nil && cs.state.IsMerged() {
+		return nil, fmt.Errorf("cursor belongs to a merged query log")
+	}
+	search, err := s.pinSearch(cs)
+	if err != nil {
+		return nil, err
+	}
+	length := queryLogLength(params)
+	storeFilters := toStoreFilters(search.Filters)
+
+	totals, err := s.queryLogStore.CountQueryLogEntriesByNode(store.GetQueryLogEntriesParams{MaxId: &search.MaxId})
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := s.queryLogStore.CountQueryLogEntriesByNode(store.GetQueryLogEntriesParams{MaxId: &search.MaxId, Filters: storeFilters})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int64]*domain.NodeResult[pihole.FetchQueryLogResponse])
+	offsets := make(map[int64]int)
+	endOfResults := true
+	for _, node := range s.cluster.GetNodes(ctx) {
+		offset := 0
+		if cs.state != nil {
+			if o, ok := cs.state.GetPiholeCursor(node.Id); ok {
+				offset = o
+			}
+		}
+		if params.Start != nil && cs.state == nil {
+			offset = *params.Start
+		}
+
+		id := node.Id
+		entries, err := s.queryLogStore.GetQueryLogEntries(store.GetQueryLogEntriesParams{
+			PiholeId: &id,
+			MaxId:    &search.MaxId,
+			Filters:  storeFilters,
+			Limit:    length,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		queries := make([]pihole.DNSLogEntry, 0, len(entries))
+		for _, e := range entries {
+			queries = append(queries, toDNSLogEntry(e))
+		}
+		offsets[id] = offset + len(queries)
+		if int64(offsets[id]) < filtered[id] {
+			endOfResults = false
+		}
+		results[id] = &domain.NodeResult[pihole.FetchQueryLogResponse]{
+			PiholeNode: node,
+			Success:    true,
+			Response: &pihole.FetchQueryLogResponse{
+				Queries:         queries,
+				Cursor:          offsets[id],
+				RecordsTotal:    totals[id],
+				RecordsFiltered: filtered[id],
+			},
+		}
+	}
+
+	return &pihole.FetchQueryLogsClusterResponse{
+		Cursor:       s.cursors.CreateCursor(search, offsets),
+		Results:      results,
+		EndOfResults: endOfResults,
+	}, nil
+}
+
+// fetchStoredMerged pages the entries of every node from the local store as one time-ordered list.
+// The cursor keeps how many entries of each node have been read; their sum is the global offset.
+func (s *Service) fetchStoredMerged(ctx context.Context, params pihole.FetchQueryLogClusterRequest, cs *storedCursorState) (*pihole.FetchMergedQueryLogsClusterResponse, error) {
+	if cs.state != nil && !cs.state.IsMerged() {
+		return nil, fmt.Errorf("cursor does not belong to a merged query log")
+	}
+	search, err := s.pinSearch(cs)
+	if err != nil {
+		return nil, err
+	}
+	length := queryLogLength(params)
+	storeFilters := toStoreFilters(search.Filters)
+
+	nodes := s.cluster.GetNodes(ctx)
+	nodesById := make(map[int64]domain.PiholeNodeRef, len(nodes))
+	offsets := make(map[int64]int, len(nodes))
+	offset := 0
+	for _, node := range nodes {
+		nodesById[node.Id] = node
+		if cs.state != nil {
+			if o, ok := cs.state.GetPiholeOffset(node.Id); ok {
+				offsets[node.Id] = o
+				offset += o
+			}
+		}
+	}
+
+	entries, err := s.queryLogStore.GetQueryLogEntries(store.GetQueryLogEntriesParams{
+		MaxId:   &search.MaxId,
+		Filters: storeFilters,
+		Limit:   length,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := s.queryLogStore.CountQueryLogEntriesByNode(store.GetQueryLogEntriesParams{MaxId: &search.MaxId, Filters: storeFilters})
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]pihole.MergedDNSLogEntry, 0, len(entries))
+	for _, e := range entries {
+		node, ok := nodesById[e.PiholeId]
+		if !ok {
+			node = domain.PiholeNodeRef{Id: e.PiholeId}
+		}
+		queries = append(queries, pihole.MergedDNSLogEntry{DNSLogEntry: toDNSLogEntry(e), PiholeNode: node})
+		offsets[e.PiholeId]++
+	}
+
+	results := make(map[int64]*domain.NodeResult[pihole.MergedQueryLogNodeStatus], len(nodes))
+	endOfResults := true
+	for _, node := range nodes {
+		exhausted := int64(offsets[node.Id]) >= filtered[node.Id]
+		if !exhausted {
+			endOfResults = false
+		}
+		results[node.Id] = &domain.NodeResult[pihole.MergedQueryLogNodeStatus]{
+			PiholeNode: node,
+			Success:    true,
+			Response: &pihole.MergedQueryLogNodeStatus{
+				Offset:          offsets[node.Id],
+				RecordsFiltered: filtered[node.Id],
+				Exhausted:       exhausted,
+			},
+		}
+	}
+
+	return &pihole.FetchMergedQueryLogsClusterResponse{
+		Cursor:       s.cursors.CreateMergedCursor(search, nil, offsets),
+		Queries:      queries,
+		Nodes:        results,
+		EndOfResults: endOfResults,
+	}, nil
+}
+
+// pinSearch returns the cursor's search, or pins a new search to the rows stored right now.
+func (s *Service) pinSearch(cs *storedCursorState) (storedSearch, error) {
+	if cs.state != nil {
+		return cs.search, nil
+	}
+	maxId, err := s.queryLogStore.GetMaxQueryLogId()
+	if err != nil {
+		return storedSearch{}, err
+	}
+	search := cs.search
+	search.MaxId = maxId
+	return search, nil
+}
+
+func queryLogLength(params pihole.FetchQueryLogClusterRequest) int {
+	if params.Length != nil && *params.Length > 0 {
+		return *params.Length
+	}
+	return defaultQueryLogLength
+}
+
+func toStoreFilters(f pihole.FetchQueryLogFilters) store.QueryLogFilters {
+	filters := store.QueryLogFilters{
+		Domain:     f.Domain,
+		ClientIP:   f.ClientIP,
+		ClientName: f.ClientName,
+		Upstream:   f.Upstream,
+		Type:       f.Type,
+		Status:     f.Status,
+		Reply:      f.Reply,
+		DNSSEC:     f.DNSSEC,
+	}
+	if f.From != nil {
+		from := float64(*f.From)
+		filters.From = &from
+	}
+	if f.Until != nil {
+		// Until is a whole second, so include everything up to the end of it
+		until := float64(*f.Until + 1)
+		filters.Until = &until
+	}
+	return filters
+}
+
+func toDNSLogEntry(e *domain.QueryLogEntry) pihole.DNSLogEntry {
+	return pihole.DNSLogEntry{
+		Id:       e.QueryId,
+		Time:     e.Time,
+		Type:     e.Type,
+		Status:   e.Status,
+		DNSSEC:   e.DNSSEC,
+		Domain:   e.Domain,
+		Upstream: e.Upstream,
+		Reply:    pihole.ReplyInfo{Type: e.ReplyType, Time: e.ReplyTime},
+		Client:   pihole.ClientInfo{IP: e.ClientIP, Name: e.ClientName},
+		ListID:   e.ListId,
+		EDE:      pihole.EDEInfo{Code: e.EDECode, Text: e.EDEText},
+		CNAME:    e.CNAME,
+	}
+}
+
+func toQueryLogEntry(piholeId int64, e pihole.DNSLogEntry) domain.QueryLogEntry {
+	return domain.QueryLogEntry{
+		PiholeId:   piholeId,
+		QueryId:    e.Id,
+		Time:       e.Time,
+		Type:       e.Type,
+		Status:     e.Status,
+		DNSSEC:     e.DNSSEC,
+		Domain:     e.Domain,
+		Upstream:   e.Upstream,
+		ReplyType:  e.Reply.Type,
+		ReplyTime:  e.Reply.Time,
+		ClientIP:   e.Client.IP,
+		ClientName: e.Client.Name,
+		ListId:     e.ListID,
+		EDECode:    e.EDE.Code,
+		EDEText:    e.EDE.Text,
+		CNAME:      e.CNAME,
+	}
+}
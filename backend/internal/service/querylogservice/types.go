@@ -0,0 +1,83 @@
+package querylogservice
+
+import (
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+// storedSearch is what a cursor over the local query log remembers. The cursor's per-node positions
+// are offsets into the node's matching entries.
+type storedSearch struct {
+	Filters pihole.FetchQueryLogFilters
+	MaxId   int64 // newest row when the search started, so rows collected later do not shift pages
+}
+
+// searchState is the part of a pihole cursor snapshot the service reads back.
+type searchState interface {
+	IsMerged() bool
+	GetPiholeCursor(id int64) (int, bool)
+	GetPiholeOffset(id int64) (int, bool)
+}
+
+type storedCursorState struct {
+	search storedSearch
+	state  searchState // nil for a new search
+}
+
+type ExplainParams struct {
+	NodeId int64
+	Status string
+	ListId *int64
+	Domain string
+}
+
+type BlockCause string
+
+const (
+	BlockCauseExactDeny BlockCause = "exact_deny" // manual block of a single domain
+	BlockCauseRegexDeny BlockCause = "regex_deny"
+	BlockCauseAdlist    BlockCause = "adlist"
+	BlockCauseUpstream  BlockCause = "upstream" // the upstream server blocked it
+	BlockCauseSpecial   BlockCause = "special"  // special domains Pi-hole always blocks, e.g. the Mozilla canary
+	BlockCauseDatabase  BlockCause = "database_busy"
+	BlockCauseNone      BlockCause = "none" // the query was not blocked
+	BlockCauseUnknown   BlockCause = "unknown"
+)
+
+type Explanation struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Domain     string               `json:"domain,omitempty"`
+	Status     string               `json:"status"`
+	ListId     *int64               `json:"listId,omitempty"`
+	Blocked    bool                 `json:"blocked"`
+	Cause      BlockCause           `json:"cause"`
+	ViaCNAME   bool                 `json:"viaCname"` // the match was on a CNAME target, not the queried domain
+	Summary    string               `json:"summary"`
+	DomainRule *pihole.DomainInfo   `json:"domainRule,omitempty"` // the exact or regex rule that matched
+	List       *pihole.ListInfo     `json:"list,omitempty"`       // the adlist that matched
+}
+
+type UnblockParams struct {
+	NodeId int64    `json:"nodeId"`
+	Domain string   `json:"domain"`
+	Status string   `json:"status"`
+	ListId *int64   `json:"listId,omitempty"`
+	Time   *float64 `json:"time,omitempty"` // when the query was logged, for the rule comment
+}
+
+type UnblockAction string
+
+const (
+	UnblockActionRemoveDenyRule UnblockAction = "remove_deny_rule"
+	UnblockActionAddAllowRule   UnblockAction = "add_allow_rule"
+)
+
+type UnblockResult struct {
+	Explanation *Explanation  `json:"explanation"`
+	Action      UnblockAction `json:"action"`
+	Type        string        `json:"type"`
+	Kind        string        `json:"kind"`
+	Domain      string        `json:"domain"`
+	Comment     *string       `json:"comment,omitempty"`
+	Results     any           `json:"results"` // per-node results of the add or remove
+}
@@ -0,0 +1,108 @@
+package querylogservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/auto-dns/pihole-cluster-admin/internal/sessions"
+	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
+)
+
+// Unblock applies the remedy for a blocked query across the cluster. A manual exact deny rule is
+// removed. A regex or adlist block is overridden with an exact allow rule whose comment records the
+// original query and who unblocked it.
+func (s *Service) Unblock(ctx context.Context, params UnblockParams) (*UnblockResult, error) {
+	queried := strings.ToLower(strings.TrimSpace(params.Domain))
+	if queried == "" {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "domain is required")
+	}
+
+	explanation, err := s.Explain(ctx, ExplainParams{
+		NodeId: params.NodeId,
+		Status: params.Status,
+		ListId: params.ListId,
+		Domain: queried,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !explanation.Blocked {
+		return nil, httpx.NewHttpError(httpx.ErrValidation, "the query was not blocked")
+	}
+
+	result := &UnblockResult{Explanation: explanation}
+	switch explanation.Cause {
+	case BlockCauseExactDeny:
+		if explanation.DomainRule != nil {
+			result.Action = UnblockActionRemoveDenyRule
+			result.Type = string(pihole.RuleTypeDeny)
+			result.Kind = string(pihole.RuleKindExact)
+			result.Domain = explanation.DomainRule.Domain
+			result.Results = s.rules.Remove(ctx, pihole.RemoveDomainRuleOptions{
+				Type:   pihole.RuleTypeDeny,
+				Kind:   pihole.RuleKindExact,
+				Domain: result.Domain,
+			})
+			return result, nil
+		}
+		// The deny rule is gone from the node that logged the query, so fall back to allowing the domain
+
+	case BlockCauseRegexDeny, BlockCauseAdlist:
+		// Removing a regex or adlist would unblock far more than this domain, so override it instead
+
+	default:
+		return nil, httpx.NewHttpError(httpx.ErrValidation, fmt.Sprintf("the query cannot be unblocked in Pi-hole: %s", explanation.Summary))
+	}
+
+	comment := s.unblockComment(ctx, queried, params, explanation)
+	result.Action = UnblockActionAddAllowRule
+	result.Type = string(pihole.RuleTypeAllow)
+	result.Kind = string(pihole.RuleKindExact)
+	result.Domain = queried
+	result.Comment = &comment
+	results, err := s.rules.Add(ctx, pihole.AddDomainRuleOptions{
+		Type: pihole.RuleTypeAllow,
+		Kind: pihole.RuleKindExact,
+		Payload: pihole.AddDomainPayload{
+			Domain:  queried,
+			Comment: &comment,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Results = results
+	return result, nil
+}
+
+func (s *Service) unblockComment(ctx context.Context, queried string, params UnblockParams, explanation *Explanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Unblocked from query log: %s (%s", queried, explanation.Status)
+	if explanation.DomainRule != nil {
+		fmt.Fprintf(&b, ", rule %q", explanation.DomainRule.Domain)
+	}
+	if explanation.List != nil {
+		fmt.Fprintf(&b, ", adlist %s", explanation.List.Address)
+	}
+	node := explanation.PiholeNode.Name
+	if node == "" {
+		node = fmt.Sprintf("node %d", explanation.PiholeNode.Id)
+	}
+	fmt.Fprintf(&b, ") on %s", node)
+	if params.Time != nil {
+		fmt.Fprintf(&b, " at %s", time.Unix(int64(*params.Time), 0).UTC().Format(time.RFC3339))
+	}
+
+	if userId, ok := ctx.Value(sessions.UserIdContextKey).(int64); ok {
+		if user, err := s.users.GetUser(userId); err == nil {
+			fmt.Fprintf(&b, " by %s", user.Username)
+		} else {
+			s.logger.Warn().Err(err).Int64("user_id", userId).Msg("error looking up user for unblock comment")
+			fmt.Fprintf(&b, " by user %d", userId)
+		}
+	}
+	return b.String()
+}
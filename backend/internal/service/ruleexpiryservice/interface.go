@@ -0,0 +1,18 @@
+package ruleexpiryservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type expirationStore interface {
+	GetDueExpirations(now time.Time) ([]*domain.DomainRuleExpiration, error)
+	RemoveExpiration(id int64) (bool, error)
+}
+
+type ruleRemover interface {
+	RemoveFromNodes(ctx context.Context, ids []int64, opts pihole.RemoveDomainRuleOptions) map[int64]*domain.NodeResult[pihole.RemoveDomainRuleResponse]
+}
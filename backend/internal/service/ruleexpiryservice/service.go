@@ -0,0 +1,90 @@
+package ruleexpiryservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/config"
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/rs/zerolog"
+)
+
+// Service removes temporary domain rules once they expire. Expirations live in the database, so
+// rules that expire while the app is down are removed on the first sweep after it starts.
+type Service struct {
+	expirationStore expirationStore
+	remover         ruleRemover
+	cfg             config.RuleExpiryServiceConfig
+	logger          zerolog.Logger
+}
+
+func NewService(expirationStore expirationStore, remover ruleRemover, cfg config.RuleExpiryServiceConfig, logger zerolog.Logger) *Service {
+	return &Service{
+		expirationStore: expirationStore,
+		remover:         remover,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.logger.Info().Msg("Starting rule expiry service")
+
+	ticker := time.NewTicker(time.Duration(s.cfg.PollingIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	s.sweepOnce(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.sweepOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) sweepOnce(ctx context.Context) {
+	due, err := s.expirationStore.GetDueExpirations(time.Now())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("error getting due domain rule expirations")
+		return
+	}
+
+	for _, expiration := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.expire(ctx, expiration)
+	}
+}
+
+// expire removes the rule from the nodes where it was added. The domain rule service takes each node
+// off the expiration once the rule is gone from it, or the removal is queued because the node is
+// unreachable. A node whose removal failed stays on the expiration, so it is retried on the next sweep.
+func (s *Service) expire(ctx context.Context, expiration *domain.DomainRuleExpiration) {
+	logger := s.logger.With().Int64("id", expiration.Id).Str("type", expiration.Type).Str("kind", expiration.Kind).Str("domain", expiration.Domain).Logger()
+
+	ruleType, okType := pihole.ParseRuleType(expiration.Type)
+	ruleKind, okKind := pihole.ParseRuleKind(expiration.Kind)
+	if !okType || !okKind {
+		logger.Error().Msg("invalid type or kind on domain rule expiration, dropping it")
+		if _, err := s.expirationStore.RemoveExpiration(expiration.Id); err != nil {
+			logger.Error().Err(err).Msg("error removing domain rule expiration")
+		}
+		return
+	}
+
+	logger.Info().Msg("temporary domain rule expired, removing it")
+	results := s.remover.RemoveFromNodes(ctx, expiration.PiholeNodeIds, pihole.RemoveDomainRuleOptions{
+		Type:   ruleType,
+		Kind:   ruleKind,
+		Domain: expiration.Domain,
+	})
+	for _, nr := range results {
+		if nr.Error != nil && !pihole.IsUnreachable(nr.Error) {
+			logger.Warn().Err(nr.Error).Int64("node_id", nr.PiholeNode.Id).Msg("error removing expired domain rule, retrying next sweep")
+		}
+	}
+}
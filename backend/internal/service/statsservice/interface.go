@@ -0,0 +1,15 @@
+package statsservice
+
+import (
+	"context"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+)
+
+type cluster interface {
+	GetStatsSummary(ctx context.Context, opts pihole.GetStatsSummaryOptions) map[int64]*domain.NodeResult[pihole.StatsSummaryResponse]
+	GetTopDomains(ctx context.Context, opts pihole.GetTopDomainsOptions) map[int64]*domain.NodeResult[pihole.TopDomainsResponse]
+	GetTopClients(ctx context.Context, opts pihole.GetTopClientsOptions) map[int64]*domain.NodeResult[pihole.TopClientsResponse]
+	GetUpstreams(ctx context.Context, opts pihole.GetUpstreamsOptions) map[int64]*domain.NodeResult[pihole.UpstreamsResponse]
+}
@@ -0,0 +1,263 @@
+package statsservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/pihole"
+	"github.com/rs/zerolog"
+)
+
+const defaultWindow = 24 * time.Hour
+
+// pseudoUpstreamPort is the port Pi-hole reports for the blocklist and its cache, which it lists
+// among the upstreams but are not forward destinations.
+const pseudoUpstreamPort = -1
+
+type Service struct {
+	cluster cluster
+	logger  zerolog.Logger
+}
+
+func NewService(cluster cluster, logger zerolog.Logger) *Service {
+	return &Service{
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+// GetStats aggregates the statistics of every node. Top lists are merged from each node's own top
+// list, so an entry that is common across the cluster but never in any single node's top Count can
+// be missing. A node that fails any of its requests is reported as unreachable and left out
+// entirely, so the totals and top lists always describe the same set of nodes.
+func (s *Service) GetStats(ctx context.Context, params Params) (*Stats, error) {
+	from, until := params.From, params.Until
+	if from != nil || until != nil {
+		if until == nil {
+			now := time.Now().UTC()
+			until = &now
+		}
+		if from == nil {
+			f := until.Add(-defaultWindow)
+			from = &f
+		}
+	}
+
+	window := pihole.StatsWindow{}
+	if from != nil {
+		f, u := from.Unix(), until.Unix()
+		window.From, window.Until = &f, &u
+	}
+
+	var (
+		wg        sync.WaitGroup
+		summaries map[int64]*domain.NodeResult[pihole.StatsSummaryResponse]
+		domains   map[int64]*domain.NodeResult[pihole.TopDomainsResponse]
+		clients   map[int64]*domain.NodeResult[pihole.TopClientsResponse]
+		upstreams map[int64]*domain.NodeResult[pihole.UpstreamsResponse]
+	)
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		summaries = s.cluster.GetStatsSummary(ctx, pihole.GetStatsSummaryOptions{Window: window})
+	}()
+	go func() {
+		defer wg.Done()
+		domains = s.cluster.GetTopDomains(ctx, pihole.GetTopDomainsOptions{Window: window, Blocked: true, Count: params.Count})
+	}()
+	go func() {
+		defer wg.Done()
+		clients = s.cluster.GetTopClients(ctx, pihole.GetTopClientsOptions{Window: window, Count: params.Count})
+	}()
+	go func() {
+		defer wg.Done()
+		upstreams = s.cluster.GetUpstreams(ctx, pihole.GetUpstreamsOptions{Window: window})
+	}()
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{
+		From:              from,
+		Until:             until,
+		TopBlockedDomains: []DomainCount{},
+		TopClients:        []ClientCount{},
+		TopUpstreams:      []UpstreamCount{},
+		Nodes:             []NodeStats{},
+		UnreachableNodes:  []domain.UnreachableNode{},
+	}
+
+	ids := make([]int64, 0, len(summaries))
+	for id := range summaries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var minutes float64
+	if from != nil {
+		minutes = until.Sub(*from).Minutes()
+	}
+
+	domainCounts := make(map[string]*DomainCount)
+	clientCounts := make(map[string]*ClientCount)
+	upstreamCounts := make(map[string]*UpstreamCount)
+	for _, id := range ids {
+		node, err := nodeFailure(id, summaries[id], domains[id], clients[id], upstreams[id])
+		if err != "" {
+			stats.UnreachableNodes = append(stats.UnreachableNodes, domain.UnreachableNode{PiholeNode: node, Error: err})
+			continue
+		}
+
+		summary := summaries[id].Response.Queries
+		nodeTotals := Totals{Queries: summary.Total, Blocked: summary.Blocked}
+		if minutes > 0 {
+			nodeTotals.QueriesPerMinute = float64(summary.Total) / minutes
+		} else {
+			nodeTotals.QueriesPerMinute = summary.Frequency * 60
+		}
+		nodeTotals.PercentBlocked = percent(nodeTotals.Blocked, nodeTotals.Queries)
+		stats.Nodes = append(stats.Nodes, NodeStats{PiholeNode: node, Totals: nodeTotals})
+
+		stats.Totals.Queries += nodeTotals.Queries
+		stats.Totals.Blocked += nodeTotals.Blocked
+		stats.Totals.QueriesPerMinute += nodeTotals.QueriesPerMinute
+
+		for _, d := range domains[id].Response.Domains {
+			entry, ok := domainCounts[d.Domain]
+			if !ok {
+				entry = &DomainCount{Domain: d.Domain}
+				domainCounts[d.Domain] = entry
+			}
+			entry.Count += d.Count
+			entry.Nodes = append(entry.Nodes, node)
+		}
+
+		for _, c := range clients[id].Response.Clients {
+			entry, ok := clientCounts[c.IP]
+			if !ok {
+				entry = &ClientCount{IP: c.IP}
+				clientCounts[c.IP] = entry
+			}
+			if entry.Name == "" {
+				entry.Name = c.Name
+			}
+			entry.Count += c.Count
+			entry.Nodes = append(entry.Nodes, node)
+		}
+
+		for _, u := range upstreams[id].Response.Upstreams {
+			if u.Port == pseudoUpstreamPort {
+				continue
+			}
+			key := fmt.Sprintf("%s#%d", u.IP, u.Port)
+			entry, ok := upstreamCounts[key]
+			if !ok {
+				entry = &UpstreamCount{IP: u.IP, Port: u.Port}
+				upstreamCounts[key] = entry
+			}
+			if entry.Name == "" {
+				entry.Name = u.Name
+			}
+			// Running mean weighted by each node's count
+			if total := entry.Count + u.Count; total > 0 {
+				entry.ResponseTime = (entry.ResponseTime*float64(entry.Count) + u.Statistics.Response*float64(u.Count)) / float64(total)
+			}
+			entry.Count += u.Count
+			entry.Nodes = append(entry.Nodes, node)
+		}
+	}
+	stats.Totals.PercentBlocked = percent(stats.Totals.Blocked, stats.Totals.Queries)
+
+	for _, entry := range domainCounts {
+		stats.TopBlockedDomains = append(stats.TopBlockedDomains, *entry)
+	}
+	sort.Slice(stats.TopBlockedDomains, func(i, j int) bool {
+		a, b := stats.TopBlockedDomains[i], stats.TopBlockedDomains[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Domain < b.Domain
+	})
+
+	for _, entry := range clientCounts {
+		stats.TopClients = append(stats.TopClients, *entry)
+	}
+	sort.Slice(stats.TopClients, func(i, j int) bool {
+		a, b := stats.TopClients[i], stats.TopClients[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.IP < b.IP
+	})
+
+	for _, entry := range upstreamCounts {
+		stats.TopUpstreams = append(stats.TopUpstreams, *entry)
+	}
+	sort.Slice(stats.TopUpstreams, func(i, j int) bool {
+		a, b := stats.TopUpstreams[i], stats.TopUpstreams[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.IP != b.IP {
+			return a.IP < b.IP
+		}
+		return a.Port < b.Port
+	})
+
+	if params.Count > 0 {
+		stats.TopBlockedDomains = truncate(stats.TopBlockedDomains, params.Count)
+		stats.TopClients = truncate(stats.TopClients, params.Count)
+		stats.TopUpstreams = truncate(stats.TopUpstreams, params.Count)
+	}
+
+	return stats, nil
+}
+
+// nodeFailure returns the node's reference and the first error among its results, or an empty
+// string when every request succeeded.
+func nodeFailure(
+	id int64,
+	summary *domain.NodeResult[pihole.StatsSummaryResponse],
+	domains *domain.NodeResult[pihole.TopDomainsResponse],
+	clients *domain.NodeResult[pihole.TopClientsResponse],
+	upstreams *domain.NodeResult[pihole.UpstreamsResponse],
+) (domain.PiholeNodeRef, string) {
+	node := domain.PiholeNodeRef{Id: id}
+	if summary != nil {
+		node = summary.PiholeNode
+	}
+
+	switch {
+	case summary == nil || domains == nil || clients == nil || upstreams == nil:
+		return node, "node was removed while collecting stats"
+	case !summary.Success || summary.Response == nil:
+		return node, summary.ErrorString
+	case !domains.Success || domains.Response == nil:
+		return node, domains.ErrorString
+	case !clients.Success || clients.Response == nil:
+		return node, clients.ErrorString
+	case !upstreams.Success || upstreams.Response == nil:
+		return node, upstreams.ErrorString
+	}
+	return node, ""
+}
+
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func truncate[T any](s []T, n int) []T {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
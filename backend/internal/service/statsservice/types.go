@@ -0,0 +1,58 @@
+package statsservice
+
+import (
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+)
+
+type Params struct {
+	From  *time.Time // nil From and Until use each node's in-memory statistics (about the last 24 hours)
+	Until *time.Time
+	Count int // entries in each top list
+}
+
+type Stats struct {
+	From              *time.Time               `json:"from,omitempty"`
+	Until             *time.Time               `json:"until,omitempty"`
+	Totals            Totals                   `json:"totals"`
+	TopBlockedDomains []DomainCount            `json:"topBlockedDomains"`
+	TopClients        []ClientCount            `json:"topClients"`
+	TopUpstreams      []UpstreamCount          `json:"topUpstreams"`
+	Nodes             []NodeStats              `json:"nodes"`
+	UnreachableNodes  []domain.UnreachableNode `json:"unreachableNodes"`
+}
+
+type Totals struct {
+	Queries          int64   `json:"queries"`
+	Blocked          int64   `json:"blocked"`
+	PercentBlocked   float64 `json:"percentBlocked"`
+	QueriesPerMinute float64 `json:"queriesPerMinute"`
+}
+
+type NodeStats struct {
+	PiholeNode domain.PiholeNodeRef `json:"piholeNode"`
+	Totals     Totals               `json:"totals"`
+}
+
+type DomainCount struct {
+	Domain string                 `json:"domain"`
+	Count  int64                  `json:"count"`
+	Nodes  []domain.PiholeNodeRef `json:"nodes"`
+}
+
+type ClientCount struct {
+	IP    string                 `json:"ip"`
+	Name  string                 `json:"name,omitempty"`
+	Count int64                  `json:"count"`
+	Nodes []domain.PiholeNodeRef `json:"nodes"`
+}
+
+type UpstreamCount struct {
+	IP           string                 `json:"ip"`
+	Name         string                 `json:"name,omitempty"`
+	Port         int                    `json:"port"`
+	Count        int64                  `json:"count"`
+	ResponseTime float64                `json:"responseTime"` // seconds, averaged across nodes weighted by count
+	Nodes        []domain.PiholeNodeRef `json:"nodes"`
+}
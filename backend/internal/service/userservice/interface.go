@@ -1,7 +1,10 @@
 package userservice
 
 import (
+	"context"
+
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 )
 
@@ -10,3 +13,7 @@ type userStore interface {
 	GetUserAuth(id int64) (*domain.UserAuth, error)
 	UpdateUser(id int64, params store.UpdateUserParams) (*domain.User, error)
 }
+
+type auditor interface {
+	Record(ctx context.Context, event auditservice.Event)
+}
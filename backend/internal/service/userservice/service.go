@@ -1,11 +1,14 @@
 package userservice
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/crypto"
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/auto-dns/pihole-cluster-admin/internal/service/auditservice"
 	"github.com/auto-dns/pihole-cluster-admin/internal/store"
 	"github.com/auto-dns/pihole-cluster-admin/internal/transport/httpx"
 	"github.com/rs/zerolog"
@@ -13,17 +16,30 @@ import (
 
 type Service struct {
 	userStore userStore
+	auditor   auditor
 	logger    zerolog.Logger
 }
 
-func NewService(userStore userStore, logger zerolog.Logger) *Service {
+func NewService(userStore userStore, auditor auditor, logger zerolog.Logger) *Service {
 	return &Service{
 		userStore: userStore,
+		auditor:   auditor,
 		logger:    logger,
 	}
 }
 
-func (s *Service) Patch(id int64, params PatchUserParams) (*domain.User, error) {
+func (s *Service) Patch(ctx context.Context, id int64, params PatchUserParams) (*domain.User, error) {
+	updatedUser, err := s.patch(id, params)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:  auditservice.ActionUserUpdate,
+		Target:  fmt.Sprintf("%d", id),
+		Details: params,
+		Success: err == nil,
+	})
+	return updatedUser, err
+}
+
+func (s *Service) patch(id int64, params PatchUserParams) (*domain.User, error) {
 	currentUser, err := s.userStore.GetUser(id)
 	if err != nil {
 		return nil, err
@@ -49,7 +65,18 @@ func (s *Service) Patch(id int64, params PatchUserParams) (*domain.User, error)
 	return updatedNode, err
 }
 
-func (s *Service) UpdatePassword(id int64, params UpdatePasswordParams) (*domain.User, error) {
+func (s *Service) UpdatePassword(ctx context.Context, id int64, params UpdatePasswordParams) (*domain.User, error) {
+	// The passwords themselves are never recorded
+	updatedUser, err := s.updatePassword(id, params)
+	s.auditor.Record(ctx, auditservice.Event{
+		Action:  auditservice.ActionUserPasswordChange,
+		Target:  fmt.Sprintf("%d", id),
+		Success: err == nil,
+	})
+	return updatedUser, err
+}
+
+func (s *Service) updatePassword(id int64, params UpdatePasswordParams) (*domain.User, error) {
 	currentUserAuth, err := s.userStore.GetUserAuth(id)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+type AuditStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewAuditStore(db *sql.DB, logger zerolog.Logger) *AuditStore {
+	return &AuditStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *AuditStore) AddAuditEntry(params AddAuditEntryParams) (*domain.AuditEntry, error) {
+	var details sql.NullString
+	if len(params.Details) > 0 {
+		details = sql.NullString{String: string(params.Details), Valid: true}
+	}
+
+	var nodeOutcomes sql.NullString
+	if len(params.NodeOutcomes) > 0 {
+		b, err := json.Marshal(params.NodeOutcomes)
+		if err != nil {
+			return nil, err
+		}
+		nodeOutcomes = sql.NullString{String: string(b), Valid: true}
+	}
+
+	var userId sql.NullInt64
+	if params.UserId != nil {
+		userId = sql.NullInt64{Int64: *params.UserId, Valid: true}
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO audit_log
+		(user_id, action, route, target, details, node_outcomes, success, created_at)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		userId, strings.TrimSpace(params.Action), params.Route, params.Target, details, nodeOutcomes, params.Success)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAuditEntry(id)
+}
+
+func (s *AuditStore) GetAuditEntry(id int64) (*domain.AuditEntry, error) {
+	var row auditRow
+	err := s.db.QueryRow(`
+		SELECT id, user_id, action, route, target, details, node_outcomes, success, created_at
+		FROM audit_log WHERE id = ?`, id).Scan(
+		&row.Id, &row.UserId, &row.Action, &row.Route, &row.Target, &row.Details, &row.NodeOutcomes, &row.Success, &row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rowToDomainAuditEntry(row, s.logger), nil
+}
+
+func (s *AuditStore) GetAuditEntries(params GetAuditEntriesParams) ([]*domain.AuditEntry, int64, error) {
+	var whereParts []string
+	var args []any
+	if params.UserId != nil {
+		whereParts = append(whereParts, "user_id = ?")
+		args = append(args, *params.UserId)
+	}
+	if params.Action != nil {
+		whereParts = append(whereParts, "action = ?")
+		args = append(args, *params.Action)
+	}
+	if params.Success != nil {
+		whereParts = append(whereParts, "success = ?")
+		args = append(args, *params.Success)
+	}
+	if params.From != nil {
+		whereParts = append(whereParts, "created_at >= datetime(?, 'unixepoch')")
+		args = append(args, params.From.Unix())
+	}
+	if params.Until != nil {
+		whereParts = append(whereParts, "created_at <= datetime(?, 'unixepoch')")
+		args = append(args, params.Until.Unix())
+	}
+
+	whereClause := ""
+	if len(whereParts) > 0 {
+		whereClause = " WHERE " + strings.Join(whereParts, " AND ")
+	}
+
+	var total int64
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM audit_log"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, user_id, action, route, target, details, node_outcomes, success, created_at
+		FROM audit_log` + whereClause + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`
+	rows, err := s.db.Query(query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.AuditEntry{}
+	for rows.Next() {
+		var row auditRow
+		if err := rows.Scan(&row.Id, &row.UserId, &row.Action, &row.Route, &row.Target, &row.Details, &row.NodeOutcomes, &row.Success, &row.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, rowToDomainAuditEntry(row, s.logger))
+	}
+
+	return entries, total, rows.Err()
+}
+
+func rowToDomainAuditEntry(row auditRow, logger zerolog.Logger) *domain.AuditEntry {
+	entry := &domain.AuditEntry{
+		Id:           row.Id,
+		Action:       row.Action,
+		Route:        row.Route,
+		Target:       row.Target,
+		NodeOutcomes: []domain.AuditNodeOutcome{},
+		Success:      row.Success,
+		CreatedAt:    row.CreatedAt,
+	}
+	if row.UserId.Valid {
+		userId := row.UserId.Int64
+		entry.UserId = &userId
+	}
+	if row.Details.Valid {
+		entry.Details = json.RawMessage(row.Details.String)
+	}
+	if row.NodeOutcomes.Valid {
+		if err := json.Unmarshal([]byte(row.NodeOutcomes.String), &entry.NodeOutcomes); err != nil {
+			logger.Warn().Err(err).Int64("id", row.Id).Msg("error decoding audit node outcomes")
+		}
+	}
+	return entry
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const domainRuleExpirationColumns = `id, type, kind, domain, pihole_node_ids, user_id, expires_at, created_at`
+
+type DomainRuleExpirationStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewDomainRuleExpirationStore(db *sql.DB, logger zerolog.Logger) *DomainRuleExpirationStore {
+	return &DomainRuleExpirationStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddExpiration schedules a rule for removal. Scheduling a rule that already has an expiration moves
+// it to the new time and adds the new nodes to it.
+func (s *DomainRuleExpirationStore) AddExpiration(params AddDomainRuleExpirationParams) (*domain.DomainRuleExpiration, error) {
+	var userId sql.NullInt64
+	if params.UserId != nil {
+		userId = sql.NullInt64{Int64: *params.UserId, Valid: true}
+	}
+
+	nodeIds := params.PiholeNodeIds
+	existing, err := s.GetExpirationByRule(params.Type, params.Kind, params.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.PiholeNodeIds == nil {
+			nodeIds = nil
+		} else if nodeIds != nil {
+			nodeIds = mergeNodeIds(existing.PiholeNodeIds, nodeIds)
+		}
+	}
+	encodedNodeIds, err := encodeNodeIds(nodeIds)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO domain_rule_expirations
+		(type, kind, domain, pihole_node_ids, user_id, expires_at, created_at)
+		VALUES
+		(?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (type, kind, domain) DO UPDATE SET
+			pihole_node_ids = excluded.pihole_node_ids,
+			user_id = excluded.user_id,
+			expires_at = excluded.expires_at`,
+		params.Type, params.Kind, params.Domain, encodedNodeIds, userId, params.ExpiresAt.UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetExpirationByRule(params.Type, params.Kind, params.Domain)
+}
+
+// GetExpirationByRule returns the expiration of a rule, or nil if the rule is permanent.
+func (s *DomainRuleExpirationStore) GetExpirationByRule(ruleType, kind, domainName string) (*domain.DomainRuleExpiration, error) {
+	row := s.db.QueryRow(`
+		SELECT `+domainRuleExpirationColumns+`
+		FROM domain_rule_expirations
+		WHERE type = ? AND kind = ? AND domain = ?`, ruleType, kind, domainName)
+	expiration, err := scanDomainRuleExpiration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return expiration, err
+}
+
+func (s *DomainRuleExpirationStore) GetExpirations() ([]*domain.DomainRuleExpiration, error) {
+	rows, err := s.db.Query(`SELECT ` + domainRuleExpirationColumns + ` FROM domain_rule_expirations ORDER BY expires_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDomainRuleExpirations(rows)
+}
+
+// GetDueExpirations returns the expirations at or before now, soonest first.
+func (s *DomainRuleExpirationStore) GetDueExpirations(now time.Time) ([]*domain.DomainRuleExpiration, error) {
+	rows, err := s.db.Query(`
+		SELECT `+domainRuleExpirationColumns+`
+		FROM domain_rule_expirations
+		WHERE expires_at <= ?
+		ORDER BY expires_at ASC`, now.UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDomainRuleExpirations(rows)
+}
+
+func (s *DomainRuleExpirationStore) RemoveExpiration(id int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM domain_rule_expirations WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// SetExpirationNodes narrows an expiration to the nodes the rule still has to be removed from.
+func (s *DomainRuleExpirationStore) SetExpirationNodes(id int64, piholeNodeIds []int64) error {
+	encodedNodeIds, err := encodeNodeIds(piholeNodeIds)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`UPDATE domain_rule_expirations SET pihole_node_ids = ? WHERE id = ?`, encodedNodeIds, id)
+	return err
+}
+
+func scanDomainRuleExpirations(rows *sql.Rows) ([]*domain.DomainRuleExpiration, error) {
+	expirations := []*domain.DomainRuleExpiration{}
+	for rows.Next() {
+		expiration, err := scanDomainRuleExpiration(rows)
+		if err != nil {
+			return nil, err
+		}
+		expirations = append(expirations, expiration)
+	}
+	return expirations, rows.Err()
+}
+
+func scanDomainRuleExpiration(scanner rowScanner) (*domain.DomainRuleExpiration, error) {
+	var row domainRuleExpirationRow
+	if err := scanner.Scan(&row.Id, &row.Type, &row.Kind, &row.Domain, &row.PiholeNodeIds, &row.UserId, &row.ExpiresAt, &row.CreatedAt); err != nil {
+		return nil, err
+	}
+	return rowToDomainRuleExpiration(row)
+}
+
+func rowToDomainRuleExpiration(row domainRuleExpirationRow) (*domain.DomainRuleExpiration, error) {
+	expiration := &domain.DomainRuleExpiration{
+		Id:        row.Id,
+		Type:      row.Type,
+		Kind:      row.Kind,
+		Domain:    row.Domain,
+		ExpiresAt: row.ExpiresAt,
+		CreatedAt: row.CreatedAt,
+	}
+	if row.PiholeNodeIds.Valid {
+		if err := json.Unmarshal([]byte(row.PiholeNodeIds.String), &expiration.PiholeNodeIds); err != nil {
+			return nil, err
+		}
+	}
+	if row.UserId.Valid {
+		userId := row.UserId.Int64
+		expiration.UserId = &userId
+	}
+	return expiration, nil
+}
+
+func encodeNodeIds(ids []int64) (sql.NullString, error) {
+	if ids == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func mergeNodeIds(a, b []int64) []int64 {
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const domainRuleChangeSetColumns = `id, user_id, action, reverts_id, changes, created_at, reverted_at, reverted_by_id`
+
+type DomainRuleHistoryStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewDomainRuleHistoryStore(db *sql.DB, logger zerolog.Logger) *DomainRuleHistoryStore {
+	return &DomainRuleHistoryStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *DomainRuleHistoryStore) AddChangeSet(params AddDomainRuleChangeSetParams) (*domain.DomainRuleChangeSet, error) {
+	changes, err := json.Marshal(params.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var userId, revertsId sql.NullInt64
+	if params.UserId != nil {
+		userId = sql.NullInt64{Int64: *params.UserId, Valid: true}
+	}
+	if params.RevertsId != nil {
+		revertsId = sql.NullInt64{Int64: *params.RevertsId, Valid: true}
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO domain_rule_change_set
+		(user_id, action, reverts_id, changes, created_at)
+		VALUES
+		(?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		userId, params.Action, revertsId, string(changes))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetChangeSet(id)
+}
+
+func (s *DomainRuleHistoryStore) GetChangeSet(id int64) (*domain.DomainRuleChangeSet, error) {
+	row := s.db.QueryRow(`SELECT `+domainRuleChangeSetColumns+` FROM domain_rule_change_set WHERE id = ?`, id)
+	changeSet, err := s.scanChangeSet(row)
+	if err != nil {
+		return nil, err
+	}
+	return changeSet, nil
+}
+
+func (s *DomainRuleHistoryStore) GetChangeSets(limit, offset int) ([]*domain.DomainRuleChangeSet, int64, error) {
+	var total int64
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM domain_rule_change_set`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+domainRuleChangeSetColumns+`
+		FROM domain_rule_change_set
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	changeSets, err := s.scanChangeSets(rows)
+	return changeSets, total, err
+}
+
+// GetUnrevertedChangeSetsSince returns the change sets made at or after since that are still in
+// effect, newest first.
+func (s *DomainRuleHistoryStore) GetUnrevertedChangeSetsSince(since time.Time) ([]*domain.DomainRuleChangeSet, error) {
+	rows, err := s.db.Query(`
+		SELECT `+domainRuleChangeSetColumns+`
+		FROM domain_rule_change_set
+		WHERE created_at >= datetime(?, 'unixepoch') AND reverted_at IS NULL
+		ORDER BY id DESC`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return s.scanChangeSets(rows)
+}
+
+// MarkReverted flags a change set as undone. revertedById is nil when reverting it took no changes.
+func (s *DomainRuleHistoryStore) MarkReverted(id int64, revertedById *int64) error {
+	_, err := s.db.Exec(`
+		UPDATE domain_rule_change_set
+		SET reverted_at = CURRENT_TIMESTAMP, reverted_by_id = ?
+		WHERE id = ?`, revertedById, id)
+	return err
+}
+
+func (s *DomainRuleHistoryStore) ClearReverted(id int64) error {
+	_, err := s.db.Exec(`
+		UPDATE domain_rule_change_set
+		SET reverted_at = NULL, reverted_by_id = NULL
+		WHERE id = ?`, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *DomainRuleHistoryStore) scanChangeSet(scanner rowScanner) (*domain.DomainRuleChangeSet, error) {
+	var row domainRuleChangeSetRow
+	if err := scanner.Scan(&row.Id, &row.UserId, &row.Action, &row.RevertsId, &row.Changes, &row.CreatedAt, &row.RevertedAt, &row.RevertedById); err != nil {
+		return nil, err
+	}
+	return rowToDomainRuleChangeSet(row, s.logger), nil
+}
+
+func (s *DomainRuleHistoryStore) scanChangeSets(rows *sql.Rows) ([]*domain.DomainRuleChangeSet, error) {
+	changeSets := []*domain.DomainRuleChangeSet{}
+	for rows.Next() {
+		changeSet, err := s.scanChangeSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		changeSets = append(changeSets, changeSet)
+	}
+	return changeSets, rows.Err()
+}
+
+func rowToDomainRuleChangeSet(row domainRuleChangeSetRow, logger zerolog.Logger) *domain.DomainRuleChangeSet {
+	changeSet := &domain.DomainRuleChangeSet{
+		Id:        row.Id,
+		Action:    row.Action,
+		Changes:   []domain.DomainRuleChange{},
+		CreatedAt: row.CreatedAt,
+	}
+	if row.UserId.Valid {
+		userId := row.UserId.Int64
+		changeSet.UserId = &userId
+	}
+	if row.RevertsId.Valid {
+		revertsId := row.RevertsId.Int64
+		changeSet.RevertsId = &revertsId
+	}
+	if row.RevertedAt.Valid {
+		revertedAt := row.RevertedAt.Time
+		changeSet.RevertedAt = &revertedAt
+	}
+	if row.RevertedById.Valid {
+		revertedById := row.RevertedById.Int64
+		changeSet.RevertedById = &revertedById
+	}
+	if err := json.Unmarshal([]byte(row.Changes), &changeSet.Changes); err != nil {
+		logger.Warn().Err(err).Int64("id", row.Id).Msg("error decoding domain rule changes")
+	}
+	return changeSet
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+type NodeHealthHistoryStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewNodeHealthHistoryStore(db *sql.DB, logger zerolog.Logger) *NodeHealthHistoryStore {
+	return &NodeHealthHistoryStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddNodeHealthSamples stores the samples and folds them into the hourly rollups in one transaction.
+func (s *NodeHealthHistoryStore) AddNodeHealthSamples(samples []AddNodeHealthSampleParams) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sampleStmt, err := tx.Prepare(`
+		INSERT INTO node_health_samples
+		(pihole_id, time, status, latency_ms, interval_seconds)
+		VALUES
+		(?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer sampleStmt.Close()
+
+	rollupStmt, err := tx.Prepare(`
+		INSERT INTO node_health_rollups
+		(pihole_id, bucket_start, status, latency_bucket, count, seconds)
+		VALUES
+		(?, ?, ?, ?, 1, ?)
+		ON CONFLICT (pihole_id, bucket_start, status, latency_bucket) DO UPDATE SET
+			count = count + 1,
+			seconds = seconds + excluded.seconds`)
+	if err != nil {
+		return err
+	}
+	defer rollupStmt.Close()
+
+	for _, sample := range samples {
+		t := sample.Time.UTC()
+		if _, err := sampleStmt.Exec(sample.PiholeId, t.Unix(), sample.Status, sample.LatencyMS, sample.IntervalSeconds); err != nil {
+			return err
+		}
+		if _, err := rollupStmt.Exec(sample.PiholeId, t.Truncate(time.Hour).Unix(), sample.Status, sample.LatencyBucket, sample.IntervalSeconds); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetNodeHealthSamples returns a node's samples at or after since, oldest first.
+func (s *NodeHealthHistoryStore) GetNodeHealthSamples(piholeId int64, since time.Time) ([]*domain.NodeHealthSample, error) {
+	rows, err := s.db.Query(`
+		SELECT pihole_id, time, status, latency_ms, interval_seconds
+		FROM node_health_samples
+		WHERE pihole_id = ? AND time >= ?
+		ORDER BY time ASC`, piholeId, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []*domain.NodeHealthSample{}
+	for rows.Next() {
+		var sample domain.NodeHealthSample
+		var t int64
+		if err := rows.Scan(&sample.PiholeId, &t, &sample.Status, &sample.LatencyMS, &sample.IntervalSeconds); err != nil {
+			return nil, err
+		}
+		sample.Time = time.Unix(t, 0).UTC()
+		samples = append(samples, &sample)
+	}
+	return samples, rows.Err()
+}
+
+// GetNodeHealthRollups returns a node's hourly rollups for hours starting at or after since, oldest first.
+func (s *NodeHealthHistoryStore) GetNodeHealthRollups(piholeId int64, since time.Time) ([]*domain.NodeHealthRollup, error) {
+	rows, err := s.db.Query(`
+		SELECT pihole_id, bucket_start, status, latency_bucket, count, seconds
+		FROM node_health_rollups
+		WHERE pihole_id = ? AND bucket_start >= ?
+		ORDER BY bucket_start ASC, status ASC, latency_bucket ASC`, piholeId, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := []*domain.NodeHealthRollup{}
+	for rows.Next() {
+		var rollup domain.NodeHealthRollup
+		var bucketStart int64
+		if err := rows.Scan(&rollup.PiholeId, &bucketStart, &rollup.Status, &rollup.LatencyBucket, &rollup.Count, &rollup.Seconds); err != nil {
+			return nil, err
+		}
+		rollup.BucketStart = time.Unix(bucketStart, 0).UTC()
+		rollups = append(rollups, &rollup)
+	}
+	return rollups, rows.Err()
+}
+
+func (s *NodeHealthHistoryStore) RemoveNodeHealthSamplesBefore(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM node_health_samples WHERE time < ?`, cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *NodeHealthHistoryStore) RemoveNodeHealthRollupsBefore(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM node_health_rollups WHERE bucket_start < ?`, cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
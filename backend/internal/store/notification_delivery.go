@@ -0,0 +1,129 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const notificationDeliveryColumns = `id, channel, event, pihole_id, pihole_name, subject, attempt, success, error, created_at`
+
+type NotificationDeliveryStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewNotificationDeliveryStore(db *sql.DB, logger zerolog.Logger) *NotificationDeliveryStore {
+	return &NotificationDeliveryStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *NotificationDeliveryStore) AddNotificationDelivery(params AddNotificationDeliveryParams) error {
+	var piholeId sql.NullInt64
+	if params.PiholeId != nil {
+		piholeId = sql.NullInt64{Int64: *params.PiholeId, Valid: true}
+	}
+	var piholeName sql.NullString
+	if params.PiholeName != nil {
+		piholeName = sql.NullString{String: *params.PiholeName, Valid: true}
+	}
+	var errString sql.NullString
+	if params.Error != nil {
+		errString = sql.NullString{String: *params.Error, Valid: true}
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO notification_deliveries
+		(channel, event, pihole_id, pihole_name, subject, attempt, success, error, created_at)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		params.Channel, params.Event, piholeId, piholeName, params.Subject, params.Attempt, params.Success, errString)
+	return err
+}
+
+func (s *NotificationDeliveryStore) GetNotificationDeliveries(params GetNotificationDeliveriesParams) ([]*domain.NotificationDelivery, int64, error) {
+	var whereParts []string
+	var args []any
+	if params.Channel != nil {
+		whereParts = append(whereParts, "channel = ?")
+		args = append(args, *params.Channel)
+	}
+	if params.Event != nil {
+		whereParts = append(whereParts, "event = ?")
+		args = append(args, *params.Event)
+	}
+	if params.Success != nil {
+		whereParts = append(whereParts, "success = ?")
+		args = append(args, *params.Success)
+	}
+
+	whereClause := ""
+	if len(whereParts) > 0 {
+		whereClause = " WHERE " + strings.Join(whereParts, " AND ")
+	}
+
+	var total int64
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM notification_deliveries"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + notificationDeliveryColumns + `
+		FROM notification_deliveries` + whereClause + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`
+	rows, err := s.db.Query(query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.NotificationDelivery{}
+	for rows.Next() {
+		var row notificationDeliveryRow
+		if err := rows.Scan(&row.Id, &row.Channel, &row.Event, &row.PiholeId, &row.PiholeName, &row.Subject, &row.Attempt, &row.Success, &row.Error, &row.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, rowToNotificationDelivery(row))
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+func rowToNotificationDelivery(row notificationDeliveryRow) *domain.NotificationDelivery {
+	delivery := &domain.NotificationDelivery{
+		Id:        row.Id,
+		Channel:   row.Channel,
+		Event:     row.Event,
+		Subject:   row.Subject,
+		Attempt:   row.Attempt,
+		Success:   row.Success,
+		CreatedAt: row.CreatedAt,
+	}
+	if row.PiholeId.Valid {
+		piholeId := row.PiholeId.Int64
+		delivery.PiholeId = &piholeId
+	}
+	if row.PiholeName.Valid {
+		piholeName := row.PiholeName.String
+		delivery.PiholeName = &piholeName
+	}
+	if row.Error.Valid {
+		errString := row.Error.String
+		delivery.Error = &errString
+	}
+	return delivery
+}
+
+func (s *NotificationDeliveryStore) RemoveNotificationDeliveriesBefore(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM notification_deliveries WHERE created_at < datetime(?, 'unixepoch')`, cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const pendingDomainRuleOpColumns = `id, pihole_id, action, type, kind, domain, comment, groups, group_names, enabled, attempts, last_error, created_at, last_attempt_at`
+
+type PendingDomainRuleOpStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewPendingDomainRuleOpStore(db *sql.DB, logger zerolog.Logger) *PendingDomainRuleOpStore {
+	return &PendingDomainRuleOpStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddPendingOp queues an operation for a node. Only the latest operation for a rule matters, so it
+// replaces any operation already queued for the same rule on the same node.
+func (s *PendingDomainRuleOpStore) AddPendingOp(params AddPendingDomainRuleOpParams) (*domain.PendingDomainRuleOp, error) {
+	var groups sql.NullString
+	if params.Groups != nil {
+		b, err := json.Marshal(params.Groups)
+		if err != nil {
+			return nil, err
+		}
+		groups = sql.NullString{String: string(b), Valid: true}
+	}
+	var groupNames sql.NullString
+	if params.GroupNames != nil {
+		b, err := json.Marshal(params.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		groupNames = sql.NullString{String: string(b), Valid: true}
+	}
+
+	var comment, lastError sql.NullString
+	if params.Comment != nil {
+		comment = sql.NullString{String: *params.Comment, Valid: true}
+	}
+	if params.LastError != "" {
+		lastError = sql.NullString{String: params.LastError, Valid: true}
+	}
+
+	var enabled sql.NullBool
+	if params.Enabled != nil {
+		enabled = sql.NullBool{Bool: *params.Enabled, Valid: true}
+	}
+
+	result, err := s.db.Exec(`
+		INSERT OR REPLACE INTO pending_domain_rule_ops
+		(pihole_id, action, type, kind, domain, comment, groups, group_names, enabled, last_error, created_at)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		params.PiholeId, params.Action, params.Type, params.Kind, params.Domain, comment, groups, groupNames, enabled, lastError)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRow(`SELECT `+pendingDomainRuleOpColumns+` FROM pending_domain_rule_ops WHERE id = ?`, id)
+	return s.scanPendingOp(row)
+}
+
+// GetPendingOps returns the queued operations in the order they were queued. A nil piholeId returns
+// the operations of every node.
+func (s *PendingDomainRuleOpStore) GetPendingOps(piholeId *int64) ([]*domain.PendingDomainRuleOp, error) {
+	query := `SELECT ` + pendingDomainRuleOpColumns + ` FROM pending_domain_rule_ops`
+	var args []any
+	if piholeId != nil {
+		query += ` WHERE pihole_id = ?`
+		args = append(args, *piholeId)
+	}
+	query += ` ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops := []*domain.PendingDomainRuleOp{}
+	for rows.Next() {
+		op, err := s.scanPendingOp(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+func (s *PendingDomainRuleOpStore) RecordAttempt(id int64, lastError string) error {
+	_, err := s.db.Exec(`
+		UPDATE pending_domain_rule_ops
+		SET attempts = attempts + 1, last_error = ?, last_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = ?`, lastError, id)
+	return err
+}
+
+func (s *PendingDomainRuleOpStore) RemovePendingOp(id int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM pending_domain_rule_ops WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *PendingDomainRuleOpStore) scanPendingOp(scanner rowScanner) (*domain.PendingDomainRuleOp, error) {
+	var row pendingDomainRuleOpRow
+	err := scanner.Scan(&row.Id, &row.PiholeId, &row.Action, &row.Type, &row.Kind, &row.Domain, &row.Comment, &row.Groups,
+		&row.GroupNames, &row.Enabled, &row.Attempts, &row.LastError, &row.CreatedAt, &row.LastAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+	return rowToDomainPendingDomainRuleOp(row, s.logger), nil
+}
+
+func rowToDomainPendingDomainRuleOp(row pendingDomainRuleOpRow, logger zerolog.Logger) *domain.PendingDomainRuleOp {
+	op := &domain.PendingDomainRuleOp{
+		Id:        row.Id,
+		PiholeId:  row.PiholeId,
+		Action:    row.Action,
+		Type:      row.Type,
+		Kind:      row.Kind,
+		Domain:    row.Domain,
+		Attempts:  row.Attempts,
+		LastError: row.LastError.String,
+		CreatedAt: row.CreatedAt,
+	}
+	if row.Comment.Valid {
+		comment := row.Comment.String
+		op.Comment = &comment
+	}
+	if row.Groups.Valid {
+		if err := json.Unmarshal([]byte(row.Groups.String), &op.Groups); err != nil {
+			logger.Warn().Err(err).Int64("id", row.Id).Msg("error decoding pending op groups")
+		}
+	}
+	if row.GroupNames.Valid {
+		if err := json.Unmarshal([]byte(row.GroupNames.String), &op.GroupNames); err != nil {
+			logger.Warn().Err(err).Int64("id", row.Id).Msg("error decoding pending op group names")
+		}
+	}
+	if row.Enabled.Valid {
+		enabled := row.Enabled.Bool
+		op.Enabled = &enabled
+	}
+	if row.LastAttemptAt.Valid {
+		lastAttemptAt := row.LastAttemptAt.Time
+		op.LastAttemptAt = &lastAttemptAt
+	}
+	return op
+}
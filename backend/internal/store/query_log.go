@@ -0,0 +1,269 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const queryLogColumns = `id, pihole_id, query_id, time, type, status, dnssec, domain, upstream, reply_type, reply_time, client_ip, client_name, list_id, ede_code, ede_text, cname`
+
+type QueryLogStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewQueryLogStore(db *sql.DB, logger zerolog.Logger) *QueryLogStore {
+	return &QueryLogStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddQueryLogEntries stores the entries in one transaction, skipping entries that are already
+// stored. It returns the entries that were new.
+func (s *QueryLogStore) AddQueryLogEntries(entries []domain.QueryLogEntry) ([]domain.QueryLogEntry, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO query_log
+		(pihole_id, query_id, time, type, status, dnssec, domain, upstream, reply_type, reply_time, client_ip, client_name, list_id, ede_code, ede_text, cname)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var added []domain.QueryLogEntry
+	for _, e := range entries {
+		result, err := stmt.Exec(e.PiholeId, e.QueryId, e.Time, e.Type, e.Status, e.DNSSEC, e.Domain, e.Upstream, e.ReplyType, e.ReplyTime, e.ClientIP, e.ClientName, e.ListId, e.EDECode, e.EDEText, e.CNAME)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected > 0 {
+			added = append(added, e)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// GetLatestQueryLogTime returns the time of the newest stored entry of a node, or nil if none are stored.
+func (s *QueryLogStore) GetLatestQueryLogTime(piholeId int64) (*float64, error) {
+	var latest sql.NullFloat64
+	if err := s.db.QueryRow(`SELECT MAX(time) FROM query_log WHERE pihole_id = ?`, piholeId).Scan(&latest); err != nil {
+		return nil, err
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Float64, nil
+}
+
+func (s *QueryLogStore) GetMaxQueryLogId() (int64, error) {
+	var maxId sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(id) FROM query_log`).Scan(&maxId); err != nil {
+		return 0, err
+	}
+	return maxId.Int64, nil
+}
+
+// GetQueryLogEntries returns matching entries, newest first.
+func (s *QueryLogStore) GetQueryLogEntries(params GetQueryLogEntriesParams) ([]*domain.QueryLogEntry, error) {
+	whereClause, args := queryLogWhere(params)
+	rows, err := s.db.Query(`
+		SELECT `+queryLogColumns+`
+		FROM query_log`+whereClause+`
+		ORDER BY time DESC, id DESC
+		LIMIT ? OFFSET ?`, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.QueryLogEntry{}
+	for rows.Next() {
+		var row queryLogRow
+		if err := rows.Scan(&row.Id, &row.PiholeId, &row.QueryId, &row.Time, &row.Type, &row.Status, &row.DNSSEC, &row.Domain, &row.Upstream, &row.ReplyType, &row.ReplyTime, &row.ClientIP, &row.ClientName, &row.ListId, &row.EDECode, &row.EDEText, &row.CNAME); err != nil {
+			return nil, err
+		}
+		entries = append(entries, rowToDomainQueryLogEntry(row))
+	}
+	return entries, rows.Err()
+}
+
+// CountQueryLogEntriesByNode counts matching entries per node. Limit and Offset are ignored.
+func (s *QueryLogStore) CountQueryLogEntriesByNode(params GetQueryLogEntriesParams) (map[int64]int64, error) {
+	whereClause, args := queryLogWhere(params)
+	rows, err := s.db.Query(`SELECT pihole_id, COUNT(*) FROM query_log`+whereClause+` GROUP BY pihole_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var piholeId, count int64
+		if err := rows.Scan(&piholeId, &count); err != nil {
+			return nil, err
+		}
+		counts[piholeId] = count
+	}
+	return counts, rows.Err()
+}
+
+// RemoveQueryLogEntriesBefore drops entries older than the cutoff, returning how many were removed.
+func (s *QueryLogStore) RemoveQueryLogEntriesBefore(cutoff float64) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM query_log WHERE time < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryLogWhere(params GetQueryLogEntriesParams) (string, []any) {
+	var whereParts []string
+	var args []any
+	if params.PiholeId != nil {
+		whereParts = append(whereParts, "pihole_id = ?")
+		args = append(args, *params.PiholeId)
+	}
+	if params.MaxId != nil {
+		whereParts = append(whereParts, "id <= ?")
+		args = append(args, *params.MaxId)
+	}
+
+	f := params.Filters
+	if f.From != nil {
+		whereParts = append(whereParts, "time >= ?")
+		args = append(args, *f.From)
+	}
+	if f.Until != nil {
+		whereParts = append(whereParts, "time <= ?")
+		args = append(args, *f.Until)
+	}
+	for _, filter := range []struct {
+		column   string
+		value    *string
+		wildcard bool
+	}{
+		{"domain", f.Domain, true},
+		{"client_ip", f.ClientIP, false},
+		{"client_name", f.ClientName, true},
+		{"upstream", f.Upstream, true},
+		{"type", f.Type, false},
+		{"status", f.Status, false},
+		{"reply_type", f.Reply, false},
+		{"dnssec", f.DNSSEC, false},
+	} {
+		if filter.value == nil {
+			continue
+		}
+		if filter.wildcard && strings.Contains(*filter.value, "*") {
+			whereParts = append(whereParts, filter.column+` LIKE ? ESCAPE '\'`)
+			args = append(args, wildcardToLike(*filter.value))
+			continue
+		}
+		whereParts = append(whereParts, filter.column+" = ?")
+		args = append(args, *filter.value)
+	}
+
+	if len(whereParts) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(whereParts, " AND "), args
+}
+
+func wildcardToLike(pattern string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return replacer.Replace(pattern)
+}
+
+func rowToDomainQueryLogEntry(row queryLogRow) *domain.QueryLogEntry {
+	entry := &domain.QueryLogEntry{
+		Id:        row.Id,
+		PiholeId:  row.PiholeId,
+		QueryId:   row.QueryId,
+		Time:      row.Time,
+		Type:      row.Type.String,
+		Status:    row.Status.String,
+		DNSSEC:    row.DNSSEC.String,
+		Domain:    row.Domain,
+		ReplyType: row.ReplyType.String,
+		ReplyTime: row.ReplyTime.Float64,
+		ClientIP:  row.ClientIP.String,
+		EDECode:   row.EDECode.Int64,
+	}
+	if row.Upstream.Valid {
+		entry.Upstream = &row.Upstream.String
+	}
+	if row.ClientName.Valid {
+		entry.ClientName = &row.ClientName.String
+	}
+	if row.ListId.Valid {
+		entry.ListId = &row.ListId.Int64
+	}
+	if row.EDEText.Valid {
+		entry.EDEText = &row.EDEText.String
+	}
+	if row.CNAME.Valid {
+		entry.CNAME = &row.CNAME.String
+	}
+	return entry
+}
+
+// GetQueryLogCollectPosition returns where the collector stopped reading a node's backlog, or nil if
+// it is caught up.
+func (s *QueryLogStore) GetQueryLogCollectPosition(piholeId int64) (*domain.QueryLogCollectPosition, error) {
+	position := domain.QueryLogCollectPosition{PiholeId: piholeId}
+	err := s.db.QueryRow(`
+		SELECT from_time, until_time, cursor, start
+		FROM query_log_collect_positions
+		WHERE pihole_id = ?`, piholeId).Scan(&position.From, &position.Until, &position.Cursor, &position.Start)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (s *QueryLogStore) SetQueryLogCollectPosition(position domain.QueryLogCollectPosition) error {
+	_, err := s.db.Exec(`
+		INSERT INTO query_log_collect_positions
+		(pihole_id, from_time, until_time, cursor, start)
+		VALUES
+		(?, ?, ?, ?, ?)
+		ON CONFLICT (pihole_id) DO UPDATE SET
+			from_time = excluded.from_time,
+			until_time = excluded.until_time,
+			cursor = excluded.cursor,
+			start = excluded.start`,
+		position.PiholeId, position.From, position.Until, position.Cursor, position.Start)
+	return err
+}
+
+func (s *QueryLogStore) RemoveQueryLogCollectPosition(piholeId int64) error {
+	_, err := s.db.Exec(`DELETE FROM query_log_collect_positions WHERE pihole_id = ?`, piholeId)
+	return err
+}
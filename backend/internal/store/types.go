@@ -1,6 +1,7 @@
 package store
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/auto-dns/pihole-cluster-admin/internal/domain"
@@ -79,3 +80,193 @@ type UpdateUserParams struct {
 	Username *string
 	Password *string
 }
+
+// Audit store
+
+type auditRow struct {
+	Id           int64
+	UserId       sql.NullInt64
+	Action       string
+	Route        string
+	Target       string
+	Details      sql.NullString
+	NodeOutcomes sql.NullString
+	Success      bool
+	CreatedAt    time.Time
+}
+
+type AddAuditEntryParams struct {
+	UserId       *int64
+	Action       string
+	Route        string
+	Target       string
+	Details      []byte // JSON
+	NodeOutcomes []domain.AuditNodeOutcome
+	Success      bool
+}
+
+type GetAuditEntriesParams struct {
+	UserId  *int64
+	Action  *string
+	Success *bool
+	From    *time.Time
+	Until   *time.Time
+	Limit   int
+	Offset  int
+}
+
+type domainRuleChangeSetRow struct {
+	Id           int64
+	UserId       sql.NullInt64
+	Action       string
+	RevertsId    sql.NullInt64
+	Changes      string
+	CreatedAt    time.Time
+	RevertedAt   sql.NullTime
+	RevertedById sql.NullInt64
+}
+
+type AddDomainRuleChangeSetParams struct {
+	UserId    *int64
+	Action    string
+	RevertsId *int64
+	Changes   []domain.DomainRuleChange
+}
+
+type pendingDomainRuleOpRow struct {
+	Id            int64
+	PiholeId      int64
+	Action        string
+	Type          string
+	Kind          string
+	Domain        string
+	Comment       sql.NullString
+	Groups        sql.NullString
+	GroupNames    sql.NullString
+	Enabled       sql.NullBool
+	Attempts      int
+	LastError     sql.NullString
+	CreatedAt     time.Time
+	LastAttemptAt sql.NullTime
+}
+
+type AddPendingDomainRuleOpParams struct {
+	PiholeId   int64
+	Action     string
+	Type       string
+	Kind       string
+	Domain     string
+	Comment    *string
+	Groups     []int
+	GroupNames []string
+	Enabled    *bool
+	LastError  string
+}
+
+type domainRuleExpirationRow struct {
+	Id            int64
+	Type          string
+	Kind          string
+	Domain        string
+	PiholeNodeIds sql.NullString
+	UserId        sql.NullInt64
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
+type AddDomainRuleExpirationParams struct {
+	Type          string
+	Kind          string
+	Domain        string
+	PiholeNodeIds []int64
+	UserId        *int64
+	ExpiresAt     time.Time
+}
+
+type queryLogRow struct {
+	Id         int64
+	PiholeId   int64
+	QueryId    int64
+	Time       float64
+	Type       sql.NullString
+	Status     sql.NullString
+	DNSSEC     sql.NullString
+	Domain     string
+	Upstream   sql.NullString
+	ReplyType  sql.NullString
+	ReplyTime  sql.NullFloat64
+	ClientIP   sql.NullString
+	ClientName sql.NullString
+	ListId     sql.NullInt64
+	EDECode    sql.NullInt64
+	EDEText    sql.NullString
+	CNAME      sql.NullString
+}
+
+// QueryLogFilters mirror the Pi-hole query log filters. Domain, ClientName and Upstream accept '*'
+// wildcards; the other fields match exactly.
+type QueryLogFilters struct {
+	From       *float64
+	Until      *float64
+	Domain     *string
+	ClientIP   *string
+	ClientName *string
+	Upstream   *string
+	Type       *string
+	Status     *string
+	Reply      *string
+	DNSSEC     *string
+}
+
+type GetQueryLogEntriesParams struct {
+	PiholeId *int64 // nil reads every node
+	MaxId    *int64 // only rows with id <= MaxId, pinning a search to a snapshot
+	Filters  QueryLogFilters
+	Limit    int
+	Offset   int
+}
+
+// Notification delivery store
+
+type notificationDeliveryRow struct {
+	Id         int64
+	Channel    string
+	Event      string
+	PiholeId   sql.NullInt64
+	PiholeName sql.NullString
+	Subject    string
+	Attempt    int
+	Success    bool
+	Error      sql.NullString
+	CreatedAt  time.Time
+}
+
+type AddNotificationDeliveryParams struct {
+	Channel    string
+	Event      string
+	PiholeId   *int64
+	PiholeName *string
+	Subject    string
+	Attempt    int
+	Success    bool
+	Error      *string
+}
+
+type GetNotificationDeliveriesParams struct {
+	Channel *string
+	Event   *string
+	Success *bool
+	Limit   int
+	Offset  int
+}
+
+// Node health history store
+
+type AddNodeHealthSampleParams struct {
+	PiholeId        int64
+	Time            time.Time
+	Status          string
+	LatencyMS       int
+	LatencyBucket   int // index into the caller's latency bucket bounds, used for the hourly rollup
+	IntervalSeconds int
+}
@@ -2,6 +2,7 @@ package httpx
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -39,6 +40,17 @@ func WriteJSONErrorFromErr(w http.ResponseWriter, err error) {
 	WriteJSONError(w, message, status)
 }
 
+// WriteValidationError writes a validation error with its message, so the client sees what was wrong
+// with the request. Any other error is written as WriteJSONErrorFromErr does.
+func WriteValidationError(w http.ResponseWriter, err error) {
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) && errors.Is(httpErr.Kind, ErrValidation) {
+		WriteJSONError(w, httpErr.Message, http.StatusBadRequest)
+		return
+	}
+	WriteJSONErrorFromErr(w, err)
+}
+
 func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst any, maxBytes int64) error {
 	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 	defer r.Body.Close()